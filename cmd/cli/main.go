@@ -1,24 +1,26 @@
 package main
 
 import (
-    "log"
-    
-    "github.com/drybin/TrackMyCoin/internal/app/cli"
-    "github.com/drybin/TrackMyCoin/internal/app/cli/config"
-    "github.com/joho/godotenv"
+	"log"
+	// База часовых поясов IANA встроена в бинарник: SHEET_TIMEZONE работает и без tzdata в системе
+	_ "time/tzdata"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/joho/godotenv"
 )
 
 func main() {
-    if err := godotenv.Load(); err != nil {
-        log.Println("failed to load env", err)
-    }
-    
-    configObj, err := config.InitConfig()
-    if err != nil {
-        log.Fatal("failed to init cli config", err)
-    }
-    
-    if err := cli.Run(configObj); err != nil {
-        log.Fatal("failed to run cli app: ", err)
-    }
+	if err := godotenv.Load(); err != nil {
+		log.Println("failed to load env", err)
+	}
+
+	configObj, err := config.InitConfig()
+	if err != nil {
+		log.Fatal("failed to init cli config", err)
+	}
+
+	if err := cli.Run(configObj); err != nil {
+		log.Fatal("failed to run cli app: ", err)
+	}
 }
@@ -0,0 +1,161 @@
+package recordstore
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+)
+
+// CSVFile таблица в CSV файле: один лист, названный по имени файла
+// Дополнительные листы записываются рядом: signals.csv → signals.Stats.csv.
+type CSVFile struct {
+	path string
+}
+
+func NewCSVFile(path string) *CSVFile {
+	return &CSVFile{path: path}
+}
+
+func (f *CSVFile) Name() string {
+	return "file " + f.path
+}
+
+func (f *CSVFile) ID() string {
+	return f.path
+}
+
+func (f *CSVFile) Read(_ context.Context) (*Table, error) {
+	values, err := readCSV(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{Range: a1.Range{Sheet: tableName(f.path), StartCol: 1, StartRow: 1}, Values: values}, nil
+}
+
+func (f *CSVFile) Update(_ context.Context, data []ValueRange) error {
+	values, err := readCSV(f.path)
+	if err != nil {
+		return err
+	}
+
+	g := grid(values)
+	if err := g.update(tableName(f.path), data); err != nil {
+		return err
+	}
+
+	return writeCSV(f.path, g)
+}
+
+func (f *CSVFile) Append(_ context.Context, rows [][]interface{}) (string, error) {
+	values, err := readCSV(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	g := grid(values)
+	appended := g.append(tableName(f.path), rows)
+
+	return appended, writeCSV(f.path, g)
+}
+
+func (f *CSVFile) WriteSheet(_ context.Context, title string, values [][]interface{}) (bool, error) {
+	ext := filepath.Ext(f.path)
+	path := f.path[:len(f.path)-len(ext)] + "." + title + ext
+
+	_, err := os.Stat(path)
+	created := os.IsNotExist(err)
+
+	return created, writeCSV(path, values)
+}
+
+// FormatNumbers ничего не делает: CSV хранит только значения, без форматирования
+func (f *CSVFile) FormatNumbers(_ context.Context, _ []NumberFormat) error {
+	return nil
+}
+
+func readCSV(path string) ([][]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	values := make([][]interface{}, len(records))
+	for i, record := range records {
+		values[i] = make([]interface{}, len(record))
+		for j, cell := range record {
+			// В CSV нет типов ячеек: числа читаются числами, как из Google Sheets и XLSX
+			values[i][j] = unformattedValue(cell)
+		}
+	}
+
+	return values, nil
+}
+
+// writeCSV записывает файл целиком через временный файл, чтобы прерванная запись не испортила таблицу
+func writeCSV(path string, values [][]interface{}) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := csv.NewWriter(tmp)
+	for _, row := range values {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = csvCell(value)
+		}
+		if err := w.Write(record); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("unable to write %s: %w", path, err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+
+	// CreateTemp создает файл с правами 0600: после замены у файла остаются права исходного
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func csvCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
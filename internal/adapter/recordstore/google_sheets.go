@@ -0,0 +1,137 @@
+package recordstore
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+	"google.golang.org/api/sheets/v4"
+)
+
+// GoogleSheets таблица в листе Google Sheets
+type GoogleSheets struct {
+	client        webapi.IGoogleSheets
+	spreadsheetID string
+	// sheetRange диапазон с сигналами (пусто - первый лист целиком)
+	sheetRange string
+}
+
+func NewGoogleSheets(client webapi.IGoogleSheets, spreadsheetID string, sheetRange string) *GoogleSheets {
+	return &GoogleSheets{
+		client:        client,
+		spreadsheetID: spreadsheetID,
+		sheetRange:    sheetRange,
+	}
+}
+
+func (g *GoogleSheets) Name() string {
+	if g.sheetRange != "" {
+		return fmt.Sprintf("Google Sheets %s (%s)", g.spreadsheetID, g.sheetRange)
+	}
+
+	return "Google Sheets " + g.spreadsheetID
+}
+
+func (g *GoogleSheets) ID() string {
+	return g.spreadsheetID
+}
+
+func (g *GoogleSheets) Read(ctx context.Context) (*Table, error) {
+	log.Printf("Spreadsheet ID: %s\n", g.spreadsheetID)
+
+	// Получаем информацию о таблице, включая названия листов
+	log.Println("Getting spreadsheet info...")
+	spreadsheet, err := g.client.GetSpreadsheetInfo(ctx, g.spreadsheetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spreadsheet info: %w", err)
+	}
+
+	log.Printf("Spreadsheet title: %s\n", spreadsheet.Properties.Title)
+	log.Printf("Available sheets: %d\n", len(spreadsheet.Sheets))
+
+	for i, sheet := range spreadsheet.Sheets {
+		log.Printf("  Sheet %d: %s (ID: %d)\n", i+1, sheet.Properties.Title, sheet.Properties.SheetId)
+	}
+
+	// Определяем какой лист читать
+	readRange, err := g.readRange(spreadsheet)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Reading range: %s\n", readRange)
+
+	data, err := g.client.ReadSpreadsheet(ctx, g.spreadsheetID, readRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spreadsheet: %w", err)
+	}
+
+	// Фактический диапазон, который вернул API (с именем листа и первой строкой)
+	dataRange, err := a1.Parse(data.Range)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse returned range %q: %w", data.Range, err)
+	}
+	if dataRange.Sheet == "" {
+		dataRange.Sheet = spreadsheet.Sheets[0].Properties.Title
+	}
+
+	return &Table{Range: dataRange, Values: data.Values}, nil
+}
+
+func (g *GoogleSheets) Update(ctx context.Context, data []ValueRange) error {
+	valueRanges := make([]*sheets.ValueRange, 0, len(data))
+	for _, valueRange := range data {
+		valueRanges = append(valueRanges, &sheets.ValueRange{Range: valueRange.Range, Values: valueRange.Values})
+	}
+
+	return g.client.BatchUpdateSpreadsheet(ctx, g.spreadsheetID, valueRanges)
+}
+
+func (g *GoogleSheets) Append(ctx context.Context, rows [][]interface{}) (string, error) {
+	spreadsheet, err := g.client.GetSpreadsheetInfo(ctx, g.spreadsheetID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get spreadsheet info: %w", err)
+	}
+
+	readRange, err := g.readRange(spreadsheet)
+	if err != nil {
+		return "", err
+	}
+
+	return g.client.AppendSpreadsheet(ctx, g.spreadsheetID, readRange, rows)
+}
+
+func (g *GoogleSheets) WriteSheet(ctx context.Context, title string, values [][]interface{}) (bool, error) {
+	created, err := g.client.EnsureSheet(ctx, g.spreadsheetID, title)
+	if err != nil {
+		return false, err
+	}
+
+	return created, g.client.OverwriteSheet(ctx, g.spreadsheetID, title, values)
+}
+
+func (g *GoogleSheets) FormatNumbers(ctx context.Context, formats []NumberFormat) error {
+	numberFormats := make([]webapi.NumberFormat, 0, len(formats))
+	for _, format := range formats {
+		numberFormats = append(numberFormats, webapi.NumberFormat{Range: format.Range, Pattern: format.Pattern})
+	}
+
+	return g.client.FormatNumbers(ctx, g.spreadsheetID, numberFormats)
+}
+
+// readRange возвращает диапазон листа с сигналами: заданный диапазон или весь первый лист
+func (g *GoogleSheets) readRange(spreadsheet *sheets.Spreadsheet) (string, error) {
+	if g.sheetRange != "" {
+		// Если указан диапазон в конфиге, используем его
+		return g.sheetRange, nil
+	}
+
+	if len(spreadsheet.Sheets) == 0 {
+		return "", fmt.Errorf("no sheets found in spreadsheet")
+	}
+
+	// Иначе читаем первый лист полностью: просто имя листа без диапазона читает весь лист
+	return spreadsheet.Sheets[0].Properties.Title, nil
+}
@@ -0,0 +1,154 @@
+package recordstore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+)
+
+// IRecordStore таблица с сигналами: лист Google Sheets или локальный файл
+// Строки адресуются как в листе: номер строки с 1, диапазоны в A1 нотации с именем листа.
+type IRecordStore interface {
+	// Name описание таблицы для логов
+	Name() string
+	// ID постоянный идентификатор таблицы: ID таблицы Google Sheets или путь к файлу
+	ID() string
+	// Read читает таблицу: первая строка - заголовок
+	Read(ctx context.Context) (*Table, error)
+	// Update записывает значения в диапазоны; ячейки вне диапазонов не затрагиваются
+	Update(ctx context.Context, data []ValueRange) error
+	// Append добавляет строки после последней строки таблицы и возвращает диапазон, в который они записаны
+	Append(ctx context.Context, rows [][]interface{}) (string, error)
+	// WriteSheet заменяет содержимое дополнительного листа title (статистика, восстановленные записи),
+	// создавая его при необходимости. Возвращает true, если лист был создан.
+	WriteSheet(ctx context.Context, title string, values [][]interface{}) (bool, error)
+	// FormatNumbers задает числовой формат ячеек диапазонов; таблицы без форматирования (CSV) его пропускают
+	FormatNumbers(ctx context.Context, formats []NumberFormat) error
+}
+
+// Table прочитанная таблица
+type Table struct {
+	// Range фактический диапазон данных: имя листа, первая строка и колонка (заголовок)
+	Range a1.Range
+	// Values строки таблицы, первая - заголовок
+	Values [][]interface{}
+}
+
+// ValueRange значения для записи в диапазон
+type ValueRange struct {
+	Range  string
+	Values [][]interface{}
+}
+
+// NumberFormat числовой формат ячеек диапазона
+type NumberFormat struct {
+	// Range диапазон в A1 нотации с именем листа
+	Range string
+	// Pattern шаблон формата, например "#,##0.00" (одинаковый в Google Sheets и XLSX)
+	Pattern string
+}
+
+// FilePrefix префикс спецификации файловой таблицы: file:signals.xlsx
+const FilePrefix = "file:"
+
+// OpenFile открывает файловую таблицу по спецификации file:<путь>
+// Формат определяется по расширению: .csv или .xlsx.
+func OpenFile(spec string) (IRecordStore, error) {
+	path, ok := strings.CutPrefix(spec, FilePrefix)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid record store %q: expected %s<path>.csv or %s<path>.xlsx", spec, FilePrefix, FilePrefix)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return NewCSVFile(path), nil
+	case ".xlsx":
+		return NewXLSXFile(path), nil
+	default:
+		return nil, fmt.Errorf("invalid record store %q: unsupported file type (use .csv or .xlsx)", spec)
+	}
+}
+
+// tableName имя листа файловой таблицы: имя файла без расширения
+func tableName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// unformattedValue значение ячейки файла без форматирования, как его возвращает Google Sheets:
+// числа (в том числе даты и время - серийные номера) - float64, остальное - текст
+func unformattedValue(cell string) interface{} {
+	if number, err := strconv.ParseFloat(cell, 64); err == nil && !math.IsNaN(number) && !math.IsInf(number, 0) {
+		return number
+	}
+
+	return cell
+}
+
+// grid строки таблицы в памяти для файловых таблиц
+type grid [][]interface{}
+
+// set записывает значение в ячейку (row, col с 1), дополняя таблицу пустыми ячейками
+func (g *grid) set(row int, col int, value interface{}) {
+	for len(*g) < row {
+		*g = append(*g, []interface{}{})
+	}
+	for len((*g)[row-1]) < col {
+		(*g)[row-1] = append((*g)[row-1], "")
+	}
+	(*g)[row-1][col-1] = value
+}
+
+// update записывает диапазоны листа sheet
+func (g *grid) update(sheet string, data []ValueRange) error {
+	for _, valueRange := range data {
+		r, err := a1.Parse(valueRange.Range)
+		if err != nil {
+			return err
+		}
+		if r.Sheet != "" && r.Sheet != sheet {
+			return fmt.Errorf("unknown sheet %q in range %s", r.Sheet, valueRange.Range)
+		}
+
+		for i, row := range valueRange.Values {
+			for j, value := range row {
+				g.set(max(r.StartRow, 1)+i, max(r.StartCol, 1)+j, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// append добавляет строки после последней непустой строки и возвращает их диапазон
+func (g *grid) append(sheet string, rows [][]interface{}) string {
+	last := len(*g)
+	for last > 0 && isEmptyRow((*g)[last-1]) {
+		last--
+	}
+	*g = (*g)[:last]
+
+	width := 1
+	for i, row := range rows {
+		for j, value := range row {
+			g.set(last+i+1, j+1, value)
+		}
+		width = max(width, len(row))
+	}
+
+	return a1.Rows(sheet, 1, width, last+1, last+len(rows))
+}
+
+func isEmptyRow(row []interface{}) bool {
+	for _, value := range row {
+		if value != nil && fmt.Sprintf("%v", value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,187 @@
+package recordstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var testRows = [][]interface{}{
+	{"Дата", "Время", "Источник", "Монета", "Направление", "Цена"},
+	{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "45000"},
+}
+
+func TestFileStores(t *testing.T) {
+	ctx := context.Background()
+
+	stores := map[string]func(t *testing.T) (IRecordStore, string){
+		"CSV": func(t *testing.T) (IRecordStore, string) {
+			path := filepath.Join(t.TempDir(), "signals.csv")
+			require.NoError(t, writeCSV(path, testRows))
+			return NewCSVFile(path), "signals"
+		},
+		"XLSX": func(t *testing.T) (IRecordStore, string) {
+			path := filepath.Join(t.TempDir(), "signals.xlsx")
+			file := excelize.NewFile()
+			for i, row := range testRows {
+				require.NoError(t, setRow(file, "Sheet1", 1, i+1, row))
+			}
+			require.NoError(t, file.SaveAs(path))
+			require.NoError(t, file.Close())
+			return NewXLSXFile(path), "Sheet1"
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store, sheet := open(t)
+
+			table, err := store.Read(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, sheet, table.Range.Sheet)
+			assert.Equal(t, 1, table.Range.StartRow)
+			assert.Equal(t, cellStrings(testRows), cellStrings(table.Values))
+			// Числа читаются числами, как из Google Sheets: иначе цены считались бы записанными текстом
+			assert.Equal(t, 45000.0, table.Values[1][5])
+
+			t.Run("Update записывает только указанные ячейки", func(t *testing.T) {
+				require.NoError(t, store.Update(ctx, []ValueRange{
+					{Range: sheet + "!G2:H2", Values: [][]interface{}{{45010.5, "N/A"}}},
+				}))
+
+				table, err := store.Read(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "45000", "45010.5", "N/A"}, cellStrings(table.Values)[1])
+			})
+
+			t.Run("Append добавляет строки после последней", func(t *testing.T) {
+				appended, err := store.Append(ctx, [][]interface{}{{"30.12.2025", "09:00:00", "ChannelB", "ETH", "short", 3000.0}})
+				require.NoError(t, err)
+				assert.Equal(t, sheet+"!A3:F3", appended)
+
+				table, err := store.Read(ctx)
+				require.NoError(t, err)
+				require.Len(t, table.Values, 3)
+				assert.Equal(t, []string{"30.12.2025", "09:00:00", "ChannelB", "ETH", "short", "3000"}, cellStrings(table.Values)[2])
+			})
+
+			t.Run("WriteSheet не затрагивает лист с сигналами", func(t *testing.T) {
+				created, err := store.WriteSheet(ctx, "Stats", [][]interface{}{{"Signals", 2}})
+				require.NoError(t, err)
+				assert.True(t, created)
+
+				created, err = store.WriteSheet(ctx, "Stats", [][]interface{}{{"Signals", 3}})
+				require.NoError(t, err)
+				assert.False(t, created)
+
+				table, err := store.Read(ctx)
+				require.NoError(t, err)
+				assert.Len(t, table.Values, 3)
+			})
+		})
+	}
+}
+
+func TestXLSXFile_Numbers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.xlsx")
+	file := excelize.NewFile()
+	require.NoError(t, setRow(file, "Sheet1", 1, 1, []interface{}{"Дата", "Время", "Цена"}))
+	require.NoError(t, file.SetCellValue("Sheet1", "A2", time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, setRow(file, "Sheet1", 2, 2, []interface{}{"10:30:00", 0.0046}))
+	require.NoError(t, file.SaveAs(path))
+	require.NoError(t, file.Close())
+
+	table, err := NewXLSXFile(path).Read(context.Background())
+	require.NoError(t, err)
+	// Даты и числа читаются без форматирования, как из Google Sheets: серийный номер и число
+	assert.Equal(t, []interface{}{46020.0, "10:30:00", 0.0046}, table.Values[1])
+}
+
+func TestXLSXFile_FormatNumbers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.xlsx")
+	file := excelize.NewFile()
+	require.NoError(t, setRow(file, "Sheet1", 1, 1, []interface{}{"Монета", "Цена"}))
+	require.NoError(t, setRow(file, "Sheet1", 1, 2, []interface{}{"XVG", 0.0046}))
+	require.NoError(t, file.SaveAs(path))
+	require.NoError(t, file.Close())
+
+	store := NewXLSXFile(path)
+	require.NoError(t, store.FormatNumbers(context.Background(), []NumberFormat{{Range: "Sheet1!B2:B2", Pattern: "#,##0.000000"}}))
+
+	file, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	// Формат меняет отображение, значение остается числом
+	formatted, err := file.GetCellValue("Sheet1", "B2")
+	require.NoError(t, err)
+	assert.Equal(t, "0.004600", formatted)
+
+	table, err := store.Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0.0046, table.Values[1][1])
+
+	assert.Error(t, store.FormatNumbers(context.Background(), []NumberFormat{{Range: "Stats!A1", Pattern: "0.00"}}))
+}
+
+func TestCSVFile_WriteSheet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.csv")
+	require.NoError(t, writeCSV(path, testRows))
+
+	_, err := NewCSVFile(path).WriteSheet(context.Background(), "Stats", [][]interface{}{{"Signals", 2}, {}, {"Mean %", 1.25}})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(path), "signals.Stats.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "Signals,2\n\nMean %,1.25\n", string(data))
+}
+
+func TestCSVFile_KeepsFileMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.csv")
+	require.NoError(t, writeCSV(path, testRows))
+	require.NoError(t, os.Chmod(path, 0o664))
+
+	require.NoError(t, NewCSVFile(path).Update(context.Background(), []ValueRange{
+		{Range: "signals!G2", Values: [][]interface{}{{45010.5}}},
+	}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o664), info.Mode().Perm())
+}
+
+func TestOpenFile(t *testing.T) {
+	store, err := OpenFile("file:data/signals.CSV")
+	require.NoError(t, err)
+	assert.IsType(t, &CSVFile{}, store)
+
+	store, err = OpenFile("file:signals.xlsx")
+	require.NoError(t, err)
+	assert.IsType(t, &XLSXFile{}, store)
+
+	for _, spec := range []string{"signals.csv", "file:", "file:signals.ods"} {
+		_, err := OpenFile(spec)
+		assert.Error(t, err, spec)
+	}
+
+	_, err = NewXLSXFile(filepath.Join(t.TempDir(), "missing.xlsx")).Read(context.Background())
+	assert.Error(t, err)
+}
+
+// cellStrings значения ячеек текстом: CSV хранит только текст, XLSX возвращает числа числами
+func cellStrings(values [][]interface{}) [][]string {
+	rows := make([][]string, len(values))
+	for i, row := range values {
+		for _, value := range row {
+			rows[i] = append(rows[i], csvCell(value))
+		}
+	}
+
+	return rows
+}
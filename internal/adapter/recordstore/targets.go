@@ -0,0 +1,113 @@
+package recordstore
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+)
+
+// Target таблица с сигналами, которую process обрабатывает отдельно от остальных
+type Target struct {
+	// Name название для логов и сводки: таблица и лист
+	Name string
+	// Store таблица цели (nil, если цель не удалось открыть)
+	Store IRecordStore
+	// Err почему цель не удалось открыть: таблица недоступна, нет листов под шаблоны
+	Err error
+}
+
+// ITargets набор таблиц с сигналами, которые process обходит за один запуск
+type ITargets interface {
+	// Resolve находит таблицы целей. Вызывается в каждом запуске, поэтому новые листы
+	// (например, лист нового месяца) подхватываются без перезапуска daemon.
+	// Ошибка одной таблицы возвращается в ее Target и не мешает остальным.
+	Resolve(ctx context.Context) []Target
+}
+
+// SpreadsheetTabs таблица Google Sheets и шаблоны названий ее листов с сигналами
+type SpreadsheetTabs struct {
+	SpreadsheetID string
+	// Tabs шаблоны названий листов (*, ?, [...] как в path.Match), пусто - первый лист
+	Tabs []string
+}
+
+// GoogleTabs листы таблиц Google Sheets, названия которых подходят под шаблоны
+type GoogleTabs struct {
+	client       webapi.IGoogleSheets
+	spreadsheets []SpreadsheetTabs
+	excluded     func(title string) bool
+}
+
+// NewGoogleTabs создает набор листов; excluded отсеивает листы, которые не бывают листами с сигналами,
+// даже если подходят под шаблон (листы статистики под "*"). nil - подходят все листы.
+func NewGoogleTabs(client webapi.IGoogleSheets, spreadsheets []SpreadsheetTabs, excluded func(title string) bool) *GoogleTabs {
+	return &GoogleTabs{
+		client:       client,
+		spreadsheets: spreadsheets,
+		excluded:     excluded,
+	}
+}
+
+func (g *GoogleTabs) Resolve(ctx context.Context) []Target {
+	var targets []Target
+
+	for _, spreadsheet := range g.spreadsheets {
+		info, err := g.client.GetSpreadsheetInfo(ctx, spreadsheet.SpreadsheetID)
+		if err != nil {
+			targets = append(targets, Target{Name: spreadsheet.SpreadsheetID, Err: err})
+			continue
+		}
+
+		name := spreadsheet.SpreadsheetID
+		if info.Properties != nil && info.Properties.Title != "" {
+			name = info.Properties.Title
+		}
+
+		matched := 0
+		for i, sheet := range info.Sheets {
+			if sheet.Properties == nil {
+				continue
+			}
+
+			title := sheet.Properties.Title
+			if !matchTab(spreadsheet.Tabs, i, title) || (g.excluded != nil && g.excluded(title)) {
+				continue
+			}
+
+			// Название всегда в кавычках: лист "Q1" без них API прочитал бы как ячейку Q1 первого листа
+			targets = append(targets, Target{
+				Name:  name + " / " + title,
+				Store: NewGoogleSheets(g.client, spreadsheet.SpreadsheetID, "'"+strings.ReplaceAll(title, "'", "''")+"'"),
+			})
+			matched++
+		}
+
+		if matched == 0 {
+			targets = append(targets, Target{
+				Name: name,
+				Err:  fmt.Errorf("no sheets match %q", strings.Join(spreadsheet.Tabs, "|")),
+			})
+		}
+	}
+
+	return targets
+}
+
+// matchTab проверяет, подходит ли лист под шаблоны; без шаблонов подходит только первый лист
+// Шаблоны проверены при разборе конфига, поэтому ошибка path.Match здесь означает "не подходит".
+func matchTab(patterns []string, index int, title string) bool {
+	if len(patterns) == 0 {
+		return index == 0
+	}
+
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, title); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package recordstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/sheets/v4"
+)
+
+// spreadsheetsInfo клиент Google Sheets, который знает только названия листов таблиц
+type spreadsheetsInfo struct {
+	webapi.IGoogleSheets
+	titles map[string][]string // ID таблицы → названия листов (первое - название таблицы)
+}
+
+func (s *spreadsheetsInfo) GetSpreadsheetInfo(_ context.Context, spreadsheetID string) (*sheets.Spreadsheet, error) {
+	titles, ok := s.titles[spreadsheetID]
+	if !ok {
+		return nil, fmt.Errorf("spreadsheet %s not found", spreadsheetID)
+	}
+
+	spreadsheet := &sheets.Spreadsheet{Properties: &sheets.SpreadsheetProperties{Title: titles[0]}}
+	for _, title := range titles[1:] {
+		spreadsheet.Sheets = append(spreadsheet.Sheets, &sheets.Sheet{Properties: &sheets.SheetProperties{Title: title}})
+	}
+
+	return spreadsheet, nil
+}
+
+func TestGoogleTabs_Resolve(t *testing.T) {
+	client := &spreadsheetsInfo{titles: map[string][]string{
+		"team-a": {"Команда A", "Сигналы 2025-01", "Stats", "Сигналы 2025-02", "Q1"},
+		"team-b": {"Команда B", "Лист1", "Архив"},
+	}}
+
+	targets := NewGoogleTabs(client, []SpreadsheetTabs{
+		{SpreadsheetID: "team-a", Tabs: []string{"Сигналы 2025-*", "Q?"}},
+		{SpreadsheetID: "team-b"},
+		{SpreadsheetID: "team-b", Tabs: []string{"2026-*"}},
+		{SpreadsheetID: "missing"},
+	}, nil).Resolve(context.Background())
+
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	assert.Equal(t, []string{
+		"Команда A / Сигналы 2025-01",
+		"Команда A / Сигналы 2025-02",
+		"Команда A / Q1",
+		"Команда B / Лист1",
+		"Команда B",
+		"missing",
+	}, names)
+
+	// Каждый лист - отдельная таблица, название листа в кавычках
+	require.NotNil(t, targets[2].Store)
+	assert.Equal(t, "Google Sheets team-a ('Q1')", targets[2].Store.Name())
+	assert.Equal(t, "Google Sheets team-b ('Лист1')", targets[3].Store.Name())
+
+	// Ошибка одной таблицы не мешает остальным
+	assert.Nil(t, targets[4].Store)
+	assert.ErrorContains(t, targets[4].Err, `no sheets match "2026-*"`)
+	assert.ErrorContains(t, targets[5].Err, "not found")
+}
+
+func TestGoogleTabs_ExcludesStatsSheets(t *testing.T) {
+	client := &spreadsheetsInfo{titles: map[string][]string{
+		"team-a": {"Команда A", "Январь", "Stats", "Февраль", "Stats (Январь)"},
+	}}
+	isStats := func(title string) bool { return title == "Stats" || title == "Stats (Январь)" }
+
+	targets := NewGoogleTabs(client, []SpreadsheetTabs{
+		{SpreadsheetID: "team-a", Tabs: []string{"*"}},
+	}, isStats).Resolve(context.Background())
+
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	assert.Equal(t, []string{"Команда A / Январь", "Команда A / Февраль"}, names)
+}
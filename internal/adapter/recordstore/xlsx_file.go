@@ -0,0 +1,223 @@
+package recordstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXFile таблица в первом листе файла XLSX
+// Дополнительные листы (статистика, восстановленные записи) добавляются в тот же файл.
+type XLSXFile struct {
+	path string
+}
+
+func NewXLSXFile(path string) *XLSXFile {
+	return &XLSXFile{path: path}
+}
+
+func (f *XLSXFile) Name() string {
+	return "file " + f.path
+}
+
+func (f *XLSXFile) ID() string {
+	return f.path
+}
+
+func (f *XLSXFile) Read(_ context.Context) (*Table, error) {
+	var table *Table
+	err := f.withFile(false, func(file *excelize.File, sheet string) error {
+		rows, err := file.GetRows(sheet, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return err
+		}
+
+		values := make([][]interface{}, len(rows))
+		for i, row := range rows {
+			values[i] = make([]interface{}, len(row))
+			for j, cell := range row {
+				values[i][j] = unformattedValue(cell)
+			}
+		}
+
+		table = &Table{Range: a1.Range{Sheet: sheet, StartCol: 1, StartRow: 1}, Values: values}
+		return nil
+	})
+
+	return table, err
+}
+
+func (f *XLSXFile) Update(_ context.Context, data []ValueRange) error {
+	return f.withFile(true, func(file *excelize.File, sheet string) error {
+		for _, valueRange := range data {
+			r, err := a1.Parse(valueRange.Range)
+			if err != nil {
+				return err
+			}
+			if r.Sheet != "" && r.Sheet != sheet {
+				return fmt.Errorf("unknown sheet %q in range %s", r.Sheet, valueRange.Range)
+			}
+
+			for i, row := range valueRange.Values {
+				if err := setRow(file, sheet, max(r.StartCol, 1), max(r.StartRow, 1)+i, row); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (f *XLSXFile) Append(_ context.Context, rows [][]interface{}) (string, error) {
+	var appended string
+	err := f.withFile(true, func(file *excelize.File, sheet string) error {
+		existing, err := file.GetRows(sheet)
+		if err != nil {
+			return err
+		}
+
+		last := len(existing)
+		for last > 0 && isEmptyStringRow(existing[last-1]) {
+			last--
+		}
+
+		width := 1
+		for i, row := range rows {
+			if err := setRow(file, sheet, 1, last+i+1, row); err != nil {
+				return err
+			}
+			width = max(width, len(row))
+		}
+
+		appended = a1.Rows(sheet, 1, width, last+1, last+len(rows))
+		return nil
+	})
+
+	return appended, err
+}
+
+func (f *XLSXFile) WriteSheet(_ context.Context, title string, values [][]interface{}) (bool, error) {
+	created := false
+	err := f.withFile(true, func(file *excelize.File, sheet string) error {
+		if title == sheet {
+			return fmt.Errorf("sheet %q is the data sheet", title)
+		}
+
+		// Лист пересоздается: так с него удаляются и значения, оставшиеся от прошлой записи
+		index, err := file.GetSheetIndex(title)
+		if err != nil {
+			return err
+		}
+		created = index == -1
+		if !created {
+			if err := file.DeleteSheet(title); err != nil {
+				return err
+			}
+		}
+		if _, err := file.NewSheet(title); err != nil {
+			return err
+		}
+
+		for i, row := range values {
+			if err := setRow(file, title, 1, i+1, row); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return created, err
+}
+
+func (f *XLSXFile) FormatNumbers(_ context.Context, formats []NumberFormat) error {
+	if len(formats) == 0 {
+		return nil
+	}
+
+	return f.withFile(true, func(file *excelize.File, sheet string) error {
+		// Один стиль на шаблон: иначе каждый диапазон добавлял бы в файл новый одинаковый стиль
+		styles := make(map[string]int)
+		for _, format := range formats {
+			r, err := a1.Parse(format.Range)
+			if err != nil {
+				return err
+			}
+			if r.Sheet != "" && r.Sheet != sheet {
+				return fmt.Errorf("unknown sheet %q in range %s", r.Sheet, format.Range)
+			}
+
+			style, ok := styles[format.Pattern]
+			if !ok {
+				pattern := format.Pattern
+				if style, err = file.NewStyle(&excelize.Style{CustomNumFmt: &pattern}); err != nil {
+					return err
+				}
+				styles[format.Pattern] = style
+			}
+
+			topLeft, err := excelize.CoordinatesToCellName(max(r.StartCol, 1), max(r.StartRow, 1))
+			if err != nil {
+				return err
+			}
+			bottomRight, err := excelize.CoordinatesToCellName(max(r.EndCol, r.StartCol, 1), max(r.EndRow, r.StartRow, 1))
+			if err != nil {
+				return err
+			}
+			if err := file.SetCellStyle(sheet, topLeft, bottomRight, style); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// withFile открывает файл, передает fn первый лист (лист с сигналами) и при save сохраняет изменения
+func (f *XLSXFile) withFile(save bool, fn func(file *excelize.File, sheet string) error) error {
+	file, err := excelize.OpenFile(f.path)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %w", f.path, err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return fmt.Errorf("no sheets found in %s", f.path)
+	}
+
+	if err := fn(file, sheets[0]); err != nil {
+		return fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	if save {
+		if err := file.Save(); err != nil {
+			return fmt.Errorf("unable to save %s: %w", f.path, err)
+		}
+	}
+
+	return nil
+}
+
+// setRow записывает значения строки начиная с колонки col
+func setRow(file *excelize.File, sheet string, col int, row int, values []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		return err
+	}
+
+	return file.SetSheetRow(sheet, cell, &values)
+}
+
+func isEmptyStringRow(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	_ "modernc.org/sqlite" // Драйвер SQLite на чистом Go, без cgo
+)
+
+// sqliteSchema таблицы хранилища
+// record_prices - текущие значения ячеек с ценами (как в листе), price_samples - история всех полученных цен.
+// Время хранится строкой фиксированной длины в UTC (storeTimeFormat), поэтому его можно сравнивать как текст.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS records (
+	key           TEXT PRIMARY KEY,
+	sheet         TEXT NOT NULL DEFAULT '',
+	date          TEXT NOT NULL,
+	time          TEXT NOT NULL,
+	source        TEXT NOT NULL,
+	coin          TEXT NOT NULL,
+	direction     TEXT NOT NULL,
+	source_price  REAL NOT NULL DEFAULT 0,
+	signal_at     TEXT,
+	sheet_row     INTEGER NOT NULL DEFAULT 0,
+	first_seen_at TEXT NOT NULL,
+	updated_at    TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS record_prices (
+	record_key TEXT NOT NULL,
+	field      TEXT NOT NULL,
+	price      REAL,
+	cell       TEXT,
+	PRIMARY KEY (record_key, field)
+);
+
+CREATE TABLE IF NOT EXISTS price_samples (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	record_key   TEXT NOT NULL,
+	field        TEXT NOT NULL,
+	coin         TEXT NOT NULL,
+	price        REAL NOT NULL,
+	provider     TEXT NOT NULL,
+	requested_at TEXT NOT NULL,
+	sampled_at   TEXT NOT NULL,
+	fetched_at   TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS price_samples_record ON price_samples (record_key, field);
+`
+
+// SQLite хранилище в файле SQLite
+type SQLite struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLite открывает (или создает) файл хранилища и таблицы в нем
+func NewSQLite(ctx context.Context, path string) (*SQLite, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("unable to create store directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("unable to open store %s: %w", path, err)
+	}
+	// Одно соединение: записи из daemon и команд не конкурируют за блокировку файла
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("unable to create store tables in %s: %w", path, err)
+	}
+	if err := migrateRecordsSheet(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("unable to migrate store %s: %w", path, err)
+	}
+
+	return &SQLite{db: db, now: time.Now}, nil
+}
+
+// migrateRecordsSheet добавляет колонку sheet в хранилище, созданное до ее появления
+// Записи без листа сохраняют прежние ключи и остаются записями без листа.
+func migrateRecordsSheet(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM pragma_table_info('records')`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == "sheet" {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `ALTER TABLE records ADD COLUMN sheet TEXT NOT NULL DEFAULT ''`)
+	return err
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// SaveRecords сохраняет записи и текущие значения их цен
+// Запись с тем же ключом обновляется; время первого появления записи сохраняется.
+func (s *SQLite) SaveRecords(ctx context.Context, records []*model.CoinPriceRecord) error {
+	now := formatTime(s.now())
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, record := range records {
+			key := record.Key()
+
+			var signalAt interface{}
+			if t, err := record.TryParseDateTime(); err == nil {
+				signalAt = formatTime(t)
+			}
+
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO records (key, sheet, date, time, source, coin, direction, source_price, signal_at, sheet_row, first_seen_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (key) DO UPDATE SET
+					source_price = excluded.source_price,
+					signal_at = excluded.signal_at,
+					sheet_row = excluded.sheet_row,
+					updated_at = excluded.updated_at`,
+				key, record.SheetID, record.Date, record.Time, record.Source, record.Coin, record.Direction,
+				record.SourcePrice, signalAt, record.RowNumber, now, now,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to save record %s: %w", key, err)
+			}
+
+			// Цены записи заменяются целиком: очищенная в листе ячейка очищается и в хранилище
+			if _, err := tx.ExecContext(ctx, `DELETE FROM record_prices WHERE record_key = ?`, key); err != nil {
+				return fmt.Errorf("unable to save prices of record %s: %w", key, err)
+			}
+
+			prices := map[string]model.PriceValue{model.BybitPriceField: record.BybitPrice}
+			for field, value := range record.Prices {
+				prices[field] = value
+			}
+			for field, value := range prices {
+				if value.State == model.PriceEmpty {
+					continue
+				}
+
+				var price, cell interface{}
+				if value.State == model.PriceFilled {
+					price = value.Price
+				} else {
+					cell = fmt.Sprintf("%v", value.Cell())
+				}
+
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO record_prices (record_key, field, price, cell) VALUES (?, ?, ?, ?)`,
+					key, field, price, cell,
+				)
+				if err != nil {
+					return fmt.Errorf("unable to save prices of record %s: %w", key, err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// SaveSamples добавляет полученные цены в историю
+func (s *SQLite) SaveSamples(ctx context.Context, samples []model.PriceSample) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, sample := range samples {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO price_samples (record_key, field, coin, price, provider, requested_at, sampled_at, fetched_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				sample.RecordKey, sample.Field, sample.Coin, sample.Price, sample.Provider,
+				formatTime(sample.RequestedAt), formatTime(sample.SampledAt), formatTime(sample.FetchedAt),
+			)
+			if err != nil {
+				return fmt.Errorf("unable to save price sample for %s %s: %w", sample.RecordKey, sample.Field, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Records возвращает все сохраненные записи: сначала по времени сигнала, записи с нераспознанным временем - в конце
+func (s *SQLite) Records(ctx context.Context) ([]*model.CoinPriceRecord, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT key, sheet, date, time, source, coin, direction, source_price, sheet_row
+		FROM records
+		ORDER BY signal_at IS NULL, signal_at, key`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*model.CoinPriceRecord
+	byKey := make(map[string]*model.CoinPriceRecord)
+	for rows.Next() {
+		var key string
+		record := &model.CoinPriceRecord{Prices: make(map[string]model.PriceValue)}
+		err := rows.Scan(&key, &record.SheetID, &record.Date, &record.Time, &record.Source, &record.Coin, &record.Direction,
+			&record.SourcePrice, &record.RowNumber)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read records: %w", err)
+		}
+
+		records = append(records, record)
+		byKey[key] = record
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read records: %w", err)
+	}
+
+	priceRows, err := s.db.QueryContext(ctx, `SELECT record_key, field, price, cell FROM record_prices`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read record prices: %w", err)
+	}
+	defer priceRows.Close()
+
+	for priceRows.Next() {
+		var key, field string
+		var price sql.NullFloat64
+		var cell sql.NullString
+		if err := priceRows.Scan(&key, &field, &price, &cell); err != nil {
+			return nil, fmt.Errorf("unable to read record prices: %w", err)
+		}
+
+		record, ok := byKey[key]
+		if !ok {
+			continue
+		}
+
+		value := model.ParsePriceValue(cell.String)
+		if price.Valid {
+			value = model.NewPriceValue(price.Float64)
+		}
+		if field == model.BybitPriceField {
+			record.BybitPrice = value
+		} else {
+			record.Prices[field] = value
+		}
+	}
+	if err := priceRows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read record prices: %w", err)
+	}
+
+	return records, nil
+}
+
+// Samples возвращает историю цен записи в порядке получения
+func (s *SQLite) Samples(ctx context.Context, recordKey string) ([]model.PriceSample, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT record_key, field, coin, price, provider, requested_at, sampled_at, fetched_at
+		FROM price_samples
+		WHERE record_key = ?
+		ORDER BY fetched_at, id`, recordKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read price samples: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []model.PriceSample
+	for rows.Next() {
+		var sample model.PriceSample
+		var requestedAt, sampledAt, fetchedAt string
+		err := rows.Scan(&sample.RecordKey, &sample.Field, &sample.Coin, &sample.Price, &sample.Provider,
+			&requestedAt, &sampledAt, &fetchedAt)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read price samples: %w", err)
+		}
+
+		for _, t := range []struct {
+			value  string
+			target *time.Time
+		}{{requestedAt, &sample.RequestedAt}, {sampledAt, &sample.SampledAt}, {fetchedAt, &sample.FetchedAt}} {
+			if *t.target, err = time.Parse(storeTimeFormat, t.value); err != nil {
+				return nil, fmt.Errorf("invalid time %q in price samples: %w", t.value, err)
+			}
+		}
+
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read price samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+// inTx выполняет fn в транзакции: при ошибке изменения откатываются
+func (s *SQLite) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin store transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit store transaction: %w", err)
+	}
+
+	return nil
+}
+
+// storeTimeFormat RFC3339 с наносекундами без отбрасывания нулей
+const storeTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(storeTimeFormat)
+}
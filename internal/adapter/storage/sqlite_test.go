@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "store.db")
+
+	store, err := NewSQLite(ctx, path)
+	require.NoError(t, err)
+
+	btc := &model.CoinPriceRecord{
+		Date: "29.12.2025", Time: "10:00:00", Source: "ChannelA", Coin: "BTC", Direction: "long",
+		SourcePrice: 45000, BybitPrice: model.NewPriceValue(45010), RowNumber: 2,
+		Prices: map[string]model.PriceValue{
+			"10m": model.NewPriceValue(45100),
+			"30m": {State: model.PriceNotDue},
+			"1h":  {State: model.PriceManual, Text: "skip"},
+		},
+	}
+	eth := &model.CoinPriceRecord{
+		Date: "28.12.2025", Time: "09:00:00", Source: "ChannelB", Coin: "ETH", Direction: "short",
+		Prices: map[string]model.PriceValue{"10m": {State: model.PriceNotAvailable}},
+	}
+	require.NoError(t, store.SaveRecords(ctx, []*model.CoinPriceRecord{btc, eth}))
+
+	t.Run("Записи читаются в порядке времени сигнала", func(t *testing.T) {
+		records, err := store.Records(ctx)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+
+		assert.Equal(t, "ETH", records[0].Coin)
+		assert.Equal(t, model.PriceValue{State: model.PriceNotAvailable}, records[0].Price("10m"))
+
+		got := records[1]
+		assert.Equal(t, btc.Key(), got.Key())
+		assert.Equal(t, 45000.0, got.SourcePrice)
+		assert.Equal(t, 2, got.RowNumber)
+		assert.Equal(t, model.NewPriceValue(45010), got.BybitPrice)
+		assert.Equal(t, btc.Prices, got.Prices)
+	})
+
+	t.Run("Повторное сохранение обновляет запись по ключу", func(t *testing.T) {
+		btc.RowNumber = 7
+		btc.SetPrice("30m", model.NewPriceValue(45200))
+		require.NoError(t, store.SaveRecords(ctx, []*model.CoinPriceRecord{btc}))
+
+		records, err := store.Records(ctx)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, 7, records[1].RowNumber)
+		assert.Equal(t, model.NewPriceValue(45200), records[1].Price("30m"))
+	})
+
+	t.Run("История цен с происхождением", func(t *testing.T) {
+		requested := time.Date(2025, 12, 29, 3, 10, 0, 0, time.UTC)
+		sample := model.PriceSample{
+			RecordKey: btc.Key(), Field: "10m", Coin: "BTC", Price: 45100, Provider: "bybit",
+			RequestedAt: requested, SampledAt: requested.Add(-20 * time.Second), FetchedAt: requested.Add(time.Hour),
+		}
+		second := sample
+		second.Price, second.Provider, second.FetchedAt = 45105, "coingecko", sample.FetchedAt.Add(time.Minute)
+		require.NoError(t, store.SaveSamples(ctx, []model.PriceSample{second, sample}))
+
+		samples, err := store.Samples(ctx, btc.Key())
+		require.NoError(t, err)
+		require.Len(t, samples, 2)
+		assert.Equal(t, "bybit", samples[0].Provider)
+		assert.True(t, sample.SampledAt.Equal(samples[0].SampledAt))
+		assert.True(t, sample.RequestedAt.Equal(samples[0].RequestedAt))
+		assert.Equal(t, "coingecko", samples[1].Provider)
+
+		samples, err = store.Samples(ctx, eth.Key())
+		require.NoError(t, err)
+		assert.Empty(t, samples)
+	})
+
+	// Данные сохраняются в файле
+	require.NoError(t, store.Close())
+	store, err = NewSQLite(ctx, path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	records, err := store.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+}
+
+func TestSQLite_Sheets(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.db")
+
+	// Хранилище, созданное до появления колонки листа
+	db, err := sql.Open("sqlite", "file:"+path)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `CREATE TABLE records (
+		key TEXT PRIMARY KEY, date TEXT NOT NULL, time TEXT NOT NULL, source TEXT NOT NULL, coin TEXT NOT NULL,
+		direction TEXT NOT NULL, source_price REAL NOT NULL DEFAULT 0, signal_at TEXT,
+		sheet_row INTEGER NOT NULL DEFAULT 0, first_seen_at TEXT NOT NULL, updated_at TEXT NOT NULL)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := NewSQLite(ctx, path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	// Одинаковые сигналы в разных листах - разные записи
+	signal := func(sheet string, price float64) *model.CoinPriceRecord {
+		return &model.CoinPriceRecord{
+			Date: "29.12.2025", Time: "10:00:00", Source: "ChannelA", Coin: "BTC", Direction: "long",
+			SheetID: sheet, BybitPrice: model.NewPriceValue(price),
+		}
+	}
+	require.NoError(t, store.SaveRecords(ctx, []*model.CoinPriceRecord{signal("team-a!2025-12", 1), signal("team-b!2025-12", 2)}))
+
+	records, err := store.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	prices := map[string]model.PriceValue{records[0].SheetID: records[0].BybitPrice, records[1].SheetID: records[1].BybitPrice}
+	assert.Equal(t, map[string]model.PriceValue{
+		"team-a!2025-12": model.NewPriceValue(1),
+		"team-b!2025-12": model.NewPriceValue(2),
+	}, prices)
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+// IStore локальное хранилище записей и всех полученных цен
+// Лист Google Sheets - представление данных хранилища: его можно восстановить из хранилища
+// после сортировки или удаления строк.
+type IStore interface {
+	// SaveRecords сохраняет записи (по ключу CoinPriceRecord.Key) вместе с текущими значениями цен
+	SaveRecords(ctx context.Context, records []*model.CoinPriceRecord) error
+	// SaveSamples добавляет полученные цены в историю
+	SaveSamples(ctx context.Context, samples []model.PriceSample) error
+	// Records возвращает все сохраненные записи в порядке времени сигнала
+	Records(ctx context.Context) ([]*model.CoinPriceRecord, error)
+	// Samples возвращает историю цен записи в порядке получения
+	Samples(ctx context.Context, recordKey string) ([]model.PriceSample, error)
+	Close() error
+}
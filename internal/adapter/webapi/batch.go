@@ -0,0 +1,44 @@
+package webapi
+
+import (
+	"sort"
+	"time"
+)
+
+// timeWindow окно истории, которое покрывается одним запросом к провайдеру
+type timeWindow struct {
+	From    time.Time
+	To      time.Time
+	Indexes []int // Индексы моментов из исходного списка, попавших в окно
+}
+
+// groupTimeWindows группирует моменты в окна [первый - tolerance, последний + tolerance]
+// длиной не больше maxSpan, чтобы получить цены на все моменты окна одним запросом
+func groupTimeWindows(ats []time.Time, tolerance time.Duration, maxSpan time.Duration) []timeWindow {
+	indexes := make([]int, len(ats))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return ats[indexes[i]].Before(ats[indexes[j]])
+	})
+
+	var windows []timeWindow
+	for _, index := range indexes {
+		from := ats[index].Add(-tolerance)
+		to := ats[index].Add(tolerance)
+
+		if len(windows) > 0 {
+			last := &windows[len(windows)-1]
+			if to.Sub(last.From) <= maxSpan {
+				last.To = to
+				last.Indexes = append(last.Indexes, index)
+				continue
+			}
+		}
+
+		windows = append(windows, timeWindow{From: from, To: to, Indexes: []int{index}})
+	}
+
+	return windows
+}
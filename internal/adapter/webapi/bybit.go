@@ -0,0 +1,570 @@
+package webapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/ratelimit"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	BybitProviderName = "bybit"
+
+	BybitCategorySpot   = "spot"
+	BybitCategoryLinear = "linear"
+
+	// bybitQuoteCoin - котируемая валюта, к которой приводятся все пары
+	bybitQuoteCoin = "USDT"
+	// bybitRetCodeNotSupported - символ не торгуется в указанной категории
+	bybitRetCodeNotSupported = 10001
+	// bybitRetCodeRateLimited - слишком частые запросы (Bybit отвечает им и в теле, и статусом 403)
+	bybitRetCodeRateLimited = 10006
+	// bybitKlineLimit - максимальное количество свечей в одном ответе /v5/market/kline
+	bybitKlineLimit = 1000
+	// bybitInstrumentsLimit - максимальное количество пар в одной странице /v5/market/instruments-info
+	bybitInstrumentsLimit = 1000
+	// bybitInstrumentsTTL - как долго список пар категории считается актуальным: после него
+	// список загружается заново, и daemon подхватывает новые листинги
+	bybitInstrumentsTTL = 6 * time.Hour
+	// bybitStatusTrading - статус пары, по которой идут торги
+	bybitStatusTrading = "Trading"
+	// bybitContractPerpetual - бессрочный контракт (срочные контракты не подходят для цены монеты)
+	bybitContractPerpetual = "LinearPerpetual"
+)
+
+// bybitMultipliers - множители контрактов вида 1000PEPEUSDT и SHIB1000USDT на деривативах Bybit
+var bybitMultipliers = []int{1000, 10000, 100000, 1000000, 10000000}
+
+// ErrBybitSymbolNotFound возвращается, если для монеты не нашлось ни одной пары на Bybit
+var ErrBybitSymbolNotFound = fmt.Errorf("bybit symbol: %w", ErrPriceNotFound)
+
+type IBybit interface {
+	Name() string
+	GetTicker(ctx context.Context, category string, symbol string) (*BybitTicker, error)
+	GetKlines(ctx context.Context, category string, symbol string, interval string, start time.Time, end time.Time) ([]BybitKline, error)
+	ResolveSymbol(ctx context.Context, coinSymbol string) (BybitSymbol, error)
+	GetCurrentPrice(ctx context.Context, coinSymbol string) (model.PricePoint, error)
+	GetHistoricalPrice(ctx context.Context, coinSymbol string, at time.Time) (model.PricePoint, error)
+	GetCurrentPrices(ctx context.Context, coinSymbols []string) ([]model.PricePoint, []error)
+	GetHistoricalPrices(ctx context.Context, coinSymbol string, ats []time.Time) ([]model.PricePoint, []error)
+}
+
+type Bybit struct {
+	client           *resty.Client
+	baseURL          string
+	historyTolerance time.Duration
+	limiter          *ratelimit.Limiter
+
+	now func() time.Time
+
+	mu          sync.Mutex
+	instruments map[string]bybitInstruments // Категория → загруженный список пар
+	loading     map[string]chan struct{}    // Категории, список пар которых сейчас загружается
+}
+
+// bybitInstruments пары категории по монете из таблицы
+type bybitInstruments struct {
+	symbols  map[string]BybitSymbol
+	loadedAt time.Time
+}
+
+// BybitSymbol торговая пара на Bybit, соответствующая монете из таблицы
+type BybitSymbol struct {
+	Category   string  // spot или linear
+	Symbol     string  // Например, XVGUSDT или 1000PEPEUSDT
+	Multiplier float64 // Сколько монет в одном контракте (1 для обычных пар)
+}
+
+// BybitTicker тикер из /v5/market/tickers
+type BybitTicker struct {
+	Symbol    string    `json:"symbol"`
+	LastPrice string    `json:"lastPrice"`
+	Time      time.Time `json:"-"` // Время ответа сервера
+}
+
+// BybitKline свеча из /v5/market/kline
+type BybitKline struct {
+	StartTime time.Time
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+}
+
+type bybitResponse[T any] struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  T      `json:"result"`
+	Time    int64  `json:"time"`
+}
+
+type bybitTickersResult struct {
+	Category string        `json:"category"`
+	List     []BybitTicker `json:"list"`
+}
+
+// bybitInstrument пара из /v5/market/instruments-info
+type bybitInstrument struct {
+	Symbol       string `json:"symbol"`
+	ContractType string `json:"contractType"` // Только у деривативов
+	Status       string `json:"status"`
+	QuoteCoin    string `json:"quoteCoin"`
+}
+
+type bybitInstrumentsResult struct {
+	Category       string            `json:"category"`
+	List           []bybitInstrument `json:"list"`
+	NextPageCursor string            `json:"nextPageCursor"`
+}
+
+type bybitKlineResult struct {
+	Category string     `json:"category"`
+	Symbol   string     `json:"symbol"`
+	List     [][]string `json:"list"`
+}
+
+// NewBybit создает клиент Bybit; limiter ограничивает частоту всех запросов клиента (nil - без ограничения)
+func NewBybit(client *resty.Client, historyTolerance time.Duration, limiter *ratelimit.Limiter) *Bybit {
+	return &Bybit{
+		client:           client,
+		baseURL:          "https://api.bybit.com",
+		historyTolerance: historyTolerance,
+		limiter:          limiter,
+		now:              time.Now,
+		instruments:      make(map[string]bybitInstruments),
+		loading:          make(map[string]chan struct{}),
+	}
+}
+
+// Name возвращает имя провайдера для маршрутизации цен
+func (b *Bybit) Name() string {
+	return BybitProviderName
+}
+
+// GetTicker получает тикер пары в указанной категории
+func (b *Bybit) GetTicker(ctx context.Context, category string, symbol string) (*BybitTicker, error) {
+	var result bybitResponse[bybitTickersResult]
+
+	err := b.get(ctx, "/v5/market/tickers", map[string]string{
+		"category": category,
+		"symbol":   symbol,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.RetCode == bybitRetCodeNotSupported {
+		return nil, fmt.Errorf("%w: %s/%s", ErrBybitSymbolNotFound, category, symbol)
+	}
+	if result.RetCode != 0 {
+		return nil, bybitRetCodeError(result.RetCode, result.RetMsg)
+	}
+
+	for _, ticker := range result.Result.List {
+		if ticker.Symbol == symbol {
+			ticker.Time = time.UnixMilli(result.Time)
+			return &ticker, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s/%s", ErrBybitSymbolNotFound, category, symbol)
+}
+
+// GetTickers получает тикеры всех пар категории одним запросом (символ → тикер)
+func (b *Bybit) GetTickers(ctx context.Context, category string) (map[string]BybitTicker, error) {
+	var result bybitResponse[bybitTickersResult]
+
+	err := b.get(ctx, "/v5/market/tickers", map[string]string{
+		"category": category,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.RetCode != 0 {
+		return nil, bybitRetCodeError(result.RetCode, result.RetMsg)
+	}
+
+	tickers := make(map[string]BybitTicker, len(result.Result.List))
+	for _, ticker := range result.Result.List {
+		ticker.Time = time.UnixMilli(result.Time)
+		tickers[ticker.Symbol] = ticker
+	}
+
+	return tickers, nil
+}
+
+// GetKlines получает свечи пары за период [start, end]
+// interval в формате Bybit: 1, 3, 5, 15, 30, 60, ..., D, W, M
+func (b *Bybit) GetKlines(
+	ctx context.Context,
+	category string,
+	symbol string,
+	interval string,
+	start time.Time,
+	end time.Time,
+) ([]BybitKline, error) {
+	var result bybitResponse[bybitKlineResult]
+
+	err := b.get(ctx, "/v5/market/kline", map[string]string{
+		"category": category,
+		"symbol":   symbol,
+		"interval": interval,
+		"start":    strconv.FormatInt(start.UnixMilli(), 10),
+		"end":      strconv.FormatInt(end.UnixMilli(), 10),
+		"limit":    strconv.Itoa(bybitKlineLimit),
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.RetCode == bybitRetCodeNotSupported {
+		return nil, fmt.Errorf("%w: %s/%s", ErrBybitSymbolNotFound, category, symbol)
+	}
+	if result.RetCode != 0 {
+		return nil, bybitRetCodeError(result.RetCode, result.RetMsg)
+	}
+
+	klines := make([]BybitKline, 0, len(result.Result.List))
+	for _, item := range result.Result.List {
+		kline, err := parseBybitKline(item)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, kline)
+	}
+
+	return klines, nil
+}
+
+// ResolveSymbol подбирает пару Bybit для символа монеты из таблицы
+// Пара ищется в списках пар категорий (спот, затем бессрочные контракты), которые загружаются
+// один раз на bybitInstrumentsTTL: монета, которой нет на Bybit, не стоит ни одного запроса сверх них.
+// У контрактов учитывается множитель в начале или конце названия: 1000PEPEUSDT, SHIB1000USDT.
+func (b *Bybit) ResolveSymbol(ctx context.Context, coinSymbol string) (BybitSymbol, error) {
+	coin := strings.ToUpper(strings.TrimSpace(coinSymbol))
+
+	for _, category := range []string{BybitCategorySpot, BybitCategoryLinear} {
+		symbols, err := b.categoryInstruments(ctx, category)
+		if err != nil {
+			return BybitSymbol{}, err
+		}
+		if symbol, ok := symbols[coin]; ok {
+			return symbol, nil
+		}
+	}
+
+	return BybitSymbol{}, fmt.Errorf("%w: %s", ErrBybitSymbolNotFound, coinSymbol)
+}
+
+// categoryInstruments возвращает пары категории по монете, загружая список при первом обращении
+// и после bybitInstrumentsTTL. Загрузка идет без блокировки кэша: одновременные запросы той же
+// категории ждут ее завершения, а не загружают список повторно. Если обновить список не удалось,
+// используется прежний.
+func (b *Bybit) categoryInstruments(ctx context.Context, category string) (map[string]BybitSymbol, error) {
+	for {
+		b.mu.Lock()
+		cached, ok := b.instruments[category]
+		if ok && b.now().Sub(cached.loadedAt) < bybitInstrumentsTTL {
+			b.mu.Unlock()
+			return cached.symbols, nil
+		}
+		if loading, ok := b.loading[category]; ok {
+			b.mu.Unlock()
+			select {
+			case <-loading:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		loading := make(chan struct{})
+		b.loading[category] = loading
+		b.mu.Unlock()
+
+		symbols, err := b.loadInstruments(ctx, category)
+
+		b.mu.Lock()
+		delete(b.loading, category)
+		if err == nil {
+			b.instruments[category] = bybitInstruments{symbols: symbols, loadedAt: b.now()}
+		}
+		b.mu.Unlock()
+		close(loading)
+
+		if err != nil && ok && ctx.Err() == nil {
+			return cached.symbols, nil
+		}
+		return symbols, err
+	}
+}
+
+// loadInstruments загружает все торгуемые пары категории к USDT (монета → пара)
+// Если у монеты есть и обычная пара, и контракт с множителем, выбирается пара с меньшим множителем.
+func (b *Bybit) loadInstruments(ctx context.Context, category string) (map[string]BybitSymbol, error) {
+	symbols := make(map[string]BybitSymbol)
+
+	cursor := ""
+	for {
+		var result bybitResponse[bybitInstrumentsResult]
+
+		query := map[string]string{
+			"category": category,
+			"limit":    strconv.Itoa(bybitInstrumentsLimit),
+		}
+		if cursor != "" {
+			query["cursor"] = cursor
+		}
+		if err := b.get(ctx, "/v5/market/instruments-info", query, &result); err != nil {
+			return nil, err
+		}
+		if result.RetCode != 0 {
+			return nil, bybitRetCodeError(result.RetCode, result.RetMsg)
+		}
+
+		for _, instrument := range result.Result.List {
+			if instrument.Status != bybitStatusTrading || instrument.QuoteCoin != bybitQuoteCoin {
+				continue
+			}
+			if category == BybitCategoryLinear && instrument.ContractType != bybitContractPerpetual {
+				continue
+			}
+
+			coin, multiplier, ok := bybitSymbolCoin(instrument.Symbol)
+			if !ok {
+				continue
+			}
+			if existing, ok := symbols[coin]; ok && existing.Multiplier <= multiplier {
+				continue
+			}
+			symbols[coin] = BybitSymbol{Category: category, Symbol: instrument.Symbol, Multiplier: multiplier}
+		}
+
+		cursor = result.Result.NextPageCursor
+		if cursor == "" || len(result.Result.List) == 0 {
+			return symbols, nil
+		}
+	}
+}
+
+// bybitSymbolCoin монета и множитель пары к USDT: XVGUSDT → XVG, 1, 1000PEPEUSDT и SHIB1000USDT → PEPE/SHIB, 1000
+func bybitSymbolCoin(symbol string) (string, float64, bool) {
+	base, ok := strings.CutSuffix(symbol, bybitQuoteCoin)
+	if !ok || base == "" {
+		return "", 0, false
+	}
+
+	// Сначала длинные множители: 10000SATSUSDT не должен разобраться как 1000 × 0SATS
+	for i := len(bybitMultipliers) - 1; i >= 0; i-- {
+		multiplier := bybitMultipliers[i]
+		prefix := strconv.Itoa(multiplier)
+		if coin, ok := strings.CutPrefix(base, prefix); ok && coin != "" {
+			return coin, float64(multiplier), true
+		}
+		if coin, ok := strings.CutSuffix(base, prefix); ok && coin != "" {
+			return coin, float64(multiplier), true
+		}
+	}
+
+	return base, 1, true
+}
+
+// GetCurrentPrice получает текущую цену одной монеты в USDT
+func (b *Bybit) GetCurrentPrice(ctx context.Context, coinSymbol string) (model.PricePoint, error) {
+	symbol, err := b.ResolveSymbol(ctx, coinSymbol)
+	if err != nil {
+		return model.PricePoint{}, err
+	}
+
+	ticker, err := b.GetTicker(ctx, symbol.Category, symbol.Symbol)
+	if err != nil {
+		return model.PricePoint{}, err
+	}
+
+	return tickerPricePoint(*ticker, symbol)
+}
+
+// GetCurrentPrices получает текущие цены нескольких монет
+// Пары монет подбираются через ResolveSymbol, затем тикеры каждой категории запрашиваются одним запросом.
+// Результаты и ошибки возвращаются в порядке coinSymbols.
+func (b *Bybit) GetCurrentPrices(ctx context.Context, coinSymbols []string) ([]model.PricePoint, []error) {
+	points := make([]model.PricePoint, len(coinSymbols))
+	errs := make([]error, len(coinSymbols))
+
+	symbols := make([]BybitSymbol, len(coinSymbols))
+	var categories []string
+	for i, coinSymbol := range coinSymbols {
+		symbols[i], errs[i] = b.ResolveSymbol(ctx, coinSymbol)
+		if errs[i] == nil && !slices.Contains(categories, symbols[i].Category) {
+			categories = append(categories, symbols[i].Category)
+		}
+	}
+
+	for _, category := range categories {
+		tickers, err := b.GetTickers(ctx, category)
+
+		for i, symbol := range symbols {
+			if errs[i] != nil || symbol.Category != category {
+				continue
+			}
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+
+			ticker, ok := tickers[symbol.Symbol]
+			if !ok {
+				errs[i] = fmt.Errorf("%w: %s/%s", ErrBybitSymbolNotFound, symbol.Category, symbol.Symbol)
+				continue
+			}
+			points[i], errs[i] = tickerPricePoint(ticker, symbol)
+		}
+	}
+
+	return points, errs
+}
+
+// tickerPricePoint переводит цену последней сделки пары в цену одной монеты
+func tickerPricePoint(ticker BybitTicker, symbol BybitSymbol) (model.PricePoint, error) {
+	price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+	if err != nil {
+		return model.PricePoint{}, fmt.Errorf("invalid Bybit last price %q for %s: %w", ticker.LastPrice, symbol.Symbol, err)
+	}
+
+	return model.PricePoint{
+		Price:     price / symbol.Multiplier,
+		Timestamp: ticker.Time,
+		Provider:  BybitProviderName,
+	}, nil
+}
+
+// GetHistoricalPrice получает цену одной монеты, ближайшую к моменту at, по минутным свечам
+// Сэмплом считается цена открытия свечи на момент ее начала
+func (b *Bybit) GetHistoricalPrice(ctx context.Context, coinSymbol string, at time.Time) (model.PricePoint, error) {
+	points, errs := b.GetHistoricalPrices(ctx, coinSymbol, []time.Time{at})
+	return points[0], errs[0]
+}
+
+// GetHistoricalPrices получает цены монеты на несколько моментов по минутным свечам
+// Близкие моменты объединяются в окна не длиннее bybitKlineLimit минут, на каждое окно - один запрос свечей.
+// Нет свечи рядом с моментом - ErrPriceGap (пара торгуется, цену стоит запросить повторно);
+// ErrPriceNotFound только для пары, которой на Bybit нет (ErrBybitSymbolNotFound).
+// Результаты и ошибки возвращаются в порядке ats.
+func (b *Bybit) GetHistoricalPrices(ctx context.Context, coinSymbol string, ats []time.Time) ([]model.PricePoint, []error) {
+	points := make([]model.PricePoint, len(ats))
+	errs := make([]error, len(ats))
+
+	symbol, err := b.ResolveSymbol(ctx, coinSymbol)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return points, errs
+	}
+
+	for _, window := range groupTimeWindows(ats, b.historyTolerance, (bybitKlineLimit-1)*time.Minute) {
+		klines, err := b.GetKlines(ctx, symbol.Category, symbol.Symbol, "1", window.From, window.To)
+		if err != nil {
+			for _, i := range window.Indexes {
+				errs[i] = err
+			}
+			continue
+		}
+
+		samples := make([]model.PricePoint, 0, len(klines))
+		for _, kline := range klines {
+			samples = append(samples, model.PricePoint{
+				Price:     kline.Open / symbol.Multiplier,
+				Timestamp: kline.StartTime,
+				Provider:  BybitProviderName,
+			})
+		}
+
+		for _, i := range window.Indexes {
+			point, err := model.NearestPricePoint(samples, ats[i], b.historyTolerance)
+			if err != nil {
+				errs[i] = fmt.Errorf("%w: historical price for coin: %s (%s): %w", ErrPriceGap, coinSymbol, symbol.Symbol, err)
+				continue
+			}
+			points[i] = point
+		}
+	}
+
+	return points, errs
+}
+
+func (b *Bybit) get(ctx context.Context, path string, query map[string]string, result interface{}) error {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	resp, err := b.client.R().
+		SetContext(ctx).
+		SetQueryParams(query).
+		SetResult(result).
+		Get(b.baseURL + path)
+
+	if err != nil {
+		return fmt.Errorf("%w: failed to get data from Bybit: %w", ErrTransport, err)
+	}
+
+	// Превышение лимита Bybit отдает статусом 403 (лимит по IP) или 429
+	if resp.StatusCode() == 403 || resp.StatusCode() == 429 {
+		return fmt.Errorf("Bybit %w: status %d", ErrRateLimited, resp.StatusCode())
+	}
+
+	if resp.StatusCode() >= 500 {
+		return fmt.Errorf("%w: Bybit API error: status %d", ErrTransport, resp.StatusCode())
+	}
+
+	if resp.IsError() {
+		return fmt.Errorf("Bybit API error: status %d", resp.StatusCode())
+	}
+
+	return nil
+}
+
+// bybitRetCodeError ошибка по ненулевому retCode ответа: 10006 - превышение лимита,
+// остальные коды - сбой на стороне API, по которому можно переключиться на следующий провайдер
+func bybitRetCodeError(retCode int, retMsg string) error {
+	if retCode == bybitRetCodeRateLimited {
+		return fmt.Errorf("Bybit %w: retCode %d: %s", ErrRateLimited, retCode, retMsg)
+	}
+
+	return fmt.Errorf("%w: Bybit API error: retCode %d: %s", ErrTransport, retCode, retMsg)
+}
+
+// parseBybitKline разбирает свечу формата [startTime, open, high, low, close, volume, turnover]
+func parseBybitKline(item []string) (BybitKline, error) {
+	if len(item) < 5 {
+		return BybitKline{}, fmt.Errorf("invalid Bybit kline: expected at least 5 fields, got %d", len(item))
+	}
+
+	startMs, err := strconv.ParseInt(item[0], 10, 64)
+	if err != nil {
+		return BybitKline{}, fmt.Errorf("invalid Bybit kline start time %q: %w", item[0], err)
+	}
+
+	values := make([]float64, 4)
+	for i := range values {
+		values[i], err = strconv.ParseFloat(item[i+1], 64)
+		if err != nil {
+			return BybitKline{}, fmt.Errorf("invalid Bybit kline value %q: %w", item[i+1], err)
+		}
+	}
+
+	return BybitKline{
+		StartTime: time.UnixMilli(startMs),
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+	}, nil
+}
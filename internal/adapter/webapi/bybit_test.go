@@ -0,0 +1,150 @@
+package webapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBybitStandIn возвращает клиент Bybit, которому отвечают записанные ответы из testdata/bybit
+// Ответ ищется по файлу <endpoint>_<category>_<symbol>.json, иначе отдается "Not supported symbols"
+func newBybitStandIn(t *testing.T) *Bybit {
+	t.Helper()
+
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		name := filepath.Base(r.URL.Path) + "_" + query.Get("category") + "_" + query.Get("symbol") + ".json"
+
+		body, err := os.ReadFile(filepath.Join("testdata", "bybit", name))
+		if err != nil {
+			body, err = os.ReadFile(filepath.Join("testdata", "bybit", "not_supported.json"))
+			assert.NoError(t, err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	})
+
+	bybit := NewBybit(resty.New(), 5*time.Minute, nil)
+	bybit.baseURL = server.URL
+
+	return bybit
+}
+
+func TestBybit_ResolveSymbol(t *testing.T) {
+	bybit := newBybitStandIn(t)
+	ctx := context.Background()
+
+	t.Run("Спотовая пара", func(t *testing.T) {
+		symbol, err := bybit.ResolveSymbol(ctx, "xvg")
+		assert.NoError(t, err)
+		assert.Equal(t, BybitSymbol{Category: BybitCategorySpot, Symbol: "XVGUSDT", Multiplier: 1}, symbol)
+	})
+
+	t.Run("Контракт с множителем", func(t *testing.T) {
+		symbol, err := bybit.ResolveSymbol(ctx, "PEPE")
+		assert.NoError(t, err)
+		assert.Equal(t, BybitSymbol{Category: BybitCategoryLinear, Symbol: "1000PEPEUSDT", Multiplier: 1000}, symbol)
+	})
+
+	t.Run("Множитель в конце названия", func(t *testing.T) {
+		symbol, err := bybit.ResolveSymbol(ctx, "SHIB")
+		assert.NoError(t, err)
+		assert.Equal(t, BybitSymbol{Category: BybitCategoryLinear, Symbol: "SHIB1000USDT", Multiplier: 1000}, symbol)
+
+		symbol, err = bybit.ResolveSymbol(ctx, "SATS")
+		assert.NoError(t, err)
+		assert.Equal(t, BybitSymbol{Category: BybitCategoryLinear, Symbol: "10000SATSUSDT", Multiplier: 10000}, symbol)
+	})
+
+	t.Run("Неизвестная монета", func(t *testing.T) {
+		_, err := bybit.ResolveSymbol(ctx, "UNKNOWN")
+		assert.True(t, errors.Is(err, ErrBybitSymbolNotFound))
+		assert.True(t, errors.Is(err, ErrPriceNotFound))
+
+		// Пара, которая не торгуется, не подходит
+		_, err = bybit.ResolveSymbol(ctx, "LUNA")
+		assert.True(t, errors.Is(err, ErrBybitSymbolNotFound))
+	})
+
+	t.Run("Списки пар не запрашиваются повторно", func(t *testing.T) {
+		// Сервер недоступен: и найденная, и ненайденная монета берутся из загруженных списков
+		baseURL := bybit.baseURL
+		bybit.baseURL = "http://127.0.0.1:0"
+		t.Cleanup(func() { bybit.baseURL = baseURL })
+
+		symbol, err := bybit.ResolveSymbol(ctx, "PEPE")
+		assert.NoError(t, err)
+		assert.Equal(t, "1000PEPEUSDT", symbol.Symbol)
+
+		_, err = bybit.ResolveSymbol(ctx, "UNKNOWN")
+		assert.True(t, errors.Is(err, ErrBybitSymbolNotFound))
+
+		// После bybitInstrumentsTTL список обновляется, а при ошибке обновления используется прежний
+		bybit.now = func() time.Time { return time.Now().Add(bybitInstrumentsTTL) }
+		t.Cleanup(func() { bybit.now = time.Now })
+
+		symbol, err = bybit.ResolveSymbol(ctx, "XVG")
+		assert.NoError(t, err)
+		assert.Equal(t, "XVGUSDT", symbol.Symbol)
+	})
+}
+
+func TestBybit_GetCurrentPrice(t *testing.T) {
+	bybit := newBybitStandIn(t)
+	ctx := context.Background()
+
+	point, err := bybit.GetCurrentPrice(ctx, "XVG")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.004612, point.Price)
+	assert.Equal(t, BybitProviderName, point.Provider)
+	assert.True(t, time.UnixMilli(1767000000000).Equal(point.Timestamp))
+
+	// Цена контракта 1000PEPEUSDT приводится к цене одной монеты
+	point, err = bybit.GetCurrentPrice(ctx, "PEPE")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.00001125, point.Price, 1e-12)
+}
+
+func TestBybit_GetHistoricalPrice(t *testing.T) {
+	bybit := newBybitStandIn(t)
+	ctx := context.Background()
+
+	t.Run("Ближайшая минутная свеча", func(t *testing.T) {
+		at := time.UnixMilli(1766977860000).Add(20 * time.Second)
+
+		point, err := bybit.GetHistoricalPrice(ctx, "XVG", at)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.004620, point.Price)
+		assert.True(t, time.UnixMilli(1766977860000).Equal(point.Timestamp))
+	})
+
+	t.Run("Нет свечей в окне допуска", func(t *testing.T) {
+		at := time.UnixMilli(1766977860000).Add(time.Hour)
+
+		_, err := bybit.GetHistoricalPrice(ctx, "XVG", at)
+		assert.ErrorIs(t, err, ErrPriceGap)
+		assert.NotErrorIs(t, err, ErrPriceNotFound)
+	})
+}
+
+func TestBybit_GetCurrentPrices(t *testing.T) {
+	bybit := newBybitStandIn(t)
+
+	// Тикеры каждой категории запрашиваются списком, а не по одной паре
+	points, errs := bybit.GetCurrentPrices(context.Background(), []string{"XVG", "PEPE", "UNKNOWN"})
+
+	assert.NoError(t, errs[0])
+	assert.Equal(t, 0.004615, points[0].Price)
+	assert.True(t, time.UnixMilli(1767000060000).Equal(points[0].Timestamp))
+	assert.NoError(t, errs[1])
+	assert.InDelta(t, 0.0000113, points[1].Price, 1e-12)
+	assert.True(t, errors.Is(errs[2], ErrBybitSymbolNotFound))
+}
@@ -3,38 +3,315 @@ package webapi
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/ratelimit"
 	"github.com/go-resty/resty/v2"
 )
 
+const (
+	CoinGeckoProviderName = "coingecko"
+
+	// coinGeckoMaxIDs - сколько монет запрашивается одним /simple/price
+	coinGeckoMaxIDs = 250
+	// coinGeckoMaxRange - максимальное окно market_chart/range: окна до 90 дней CoinGecko отдает
+	// не реже чем по часу, а 5-минутные данные есть только за последние сутки
+	coinGeckoMaxRange = 24 * time.Hour
+	// coinGeckoFineAge - насколько в прошлое CoinGecko хранит 5-минутные данные
+	coinGeckoFineAge = 24 * time.Hour
+	// coinGeckoHourlyTolerance - допуск для часовых данных: половина часа с запасом на неровную сетку
+	coinGeckoHourlyTolerance = 40 * time.Minute
+)
+
 type ICoinGecko interface {
-	GetCurrentPrice(ctx context.Context, coinSymbol string) (float64, error)
+	Name() string
+	GetCoinsList(ctx context.Context) ([]CoinGeckoCoin, error)
+	GetMarkets(ctx context.Context, coinIDs []string) ([]CoinGeckoMarket, error)
+	GetCurrentPrice(ctx context.Context, coinSymbol string) (model.PricePoint, error)
+	GetHistoricalPrice(ctx context.Context, coinSymbol string, at time.Time) (model.PricePoint, error)
+	GetCurrentPrices(ctx context.Context, coinSymbols []string) ([]model.PricePoint, []error)
+	GetHistoricalPrices(ctx context.Context, coinSymbol string, ats []time.Time) ([]model.PricePoint, []error)
+}
+
+// ICoinIDResolver сопоставляет символ монеты из таблицы ID монеты в CoinGecko
+type ICoinIDResolver interface {
+	ResolveCoinID(ctx context.Context, coinSymbol string) (string, error)
 }
 
 type CoinGecko struct {
-	client  *resty.Client
-	baseURL string
+	client           *resty.Client
+	baseURL          string
+	historyTolerance time.Duration
+	limiter          *ratelimit.Limiter
+	resolver         ICoinIDResolver
+	now              func() time.Time
+}
+
+// CoinGeckoCoin монета из каталога /coins/list
+type CoinGeckoCoin struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+// CoinGeckoMarket рыночные данные монеты из /coins/markets
+type CoinGeckoMarket struct {
+	ID            string `json:"id"`
+	Symbol        string `json:"symbol"`
+	Name          string `json:"name"`
+	MarketCapRank int    `json:"market_cap_rank"` // 0 - рейтинг неизвестен
 }
 
 type CoinGeckoSimplePriceResponse struct {
 	Price map[string]float64 `json:"usd"`
 }
 
-func NewCoinGecko(client *resty.Client) *CoinGecko {
+// CoinGeckoMarketChartResponse ответ /coins/{id}/market_chart/range
+// Каждый элемент prices - пара [unix ms, цена]
+type CoinGeckoMarketChartResponse struct {
+	Prices [][2]float64 `json:"prices"`
+}
+
+// NewCoinGecko создает клиент CoinGecko; limiter ограничивает частоту всех запросов клиента (nil - без ограничения)
+func NewCoinGecko(client *resty.Client, historyTolerance time.Duration, limiter *ratelimit.Limiter) *CoinGecko {
 	return &CoinGecko{
-		client:  client,
-		baseURL: "https://api.coingecko.com/api/v3",
+		client:           client,
+		baseURL:          "https://api.coingecko.com/api/v3",
+		historyTolerance: historyTolerance,
+		limiter:          limiter,
+		now:              time.Now,
+	}
+}
+
+// Name возвращает имя провайдера для маршрутизации цен
+func (c *CoinGecko) Name() string {
+	return CoinGeckoProviderName
+}
+
+// SetCoinIDResolver задает каталог, по которому символы монет переводятся в ID CoinGecko
+// Без каталога используется встроенный список популярных монет.
+func (c *CoinGecko) SetCoinIDResolver(resolver ICoinIDResolver) {
+	c.resolver = resolver
+}
+
+// GetCoinsList получает каталог всех монет CoinGecko (ID, символ, название)
+func (c *CoinGecko) GetCoinsList(ctx context.Context) ([]CoinGeckoCoin, error) {
+	var result []CoinGeckoCoin
+
+	if err := c.getWithRetry(ctx, "/coins/list", nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetMarkets получает рыночные данные (в том числе рейтинг по капитализации) для монет по ID
+func (c *CoinGecko) GetMarkets(ctx context.Context, coinIDs []string) ([]CoinGeckoMarket, error) {
+	var markets []CoinGeckoMarket
+
+	for start := 0; start < len(coinIDs); start += coinGeckoMaxIDs {
+		chunk := coinIDs[start:min(start+coinGeckoMaxIDs, len(coinIDs))]
+
+		var result []CoinGeckoMarket
+		err := c.getWithRetry(ctx, "/coins/markets", map[string]string{
+			"vs_currency": "usd",
+			"ids":         strings.Join(chunk, ","),
+			"per_page":    strconv.Itoa(coinGeckoMaxIDs),
+		}, &result)
+		if err != nil {
+			return nil, err
+		}
+		markets = append(markets, result...)
+	}
+
+	return markets, nil
+}
+
+// coinID возвращает ID монеты в CoinGecko по символу из таблицы
+func (c *CoinGecko) coinID(ctx context.Context, coinSymbol string) (string, error) {
+	if c.resolver == nil {
+		return c.symbolToCoinID(coinSymbol), nil
 	}
+
+	return c.resolver.ResolveCoinID(ctx, coinSymbol)
 }
 
 // GetCurrentPrice получает текущую цену монеты в USD с retry логикой
-func (c *CoinGecko) GetCurrentPrice(ctx context.Context, coinSymbol string) (float64, error) {
+func (c *CoinGecko) GetCurrentPrice(ctx context.Context, coinSymbol string) (model.PricePoint, error) {
+	points, errs := c.GetCurrentPrices(ctx, []string{coinSymbol})
+	return points[0], errs[0]
+}
+
+// GetCurrentPrices получает текущие цены нескольких монет запросами /simple/price по coinGeckoMaxIDs монет
+// Результаты и ошибки возвращаются в порядке coinSymbols
+func (c *CoinGecko) GetCurrentPrices(ctx context.Context, coinSymbols []string) ([]model.PricePoint, []error) {
+	points := make([]model.PricePoint, len(coinSymbols))
+	errs := make([]error, len(coinSymbols))
+
 	// CoinGecko использует ID монет, а не символы
 	// Нужно преобразовать символ в ID (например, BTC -> bitcoin, ETH -> ethereum)
-	coinID := c.symbolToCoinID(coinSymbol)
+	var coinIDs []string
+	indexesByID := make(map[string][]int)
+	for i, coinSymbol := range coinSymbols {
+		coinID, err := c.coinID(ctx, coinSymbol)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if _, ok := indexesByID[coinID]; !ok {
+			coinIDs = append(coinIDs, coinID)
+		}
+		indexesByID[coinID] = append(indexesByID[coinID], i)
+	}
+
+	for start := 0; start < len(coinIDs); start += coinGeckoMaxIDs {
+		chunk := coinIDs[start:min(start+coinGeckoMaxIDs, len(coinIDs))]
+
+		var result map[string]map[string]float64
+		err := c.getWithRetry(ctx, "/simple/price", map[string]string{
+			"ids":                     strings.Join(chunk, ","),
+			"vs_currencies":           "usd",
+			"include_last_updated_at": "true",
+		}, &result)
+
+		for _, coinID := range chunk {
+			for _, i := range indexesByID[coinID] {
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				points[i], errs[i] = simplePricePoint(result, coinID, coinSymbols[i])
+			}
+		}
+	}
+
+	return points, errs
+}
 
+// simplePricePoint извлекает цену монеты из ответа /simple/price
+func simplePricePoint(result map[string]map[string]float64, coinID string, coinSymbol string) (model.PricePoint, error) {
+	if priceData, ok := result[coinID]; ok {
+		if price, ok := priceData["usd"]; ok {
+			timestamp := time.Now()
+			if updatedAt, ok := priceData["last_updated_at"]; ok {
+				timestamp = time.Unix(int64(updatedAt), 0)
+			}
+
+			return model.PricePoint{Price: price, Timestamp: timestamp, Provider: CoinGeckoProviderName}, nil
+		}
+	}
+
+	return model.PricePoint{}, fmt.Errorf("%w for coin: %s (ID: %s)", ErrPriceNotFound, coinSymbol, coinID)
+}
+
+// GetHistoricalPrice получает цену монеты, ближайшую к моменту at
+// Запрашивается окно [at - tolerance, at + tolerance], из которого выбирается ближайший сэмпл.
+// 5-минутные данные CoinGecko отдает только за последние сутки, для более старых моментов
+// данные часовые, и допуск расширяется до coinGeckoHourlyTolerance.
+func (c *CoinGecko) GetHistoricalPrice(ctx context.Context, coinSymbol string, at time.Time) (model.PricePoint, error) {
+	points, errs := c.GetHistoricalPrices(ctx, coinSymbol, []time.Time{at})
+	return points[0], errs[0]
+}
+
+// GetHistoricalPrices получает цены монеты на несколько моментов
+// Моменты с одинаковым допуском (см. historyToleranceAt) объединяются в окна не длиннее суток,
+// на каждое окно делается один запрос /coins/{id}/market_chart/range.
+// Если рядом с моментом нет сэмпла, возвращается ErrPriceGap: монета у CoinGecko есть, и цену
+// стоит запросить повторно, а не записывать N/A.
+// Результаты и ошибки возвращаются в порядке ats.
+func (c *CoinGecko) GetHistoricalPrices(ctx context.Context, coinSymbol string, ats []time.Time) ([]model.PricePoint, []error) {
+	points := make([]model.PricePoint, len(ats))
+	errs := make([]error, len(ats))
+
+	coinID, err := c.coinID(ctx, coinSymbol)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return points, errs
+	}
+
+	now := c.now()
+
+	// Индексы моментов по допуску: свежие моменты ищутся в 5-минутных данных, старые - в часовых
+	var tolerances []time.Duration
+	groups := make(map[time.Duration][]int)
+	for i, at := range ats {
+		tolerance := c.historyToleranceAt(at, now)
+		if _, ok := groups[tolerance]; !ok {
+			tolerances = append(tolerances, tolerance)
+		}
+		groups[tolerance] = append(groups[tolerance], i)
+	}
+
+	for _, tolerance := range tolerances {
+		indexes := groups[tolerance]
+		groupAts := make([]time.Time, len(indexes))
+		for j, i := range indexes {
+			groupAts[j] = ats[i]
+		}
+
+		for _, window := range groupTimeWindows(groupAts, tolerance, coinGeckoMaxRange) {
+			to := window.To
+			if to.After(now) {
+				// Будущее CoinGecko не отдает
+				to = now
+			}
+
+			var result CoinGeckoMarketChartResponse
+
+			err := c.getWithRetry(ctx, fmt.Sprintf("/coins/%s/market_chart/range", coinID), map[string]string{
+				"vs_currency": "usd",
+				"from":        strconv.FormatInt(window.From.Unix(), 10),
+				"to":          strconv.FormatInt(to.Unix(), 10),
+			}, &result)
+			if err != nil {
+				for _, j := range window.Indexes {
+					errs[indexes[j]] = err
+				}
+				continue
+			}
+
+			samples := make([]model.PricePoint, 0, len(result.Prices))
+			for _, sample := range result.Prices {
+				samples = append(samples, model.PricePoint{
+					Price:     sample[1],
+					Timestamp: time.UnixMilli(int64(sample[0])),
+					Provider:  CoinGeckoProviderName,
+				})
+			}
+
+			for _, j := range window.Indexes {
+				i := indexes[j]
+				point, err := model.NearestPricePoint(samples, ats[i], tolerance)
+				if err != nil {
+					errs[i] = fmt.Errorf("%w: historical price for coin: %s (ID: %s): %w", ErrPriceGap, coinSymbol, coinID, err)
+					continue
+				}
+				points[i] = point
+			}
+		}
+	}
+
+	return points, errs
+}
+
+// historyToleranceAt допуск поиска сэмпла для момента at: настроенный для последних суток,
+// не меньше coinGeckoHourlyTolerance для более старых моментов, где у CoinGecko только часовые данные
+func (c *CoinGecko) historyToleranceAt(at time.Time, now time.Time) time.Duration {
+	if now.Sub(at) <= coinGeckoFineAge {
+		return c.historyTolerance
+	}
+
+	return max(c.historyTolerance, coinGeckoHourlyTolerance)
+}
+
+// getWithRetry выполняет GET запрос к CoinGecko, повторяя его при 429 (Too Many Requests)
+// Каждый запрос ждет токен лимитера; ожидание и задержки прерываются при отмене ctx.
+func (c *CoinGecko) getWithRetry(ctx context.Context, path string, query map[string]string, result interface{}) error {
 	// Retry логика для обработки rate limiting
 	maxRetries := 3
 	baseDelay := 2 * time.Second
@@ -43,24 +320,23 @@ func (c *CoinGecko) GetCurrentPrice(ctx context.Context, coinSymbol string) (flo
 		if attempt > 0 {
 			// Экспоненциальная задержка: 2s, 4s, 8s
 			delay := baseDelay * time.Duration(1<<uint(attempt-1))
-			time.Sleep(delay)
+			if err := c.limiter.Sleep(ctx, delay); err != nil {
+				return err
+			}
 		}
 
-		url := fmt.Sprintf("%s/simple/price", c.baseURL)
-
-		var result map[string]map[string]float64
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
 
 		resp, err := c.client.R().
 			SetContext(ctx).
-			SetQueryParams(map[string]string{
-				"ids":           coinID,
-				"vs_currencies": "usd",
-			}).
-			SetResult(&result).
-			Get(url)
+			SetQueryParams(query).
+			SetResult(result).
+			Get(c.baseURL + path)
 
 		if err != nil {
-			return 0, fmt.Errorf("failed to get price from CoinGecko: %w", err)
+			return fmt.Errorf("%w: failed to get price from CoinGecko: %w", ErrTransport, err)
 		}
 
 		// Если получили 429 (Too Many Requests), повторяем попытку
@@ -68,29 +344,30 @@ func (c *CoinGecko) GetCurrentPrice(ctx context.Context, coinSymbol string) (flo
 			if attempt < maxRetries {
 				continue
 			}
-			return 0, fmt.Errorf("CoinGecko rate limit exceeded after %d retries", maxRetries)
+			return fmt.Errorf("CoinGecko %w after %d retries", ErrRateLimited, maxRetries)
 		}
 
-		if resp.IsError() {
-			return 0, fmt.Errorf("CoinGecko API error: status %d", resp.StatusCode())
+		// Неизвестный ID монеты
+		if resp.StatusCode() == 404 {
+			return fmt.Errorf("%w: CoinGecko API status 404 for %s", ErrPriceNotFound, path)
 		}
 
-		// Извлекаем цену
-		if priceData, ok := result[coinID]; ok {
-			if price, ok := priceData["usd"]; ok {
-				// Небольшая задержка между запросами для соблюдения rate limit
-				time.Sleep(1 * time.Second)
-				return price, nil
-			}
+		if resp.StatusCode() >= 500 {
+			return fmt.Errorf("%w: CoinGecko API error: status %d", ErrTransport, resp.StatusCode())
 		}
 
-		return 0, fmt.Errorf("price not found for coin: %s (ID: %s)", coinSymbol, coinID)
+		if resp.IsError() {
+			return fmt.Errorf("CoinGecko API error: status %d", resp.StatusCode())
+		}
+
+		return nil
 	}
 
-	return 0, fmt.Errorf("failed to get price after retries")
+	return fmt.Errorf("failed to get price after retries")
 }
 
-// symbolToCoinID преобразует символ монеты в CoinGecko ID
+// symbolToCoinID преобразует символ монеты в CoinGecko ID по встроенному списку популярных монет
+// Используется, если каталог монет не подключен
 func (c *CoinGecko) symbolToCoinID(symbol string) string {
 	// Приводим к нижнему регистру
 	symbol = strings.ToLower(strings.TrimSpace(symbol))
@@ -150,4 +427,3 @@ func (c *CoinGecko) symbolToCoinID(symbol string) string {
 	// (может сработать для некоторых монет)
 	return symbol
 }
-
@@ -1,8 +1,16 @@
 package webapi
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -32,3 +40,151 @@ func TestCoinGecko_symbolToCoinID(t *testing.T) {
 	}
 }
 
+func TestCoinGecko_GetHistoricalPrice(t *testing.T) {
+	target := time.Date(2025, 12, 29, 3, 10, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/coins/bitcoin/market_chart/range", r.URL.Path)
+		assert.Equal(t, "usd", r.URL.Query().Get("vs_currency"))
+		assert.Equal(t, strconv.FormatInt(target.Add(-15*time.Minute).Unix(), 10), r.URL.Query().Get("from"))
+		assert.Equal(t, strconv.FormatInt(target.Add(15*time.Minute).Unix(), 10), r.URL.Query().Get("to"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"prices":[[%d,45000.5],[%d,45100.25],[%d,45200]]}`,
+			target.Add(-9*time.Minute).UnixMilli(),
+			target.Add(1*time.Minute).UnixMilli(),
+			target.Add(6*time.Minute).UnixMilli(),
+		)
+	}))
+	defer server.Close()
+
+	cg := NewCoinGecko(resty.New(), 15*time.Minute, nil)
+	cg.baseURL = server.URL
+	cg.now = func() time.Time { return target.Add(time.Hour) }
+
+	point, err := cg.GetHistoricalPrice(context.Background(), "BTC", target)
+	assert.NoError(t, err)
+	assert.Equal(t, 45100.25, point.Price)
+	assert.True(t, target.Add(1*time.Minute).Equal(point.Timestamp))
+}
+
+func TestCoinGecko_GetCurrentPrices(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/simple/price", r.URL.Path)
+		assert.Equal(t, "bitcoin,ethereum,unknown", r.URL.Query().Get("ids"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"bitcoin":{"usd":45000.5,"last_updated_at":1767000000},"ethereum":{"usd":3000}}`)
+	}))
+	defer server.Close()
+
+	cg := NewCoinGecko(resty.New(), 15*time.Minute, nil)
+	cg.baseURL = server.URL
+
+	points, errs := cg.GetCurrentPrices(context.Background(), []string{"BTC", "eth", "UNKNOWN", "btc"})
+	assert.Equal(t, 1, requests)
+
+	assert.NoError(t, errs[0])
+	assert.Equal(t, 45000.5, points[0].Price)
+	assert.Equal(t, int64(1767000000), points[0].Timestamp.Unix())
+	assert.NoError(t, errs[1])
+	assert.Equal(t, 3000.0, points[1].Price)
+	assert.ErrorIs(t, errs[2], ErrPriceNotFound)
+	assert.NoError(t, errs[3])
+	assert.Equal(t, 45000.5, points[3].Price)
+}
+
+func TestCoinGecko_GetHistoricalPrices(t *testing.T) {
+	signal := time.Date(2025, 12, 29, 3, 0, 0, 0, time.UTC)
+	ats := []time.Time{
+		signal.Add(1 * time.Hour),
+		signal.Add(10 * time.Minute),
+		signal.Add(72 * time.Hour), // Дальше суток - отдельное окно
+		signal.Add(6 * time.Hour),
+	}
+	// Первые три момента старше суток: у CoinGecko для них только часовые данные, допуск шире
+	now := signal.Add(73 * time.Hour)
+
+	var windows [][2]int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		to, _ := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+		windows = append(windows, [2]int64{from, to})
+
+		// Сэмплы каждые 5 минут, цена - номер 5-минутного интервала от сигнала
+		var samples []string
+		for ts := from - from%300; ts <= to; ts += 300 {
+			samples = append(samples, fmt.Sprintf("[%d,%d]", ts*1000, (ts-signal.Unix())/300))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"prices":[%s]}`, strings.Join(samples, ","))
+	}))
+	defer server.Close()
+
+	cg := NewCoinGecko(resty.New(), 15*time.Minute, nil)
+	cg.baseURL = server.URL
+	cg.now = func() time.Time { return now }
+
+	points, errs := cg.GetHistoricalPrices(context.Background(), "BTC", ats)
+	assert.Equal(t, [][2]int64{
+		{signal.Add(-30 * time.Minute).Unix(), signal.Add(6*time.Hour + 40*time.Minute).Unix()},
+		{signal.Add(72*time.Hour - 15*time.Minute).Unix(), signal.Add(72*time.Hour + 15*time.Minute).Unix()},
+	}, windows)
+
+	for i, at := range ats {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, float64(at.Sub(signal)/(5*time.Minute)), points[i].Price)
+		assert.True(t, at.Equal(points[i].Timestamp))
+	}
+}
+
+func TestCoinGecko_GetHistoricalPrices_Gap(t *testing.T) {
+	target := time.Date(2025, 12, 29, 3, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Ближайший сэмпл дальше допуска
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"prices":[[%d,45000]]}`, target.Add(-3*time.Hour).UnixMilli())
+	}))
+	defer server.Close()
+
+	cg := NewCoinGecko(resty.New(), 15*time.Minute, nil)
+	cg.baseURL = server.URL
+	cg.now = func() time.Time { return target.Add(time.Hour) }
+
+	_, err := cg.GetHistoricalPrice(context.Background(), "BTC", target)
+	assert.ErrorIs(t, err, ErrPriceGap)
+	assert.NotErrorIs(t, err, ErrPriceNotFound)
+}
+
+func TestCoinGecko_Catalogue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/coins/list":
+			fmt.Fprint(w, `[{"id":"bitcoin","symbol":"btc","name":"Bitcoin"},{"id":"verge","symbol":"xvg","name":"Verge"}]`)
+		case "/coins/markets":
+			assert.Equal(t, "bitcoin,batcat", r.URL.Query().Get("ids"))
+			fmt.Fprint(w, `[{"id":"bitcoin","symbol":"btc","name":"Bitcoin","market_cap_rank":1},{"id":"batcat","symbol":"btc","name":"batcat","market_cap_rank":null}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cg := NewCoinGecko(resty.New(), 15*time.Minute, nil)
+	cg.baseURL = server.URL
+
+	coins, err := cg.GetCoinsList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []CoinGeckoCoin{{ID: "bitcoin", Symbol: "btc", Name: "Bitcoin"}, {ID: "verge", Symbol: "xvg", Name: "Verge"}}, coins)
+
+	markets, err := cg.GetMarkets(context.Background(), []string{"bitcoin", "batcat"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, markets[0].MarketCapRank)
+	assert.Equal(t, 0, markets[1].MarketCapRank)
+}
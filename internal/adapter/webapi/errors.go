@@ -0,0 +1,17 @@
+package webapi
+
+import "errors"
+
+// Ошибки провайдеров цен, по которым можно переключиться на следующий провайдер
+var (
+	// ErrPriceNotFound - провайдер не знает монету: цена недоступна, повторять запрос бессмысленно
+	ErrPriceNotFound = errors.New("price not found")
+	// ErrPriceGap - монета у провайдера есть, но рядом с нужным моментом нет ни одного сэмпла
+	// (пропуск в данных, грубая сетка старых данных). В отличие от ErrPriceNotFound цена не считается
+	// недоступной навсегда: запрос повторится в следующем запуске.
+	ErrPriceGap = errors.New("no price sample near the requested time")
+	// ErrRateLimited - провайдер ограничил частоту запросов
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrTransport - сетевая ошибка, 5xx ответ или код ошибки API провайдера
+	ErrTransport = errors.New("transport error")
+)
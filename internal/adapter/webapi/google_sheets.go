@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 
+	"github.com/drybin/TrackMyCoin/pkg/a1"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
@@ -13,9 +15,24 @@ type IGoogleSheets interface {
 	ReadSpreadsheet(ctx context.Context, spreadsheetID string, readRange string) (*sheets.ValueRange, error)
 	GetSpreadsheetInfo(ctx context.Context, spreadsheetID string) (*sheets.Spreadsheet, error)
 	UpdateSpreadsheet(ctx context.Context, spreadsheetID string, writeRange string, values [][]interface{}) error
+	BatchUpdateSpreadsheet(ctx context.Context, spreadsheetID string, data []*sheets.ValueRange) error
 	ClearSpreadsheet(ctx context.Context, spreadsheetID string, clearRange string) error
+	AppendSpreadsheet(ctx context.Context, spreadsheetID string, tableRange string, values [][]interface{}) (string, error)
+	EnsureSheet(ctx context.Context, spreadsheetID string, title string) (bool, error)
+	OverwriteSheet(ctx context.Context, spreadsheetID string, title string, values [][]interface{}) error
+	FormatNumbers(ctx context.Context, spreadsheetID string, formats []NumberFormat) error
 }
 
+// NumberFormat числовой формат ячеек диапазона
+type NumberFormat struct {
+	Range   string // Диапазон в A1 нотации с именем листа, например "Лист1!F2:H10"
+	Pattern string // Шаблон формата Google Sheets, например "#,##0.00"
+}
+
+// valueInputOption значения записываются так, как если бы их ввели в ячейку вручную:
+// числа и даты становятся числами, а не текстом (RAW записывал бы "45000.5" текстом)
+const valueInputOption = "USER_ENTERED"
+
 type GoogleSheets struct {
 	service *sheets.Service
 }
@@ -62,7 +79,13 @@ func NewGoogleSheetsWithAPIKey(ctx context.Context, apiKey string) (*GoogleSheet
 }
 
 func (g *GoogleSheets) ReadSpreadsheet(ctx context.Context, spreadsheetID string, readRange string) (*sheets.ValueRange, error) {
-	resp, err := g.service.Spreadsheets.Values.Get(spreadsheetID, readRange).Context(ctx).Do()
+	// Значения без форматирования: числа приходят числами, а не текстом в формате отображения листа
+	// ("45 000,50", "$0.0046"), даты и время - серийными номерами (см. model.SerialTime)
+	resp, err := g.service.Spreadsheets.Values.Get(spreadsheetID, readRange).
+		ValueRenderOption("UNFORMATTED_VALUE").
+		DateTimeRenderOption("SERIAL_NUMBER").
+		Context(ctx).
+		Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve data from sheet: %w", err)
 	}
@@ -83,11 +106,11 @@ func (g *GoogleSheets) GetSpreadsheetInfo(ctx context.Context, spreadsheetID str
 // UpdateSpreadsheet записывает данные в таблицу
 func (g *GoogleSheets) UpdateSpreadsheet(ctx context.Context, spreadsheetID string, writeRange string, values [][]interface{}) error {
 	valueRange := &sheets.ValueRange{
-		Values: values,
+		Values: userEnteredValues(values),
 	}
 
 	_, err := g.service.Spreadsheets.Values.Update(spreadsheetID, writeRange, valueRange).
-		ValueInputOption("RAW").
+		ValueInputOption(valueInputOption).
 		Context(ctx).
 		Do()
 
@@ -98,6 +121,30 @@ func (g *GoogleSheets) UpdateSpreadsheet(ctx context.Context, spreadsheetID stri
 	return nil
 }
 
+// BatchUpdateSpreadsheet записывает несколько диапазонов одним запросом
+// Ячейки вне переданных диапазонов не затрагиваются
+func (g *GoogleSheets) BatchUpdateSpreadsheet(ctx context.Context, spreadsheetID string, data []*sheets.ValueRange) error {
+	escaped := make([]*sheets.ValueRange, 0, len(data))
+	for _, valueRange := range data {
+		escaped = append(escaped, &sheets.ValueRange{Range: valueRange.Range, Values: userEnteredValues(valueRange.Values)})
+	}
+
+	request := &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: valueInputOption,
+		Data:             escaped,
+	}
+
+	_, err := g.service.Spreadsheets.Values.BatchUpdate(spreadsheetID, request).
+		Context(ctx).
+		Do()
+
+	if err != nil {
+		return fmt.Errorf("unable to batch update data in sheet: %w", err)
+	}
+
+	return nil
+}
+
 // ClearSpreadsheet очищает данные в указанном диапазоне
 func (g *GoogleSheets) ClearSpreadsheet(ctx context.Context, spreadsheetID string, clearRange string) error {
 	_, err := g.service.Spreadsheets.Values.Clear(spreadsheetID, clearRange, &sheets.ClearValuesRequest{}).
@@ -111,3 +158,188 @@ func (g *GoogleSheets) ClearSpreadsheet(ctx context.Context, spreadsheetID strin
 	return nil
 }
 
+// AppendSpreadsheet добавляет строки после последней строки таблицы в диапазоне tableRange
+// Возвращает диапазон, в который записаны строки (например, "Лист1!A42:R42")
+func (g *GoogleSheets) AppendSpreadsheet(ctx context.Context, spreadsheetID string, tableRange string, values [][]interface{}) (string, error) {
+	valueRange := &sheets.ValueRange{
+		Values: userEnteredValues(values),
+	}
+
+	resp, err := g.service.Spreadsheets.Values.Append(spreadsheetID, tableRange, valueRange).
+		ValueInputOption(valueInputOption).
+		InsertDataOption("INSERT_ROWS").
+		Context(ctx).
+		Do()
+
+	if err != nil {
+		return "", fmt.Errorf("unable to append data to sheet: %w", err)
+	}
+
+	if resp.Updates == nil {
+		return "", fmt.Errorf("unable to append data to sheet: no updated range in response")
+	}
+
+	return resp.Updates.UpdatedRange, nil
+}
+
+// EnsureSheet создает лист с названием title, если его еще нет в таблице
+// Возвращает true, если лист был создан.
+func (g *GoogleSheets) EnsureSheet(ctx context.Context, spreadsheetID string, title string) (bool, error) {
+	spreadsheet, err := g.service.Spreadsheets.Get(spreadsheetID).
+		Fields("sheets.properties.title").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return false, fmt.Errorf("unable to retrieve spreadsheet info: %w", err)
+	}
+
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil && sheet.Properties.Title == title {
+			return false, nil
+		}
+	}
+
+	request := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: title}}},
+		},
+	}
+	if _, err := g.service.Spreadsheets.BatchUpdate(spreadsheetID, request).Context(ctx).Do(); err != nil {
+		return false, fmt.Errorf("unable to add sheet %q: %w", title, err)
+	}
+
+	return true, nil
+}
+
+// OverwriteSheet заменяет содержимое листа title значениями values начиная с ячейки A1
+// Остальные листы таблицы не затрагиваются.
+func (g *GoogleSheets) OverwriteSheet(ctx context.Context, spreadsheetID string, title string, values [][]interface{}) error {
+	if err := g.ClearSpreadsheet(ctx, spreadsheetID, a1.QuoteSheet(title)); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	return g.UpdateSpreadsheet(ctx, spreadsheetID, a1.Cell(title, 1, 1), values)
+}
+
+// FormatNumbers задает числовой формат ячеек диапазонов одним запросом
+// Значения ячеек не меняются, меняется только их отображение.
+func (g *GoogleSheets) FormatNumbers(ctx context.Context, spreadsheetID string, formats []NumberFormat) error {
+	if len(formats) == 0 {
+		return nil
+	}
+
+	spreadsheet, err := g.service.Spreadsheets.Get(spreadsheetID).
+		Fields("sheets.properties(sheetId,title)").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return fmt.Errorf("unable to retrieve spreadsheet info: %w", err)
+	}
+
+	sheetIDs := make(map[string]int64, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil {
+			sheetIDs[sheet.Properties.Title] = sheet.Properties.SheetId
+		}
+	}
+
+	requests := make([]*sheets.Request, 0, len(formats))
+	for _, format := range formats {
+		gridRange, err := toGridRange(format.Range, sheetIDs)
+		if err != nil {
+			return err
+		}
+
+		requests = append(requests, &sheets.Request{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: gridRange,
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						NumberFormat: &sheets.NumberFormat{Type: "NUMBER", Pattern: format.Pattern},
+					},
+				},
+				// Меняется только числовой формат: шрифт, цвет и выравнивание ячеек остаются прежними
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		})
+	}
+
+	request := &sheets.BatchUpdateSpreadsheetRequest{Requests: requests}
+	if _, err := g.service.Spreadsheets.BatchUpdate(spreadsheetID, request).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to format cells: %w", err)
+	}
+
+	return nil
+}
+
+// toGridRange переводит диапазон A1 в диапазон сетки (индексы с 0, конец не включается)
+// Открытые границы диапазона (A:A, F2:H) остаются открытыми.
+func toGridRange(a1Range string, sheetIDs map[string]int64) (*sheets.GridRange, error) {
+	r, err := a1.Parse(a1Range)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetID, ok := sheetIDs[r.Sheet]
+	if !ok {
+		return nil, fmt.Errorf("unknown sheet %q in range %s", r.Sheet, a1Range)
+	}
+
+	gridRange := &sheets.GridRange{
+		SheetId: sheetID,
+		// У первого листа таблицы часто sheetId = 0: без ForceSendFields нулевое поле не попадает в запрос
+		ForceSendFields: []string{"SheetId"},
+	}
+	if r.StartRow > 0 {
+		gridRange.StartRowIndex = int64(r.StartRow - 1)
+	}
+	if r.EndRow > 0 {
+		gridRange.EndRowIndex = int64(r.EndRow)
+	}
+	if r.StartCol > 0 {
+		gridRange.StartColumnIndex = int64(r.StartCol - 1)
+	}
+	if r.EndCol > 0 {
+		gridRange.EndColumnIndex = int64(r.EndCol)
+	}
+
+	return gridRange, nil
+}
+
+// userEnteredValues готовит значения к записи с USER_ENTERED
+// Текст, который таблица разобрала бы как формулу (=, +, -, @ в начале), и текст с апострофом в начале
+// экранируются апострофом: он не отображается в ячейке, а значение остается текстом.
+// Числа передаются как есть и записываются числами.
+func userEnteredValues(values [][]interface{}) [][]interface{} {
+	escaped := make([][]interface{}, len(values))
+	for i, row := range values {
+		escaped[i] = make([]interface{}, len(row))
+		for j, cell := range row {
+			escaped[i][j] = userEnteredCell(cell)
+		}
+	}
+
+	return escaped
+}
+
+func userEnteredCell(cell interface{}) interface{} {
+	text, ok := cell.(string)
+	if !ok || text == "" {
+		return cell
+	}
+
+	switch text[0] {
+	case '=', '+', '@', '\'':
+		return "'" + text
+	case '-':
+		// Отрицательное число записывается числом, остальной текст с минусом - текстом
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return "'" + text
+		}
+	}
+
+	return cell
+}
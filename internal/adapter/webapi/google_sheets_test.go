@@ -0,0 +1,91 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// newSheetsStandIn возвращает клиент Sheets и тела его POST-запросов, сгруппированные по пути
+func newSheetsStandIn(t *testing.T) (*GoogleSheets, map[string]map[string]interface{}) {
+	t.Helper()
+
+	requests := make(map[string]map[string]interface{})
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"sheets":[{"properties":{"sheetId":0,"title":"Лист1"}},{"properties":{"sheetId":42,"title":"Stats"}}]}`))
+			return
+		}
+
+		var body map[string]interface{}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests[r.URL.Path] = body
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	service, err := sheets.NewService(context.Background(),
+		option.WithEndpoint(server.URL+"/"),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(server.Client()),
+	)
+	require.NoError(t, err)
+
+	return &GoogleSheets{service: service}, requests
+}
+
+func TestGoogleSheets_BatchUpdateSpreadsheet(t *testing.T) {
+	client, requests := newSheetsStandIn(t)
+
+	err := client.BatchUpdateSpreadsheet(context.Background(), "test", []*sheets.ValueRange{
+		{Range: "Лист1!F2:I2", Values: [][]interface{}{{45000.5, "=IMPORTXML()", "-", "'abc"}}},
+	})
+	require.NoError(t, err)
+
+	body := requests["/v4/spreadsheets/test/values:batchUpdate"]
+	require.NotNil(t, body)
+	// Числа записываются числами, текст, похожий на формулу, остается текстом
+	assert.Equal(t, "USER_ENTERED", body["valueInputOption"])
+	assert.Equal(t, []interface{}{45000.5, "'=IMPORTXML()", "'-", "''abc"},
+		body["data"].([]interface{})[0].(map[string]interface{})["values"].([]interface{})[0])
+}
+
+func TestGoogleSheets_FormatNumbers(t *testing.T) {
+	client, requests := newSheetsStandIn(t)
+
+	err := client.FormatNumbers(context.Background(), "test", []NumberFormat{
+		{Range: "Лист1!F2:R3", Pattern: "#,##0.00"},
+		{Range: "Stats!B:B", Pattern: "0.000000"},
+	})
+	require.NoError(t, err)
+
+	body := requests["/v4/spreadsheets/test:batchUpdate"]
+	require.NotNil(t, body)
+	data, err := json.Marshal(body["requests"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"repeatCell": {
+			"range": {"sheetId": 0, "startRowIndex": 1, "endRowIndex": 3, "startColumnIndex": 5, "endColumnIndex": 18},
+			"cell": {"userEnteredFormat": {"numberFormat": {"type": "NUMBER", "pattern": "#,##0.00"}}},
+			"fields": "userEnteredFormat.numberFormat"
+		}},
+		{"repeatCell": {
+			"range": {"sheetId": 42, "startColumnIndex": 1, "endColumnIndex": 2},
+			"cell": {"userEnteredFormat": {"numberFormat": {"type": "NUMBER", "pattern": "0.000000"}}},
+			"fields": "userEnteredFormat.numberFormat"
+		}}
+	]`, string(data))
+
+	err = client.FormatNumbers(context.Background(), "test", []NumberFormat{{Range: "Нет такого!A1", Pattern: "0.00"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown sheet")
+}
@@ -0,0 +1,176 @@
+package webapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// telegramMaxMessageLength - максимальная длина текста сообщения в Bot API
+const telegramMaxMessageLength = 4096
+
+type ITelegram interface {
+	SendMessage(ctx context.Context, text string) error
+}
+
+// ITelegramBot получение команд боту и ответы на них
+type ITelegramBot interface {
+	GetUpdates(ctx context.Context, offset int64, pollTimeout time.Duration) ([]TelegramUpdate, error)
+	Reply(ctx context.Context, message TelegramMessage, text string) error
+}
+
+// Telegram отправляет сообщения в чат через Telegram Bot API
+type Telegram struct {
+	client   *resty.Client
+	baseURL  string
+	botToken string
+	chatID   string
+	timeout  time.Duration
+}
+
+// TelegramUpdate входящее обновление из getUpdates
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message"`
+}
+
+// TelegramMessage сообщение в чате
+type TelegramMessage struct {
+	MessageID int64         `json:"message_id"`
+	Chat      TelegramChat  `json:"chat"`
+	From      *TelegramUser `json:"from"`
+	Date      int64         `json:"date"`
+	Text      string        `json:"text"`
+}
+
+type TelegramChat struct {
+	ID int64 `json:"id"`
+}
+
+type TelegramUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type telegramReplyParameters struct {
+	MessageID int64 `json:"message_id"`
+}
+
+type telegramSendMessageRequest struct {
+	ChatID                string                   `json:"chat_id"`
+	Text                  string                   `json:"text"`
+	DisableWebPagePreview bool                     `json:"disable_web_page_preview"`
+	ReplyParameters       *telegramReplyParameters `json:"reply_parameters,omitempty"`
+}
+
+type telegramGetUpdatesRequest struct {
+	Offset         int64    `json:"offset"`
+	Timeout        int      `json:"timeout"`
+	AllowedUpdates []string `json:"allowed_updates"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+type telegramUpdatesResponse struct {
+	telegramResponse
+	Result []TelegramUpdate `json:"result"`
+}
+
+// NewTelegram создает клиент Bot API; timeout ограничивает время одного запроса (0 - без ограничения)
+func NewTelegram(client *resty.Client, botToken string, chatID string, timeout time.Duration) *Telegram {
+	return &Telegram{
+		client:   client,
+		baseURL:  "https://api.telegram.org",
+		botToken: botToken,
+		chatID:   chatID,
+		timeout:  timeout,
+	}
+}
+
+// SendMessage отправляет текстовое сообщение в чат ChatId
+// Текст длиннее лимита Bot API обрезается.
+func (t *Telegram) SendMessage(ctx context.Context, text string) error {
+	return t.sendMessage(ctx, t.chatID, text, nil)
+}
+
+// Reply отвечает на сообщение в его чате
+func (t *Telegram) Reply(ctx context.Context, message TelegramMessage, text string) error {
+	return t.sendMessage(ctx, strconv.FormatInt(message.Chat.ID, 10), text,
+		&telegramReplyParameters{MessageID: message.MessageID})
+}
+
+// GetUpdates получает новые сообщения боту начиная с offset (long polling не дольше pollTimeout)
+func (t *Telegram) GetUpdates(ctx context.Context, offset int64, pollTimeout time.Duration) ([]TelegramUpdate, error) {
+	var result telegramUpdatesResponse
+
+	// Запрос висит до pollTimeout, пока нет сообщений - таймаут клиента добавляется сверху
+	err := t.post(ctx, pollTimeout+t.timeout, "getUpdates", telegramGetUpdatesRequest{
+		Offset:         offset,
+		Timeout:        int(pollTimeout / time.Second),
+		AllowedUpdates: []string{"message"},
+	}, &result, &result.telegramResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Result, nil
+}
+
+func (t *Telegram) sendMessage(ctx context.Context, chatID string, text string, reply *telegramReplyParameters) error {
+	if runes := []rune(text); len(runes) > telegramMaxMessageLength {
+		text = string(runes[:telegramMaxMessageLength-1]) + "…"
+	}
+
+	var result telegramResponse
+	return t.post(ctx, t.timeout, "sendMessage", telegramSendMessageRequest{
+		ChatID:                chatID,
+		Text:                  text,
+		DisableWebPagePreview: true,
+		ReplyParameters:       reply,
+	}, &result, &result)
+}
+
+// post вызывает метод Bot API; timeout ограничивает время запроса (0 - без ограничения)
+func (t *Telegram) post(ctx context.Context, timeout time.Duration, method string, body interface{}, result interface{}, status *telegramResponse) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	resp, err := t.client.R().
+		SetContext(ctx).
+		SetBody(body).
+		SetResult(result).
+		SetError(result).
+		Post(t.baseURL + "/bot" + t.botToken + "/" + method)
+
+	if err != nil {
+		// Ошибка resty содержит URL с токеном бота - не выводим ее целиком
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%w: Telegram %s failed: %w", ErrTransport, method, ctxErr)
+		}
+		return fmt.Errorf("%w: Telegram %s failed", ErrTransport, method)
+	}
+
+	if resp.StatusCode() == 429 {
+		return fmt.Errorf("Telegram %w: %s", ErrRateLimited, status.Description)
+	}
+
+	if resp.StatusCode() >= 500 {
+		return fmt.Errorf("%w: Telegram API error: status %d", ErrTransport, resp.StatusCode())
+	}
+
+	if resp.IsError() || !status.OK {
+		return fmt.Errorf("Telegram API error: status %d: %s", resp.StatusCode(), status.Description)
+	}
+
+	return nil
+}
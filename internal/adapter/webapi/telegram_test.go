@@ -0,0 +1,127 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTelegramStandIn возвращает клиент Telegram, чьи sendMessage разбираются и передаются в handler
+func newTelegramStandIn(t *testing.T, handler func(w http.ResponseWriter, message telegramSendMessageRequest)) *Telegram {
+	t.Helper()
+
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/bottest-token/sendMessage", r.URL.Path)
+
+		var message telegramSendMessageRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, message)
+	})
+
+	telegram := NewTelegram(resty.New(), "test-token", "-100123", time.Second)
+	telegram.baseURL = server.URL
+
+	return telegram
+}
+
+func TestTelegram_SendMessage(t *testing.T) {
+	t.Run("Сообщение отправлено", func(t *testing.T) {
+		var received []telegramSendMessageRequest
+		telegram := newTelegramStandIn(t, func(w http.ResponseWriter, message telegramSendMessageRequest) {
+			received = append(received, message)
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+		})
+
+		require.NoError(t, telegram.SendMessage(context.Background(), "BTC long: +1.5%"))
+		assert.Equal(t, []telegramSendMessageRequest{
+			{ChatID: "-100123", Text: "BTC long: +1.5%", DisableWebPagePreview: true},
+		}, received)
+	})
+
+	t.Run("Длинное сообщение обрезается", func(t *testing.T) {
+		var received telegramSendMessageRequest
+		telegram := newTelegramStandIn(t, func(w http.ResponseWriter, message telegramSendMessageRequest) {
+			received = message
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		})
+
+		require.NoError(t, telegram.SendMessage(context.Background(), strings.Repeat("ы", 5000)))
+		assert.Len(t, []rune(received.Text), telegramMaxMessageLength)
+	})
+
+	t.Run("Ошибка Bot API", func(t *testing.T) {
+		telegram := newTelegramStandIn(t, func(w http.ResponseWriter, _ telegramSendMessageRequest) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+		})
+
+		err := telegram.SendMessage(context.Background(), "test")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "chat not found")
+	})
+
+	t.Run("Ограничение частоты", func(t *testing.T) {
+		telegram := newTelegramStandIn(t, func(w http.ResponseWriter, _ telegramSendMessageRequest) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5"}`))
+		})
+
+		assert.ErrorIs(t, telegram.SendMessage(context.Background(), "test"), ErrRateLimited)
+	})
+}
+
+func TestTelegram_GetUpdatesAndReply(t *testing.T) {
+	var replies []telegramSendMessageRequest
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/bottest-token/getUpdates":
+			var request telegramGetUpdatesRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, telegramGetUpdatesRequest{Offset: 7, Timeout: 30, AllowedUpdates: []string{"message"}}, request)
+
+			_, _ = w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"message_id":15,` +
+				`"chat":{"id":-100123},"from":{"id":42,"username":"trader"},"date":1766977200,"text":"/signal BTC long"}}]}`))
+		case "/bottest-token/sendMessage":
+			var message telegramSendMessageRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+			replies = append(replies, message)
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	telegram := NewTelegram(resty.New(), "test-token", "-100123", time.Second)
+	telegram.baseURL = server.URL
+
+	updates, err := telegram.GetUpdates(context.Background(), 7, 30*time.Second)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, TelegramMessage{
+		MessageID: 15,
+		Chat:      TelegramChat{ID: -100123},
+		From:      &TelegramUser{ID: 42, Username: "trader"},
+		Date:      1766977200,
+		Text:      "/signal BTC long",
+	}, *updates[0].Message)
+
+	require.NoError(t, telegram.Reply(context.Background(), *updates[0].Message, "ok"))
+	assert.Equal(t, []telegramSendMessageRequest{{
+		ChatID:                "-100123",
+		Text:                  "ok",
+		DisableWebPagePreview: true,
+		ReplyParameters:       &telegramReplyParameters{MessageID: 15},
+	}}, replies)
+}
@@ -0,0 +1,492 @@
+package coins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+)
+
+// ICatalogAPI методы CoinGecko, нужные для каталога монет
+type ICatalogAPI interface {
+	GetCoinsList(ctx context.Context) ([]webapi.CoinGeckoCoin, error)
+	GetMarkets(ctx context.Context, coinIDs []string) ([]webapi.CoinGeckoMarket, error)
+}
+
+// Способы, которыми символ сопоставлен монете
+const (
+	SourceOverride  = "override"   // Из файла ручных сопоставлений
+	SourceCatalogue = "catalogue"  // Единственная монета с таким символом в каталоге
+	SourceRank      = "market-cap" // Несколько монет с таким символом, выбрана с лучшим рейтингом капитализации
+)
+
+// Candidate монета каталога с подходящим символом
+type Candidate struct {
+	ID            string
+	Name          string
+	MarketCapRank int // 0 - рейтинг неизвестен
+}
+
+// Resolution результат сопоставления символа монете CoinGecko
+type Resolution struct {
+	Symbol     string
+	ID         string
+	Source     string
+	Candidates []Candidate // Все монеты каталога с этим символом (лучшие по рейтингу - первые)
+}
+
+// Resolver сопоставляет символы монет из таблицы ID монет CoinGecko
+// Каталог /coins/list хранится в файле cacheFile и обновляется, если он старше ttl.
+// Неоднозначные символы разрешаются по рейтингу капитализации, ручные сопоставления
+// из overridesFile имеют приоритет над каталогом.
+type Resolver struct {
+	api           ICatalogAPI
+	cacheFile     string
+	ttl           time.Duration
+	overridesFile string
+	now           func() time.Time
+
+	// mu защищает только ссылку на каталог: загрузки из CoinGecko идут без блокировки,
+	// чтобы символы из кэша разрешались, пока загружается каталог или рейтинг других монет
+	mu      sync.Mutex
+	catalog *catalog
+	loads   loadGroup
+	// saveMu не дает двум загрузкам одновременно писать файл кэша
+	saveMu sync.Mutex
+}
+
+// catalog содержимое файла кэша каталога
+// Каталог не меняется после создания: новый рейтинг сохраняется в копии каталога (withRanks).
+type catalog struct {
+	FetchedAt time.Time              `json:"fetched_at"`
+	Coins     []webapi.CoinGeckoCoin `json:"coins"`
+	// Ranks рейтинг капитализации монет, полученный при разрешении неоднозначных символов
+	Ranks map[string]int `json:"ranks,omitempty"`
+
+	bySymbol map[string][]webapi.CoinGeckoCoin
+}
+
+func NewResolver(api ICatalogAPI, cacheFile string, ttl time.Duration, overridesFile string) *Resolver {
+	return &Resolver{
+		api:           api,
+		cacheFile:     cacheFile,
+		ttl:           ttl,
+		overridesFile: overridesFile,
+		now:           time.Now,
+	}
+}
+
+// ResolveCoinID возвращает ID монеты CoinGecko для символа из таблицы
+func (r *Resolver) ResolveCoinID(ctx context.Context, coinSymbol string) (string, error) {
+	resolution, err := r.Resolve(ctx, coinSymbol)
+	if err != nil {
+		return "", err
+	}
+
+	return resolution.ID, nil
+}
+
+// Resolve сопоставляет символ монете CoinGecko и возвращает подробности: источник и всех кандидатов
+func (r *Resolver) Resolve(ctx context.Context, coinSymbol string) (Resolution, error) {
+	symbol := normalizeSymbol(coinSymbol)
+	resolution := Resolution{Symbol: symbol}
+
+	overrides, err := r.Overrides()
+	if err != nil {
+		return resolution, err
+	}
+
+	if id, ok := overrides[symbol]; ok {
+		resolution.ID = id
+		resolution.Source = SourceOverride
+		// Каталог для ручного сопоставления не обязателен
+		if cat, err := r.loadCatalog(ctx); err == nil {
+			resolution.Candidates = cat.candidates(symbol)
+		}
+		return resolution, nil
+	}
+
+	cat, err := r.loadCatalog(ctx)
+	if err != nil {
+		return resolution, err
+	}
+
+	coins := cat.bySymbol[strings.ToLower(symbol)]
+	switch len(coins) {
+	case 0:
+		return resolution, fmt.Errorf("%w: symbol %s is not in the CoinGecko catalogue", webapi.ErrPriceNotFound, symbol)
+	case 1:
+		resolution.ID = coins[0].ID
+		resolution.Source = SourceCatalogue
+		resolution.Candidates = cat.candidates(symbol)
+		return resolution, nil
+	}
+
+	if cat, err = r.loadRanks(ctx, symbol, coins); err != nil {
+		return resolution, err
+	}
+
+	resolution.Candidates = cat.candidates(symbol)
+	resolution.ID = resolution.Candidates[0].ID
+	resolution.Source = SourceRank
+
+	return resolution, nil
+}
+
+// Overrides возвращает ручные сопоставления символ → ID
+func (r *Resolver) Overrides() (map[string]string, error) {
+	overrides := make(map[string]string)
+	if r.overridesFile == "" {
+		return overrides, nil
+	}
+
+	data, err := os.ReadFile(r.overridesFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return overrides, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coin map %s: %w", r.overridesFile, err)
+	}
+
+	var stored map[string]string
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse coin map %s: %w", r.overridesFile, err)
+	}
+	for symbol, id := range stored {
+		overrides[normalizeSymbol(symbol)] = id
+	}
+
+	return overrides, nil
+}
+
+// AddOverride сохраняет ручное сопоставление символа монете CoinGecko
+// Если каталог доступен, ID проверяется по каталогу.
+func (r *Resolver) AddOverride(ctx context.Context, coinSymbol string, coinID string) error {
+	symbol := normalizeSymbol(coinSymbol)
+	coinID = strings.TrimSpace(coinID)
+	if symbol == "" || coinID == "" {
+		return fmt.Errorf("symbol and CoinGecko ID are required")
+	}
+
+	cat, err := r.loadCatalog(ctx)
+	if err == nil && !cat.hasID(coinID) {
+		return fmt.Errorf("coin ID %q is not in the CoinGecko catalogue", coinID)
+	}
+
+	overrides, err := r.Overrides()
+	if err != nil {
+		return err
+	}
+	overrides[symbol] = coinID
+
+	return r.saveOverrides(overrides)
+}
+
+// RemoveOverride удаляет ручное сопоставление символа
+func (r *Resolver) RemoveOverride(coinSymbol string) error {
+	symbol := normalizeSymbol(coinSymbol)
+
+	overrides, err := r.Overrides()
+	if err != nil {
+		return err
+	}
+	if _, ok := overrides[symbol]; !ok {
+		return fmt.Errorf("no mapping for symbol %s", symbol)
+	}
+	delete(overrides, symbol)
+
+	return r.saveOverrides(overrides)
+}
+
+func (r *Resolver) saveOverrides(overrides map[string]string) error {
+	if r.overridesFile == "" {
+		return fmt.Errorf("coin map file is not configured")
+	}
+
+	return writeJSONFile(r.overridesFile, overrides)
+}
+
+// loadCatalog возвращает каталог: из памяти, из файла кэша или, если кэш устарел, из CoinGecko
+// Если обновить каталог не удалось, используется устаревший кэш.
+// Одновременные вызовы не загружают каталог повторно, а ждут одной загрузки.
+func (r *Resolver) loadCatalog(ctx context.Context) (*catalog, error) {
+	if cat := r.currentCatalog(); cat != nil && r.now().Sub(cat.FetchedAt) < r.ttl {
+		return cat, nil
+	}
+
+	err := r.loads.do(ctx, "catalog", func() error {
+		return r.refreshCatalog(ctx)
+	})
+	if cat := r.currentCatalog(); cat != nil && err == nil {
+		return cat, nil
+	}
+
+	return nil, err
+}
+
+// refreshCatalog читает каталог из файла кэша, а если кэш устарел - загружает его из CoinGecko
+func (r *Resolver) refreshCatalog(ctx context.Context) error {
+	cat := r.currentCatalog()
+	if cat == nil && r.cacheFile != "" {
+		if cached, err := readCatalog(r.cacheFile); err == nil {
+			cat = cached
+			r.setCatalog(cached)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Warning: ignoring CoinGecko catalogue cache: %v", err)
+		}
+	}
+	if cat != nil && r.now().Sub(cat.FetchedAt) < r.ttl {
+		return nil
+	}
+
+	coins, err := r.api.GetCoinsList(ctx)
+	if err != nil {
+		if cat != nil {
+			log.Printf("Warning: failed to refresh CoinGecko catalogue, using cache from %s: %v",
+				cat.FetchedAt.Format(time.RFC3339), err)
+			return nil
+		}
+		return fmt.Errorf("failed to load CoinGecko catalogue: %w", err)
+	}
+
+	cat = newCatalog(coins, r.now())
+	r.setCatalog(cat)
+	r.saveCatalog(cat)
+
+	return nil
+}
+
+// loadRanks запрашивает рейтинг капитализации монет символа, для которых его еще нет в каталоге,
+// и возвращает каталог с этим рейтингом
+func (r *Resolver) loadRanks(ctx context.Context, symbol string, coins []webapi.CoinGeckoCoin) (*catalog, error) {
+	err := r.loads.do(ctx, "ranks:"+symbol, func() error {
+		cat := r.currentCatalog()
+
+		var missing []string
+		for _, coin := range coins {
+			if _, ok := cat.Ranks[coin.ID]; !ok {
+				missing = append(missing, coin.ID)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		markets, err := r.api.GetMarkets(ctx, missing)
+		if err != nil {
+			return fmt.Errorf("failed to get market cap ranks for %v: %w", missing, err)
+		}
+
+		// Монеты без рыночных данных запоминаются с нулевым рейтингом, чтобы не запрашивать их повторно
+		ranks := make(map[string]int, len(missing))
+		for _, id := range missing {
+			ranks[id] = 0
+		}
+		for _, market := range markets {
+			ranks[market.ID] = market.MarketCapRank
+		}
+
+		// Каталог мог обновиться во время запроса: рейтинг добавляется к текущему
+		r.mu.Lock()
+		cat = r.catalog.withRanks(ranks)
+		r.catalog = cat
+		r.mu.Unlock()
+		r.saveCatalog(cat)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.currentCatalog(), nil
+}
+
+func (r *Resolver) currentCatalog() *catalog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.catalog
+}
+
+func (r *Resolver) setCatalog(cat *catalog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.catalog = cat
+}
+
+func (r *Resolver) saveCatalog(cat *catalog) {
+	if r.cacheFile == "" {
+		return
+	}
+
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	if err := writeJSONFile(r.cacheFile, cat); err != nil {
+		log.Printf("Warning: failed to save CoinGecko catalogue cache: %v", err)
+	}
+}
+
+func newCatalog(coins []webapi.CoinGeckoCoin, fetchedAt time.Time) *catalog {
+	cat := &catalog{
+		FetchedAt: fetchedAt,
+		Coins:     coins,
+		Ranks:     make(map[string]int),
+	}
+	cat.index()
+
+	return cat
+}
+
+func readCatalog(path string) (*catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cat catalog
+	if err := json.Unmarshal(data, &cat); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cat.Ranks == nil {
+		cat.Ranks = make(map[string]int)
+	}
+	cat.index()
+
+	return &cat, nil
+}
+
+// withRanks копия каталога с добавленным рейтингом (монеты и индекс по символу общие)
+func (c *catalog) withRanks(ranks map[string]int) *catalog {
+	merged := make(map[string]int, len(c.Ranks)+len(ranks))
+	for id, rank := range c.Ranks {
+		merged[id] = rank
+	}
+	for id, rank := range ranks {
+		merged[id] = rank
+	}
+
+	copied := *c
+	copied.Ranks = merged
+	return &copied
+}
+
+func (c *catalog) index() {
+	c.bySymbol = make(map[string][]webapi.CoinGeckoCoin)
+	for _, coin := range c.Coins {
+		symbol := strings.ToLower(strings.TrimSpace(coin.Symbol))
+		c.bySymbol[symbol] = append(c.bySymbol[symbol], coin)
+	}
+}
+
+// candidates возвращает монеты с символом: сначала с лучшим рейтингом, монеты без рейтинга - в конце
+func (c *catalog) candidates(symbol string) []Candidate {
+	coins := c.bySymbol[strings.ToLower(symbol)]
+	candidates := make([]Candidate, 0, len(coins))
+	for _, coin := range coins {
+		candidates = append(candidates, Candidate{ID: coin.ID, Name: coin.Name, MarketCapRank: c.Ranks[coin.ID]})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].MarketCapRank, candidates[j].MarketCapRank
+		if (a == 0) != (b == 0) {
+			return b == 0
+		}
+		return a < b
+	})
+
+	return candidates
+}
+
+func (c *catalog) hasID(id string) bool {
+	for _, coin := range c.Coins {
+		if coin.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadGroup выполняет одну загрузку на ключ: вызовы с тем же ключом во время загрузки
+// ждут ее завершения и получают ее ошибку, а не загружают повторно
+type loadGroup struct {
+	mu    sync.Mutex
+	calls map[string]*loadCall
+}
+
+type loadCall struct {
+	done chan struct{}
+	err  error
+}
+
+func (g *loadGroup) do(ctx context.Context, key string, load func() error) error {
+	for {
+		g.mu.Lock()
+		if call, ok := g.calls[key]; ok {
+			g.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			// Загрузку прервала отмена чужого запроса - загружаем сами
+			if call.err != nil && ctx.Err() == nil && (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) {
+				continue
+			}
+			return call.err
+		}
+
+		call := &loadCall{done: make(chan struct{})}
+		if g.calls == nil {
+			g.calls = make(map[string]*loadCall)
+		}
+		g.calls[key] = call
+		g.mu.Unlock()
+
+		call.err = load()
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+
+		return call.err
+	}
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+func writeJSONFile(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы не оставить файл наполовину записанным
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return os.Rename(tmp, path)
+}
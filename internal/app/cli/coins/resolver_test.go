@@ -0,0 +1,243 @@
+package coins
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCatalogAPI каталог CoinGecko в памяти
+type fakeCatalogAPI struct {
+	coins       []webapi.CoinGeckoCoin
+	ranks       map[string]int
+	err         error
+	listCalls   int
+	marketCalls int
+
+	// started и release, если заданы: запрос сообщает о начале в started и ждет закрытия release
+	started chan string
+	release chan struct{}
+	mu      sync.Mutex
+}
+
+func (f *fakeCatalogAPI) GetCoinsList(_ context.Context) ([]webapi.CoinGeckoCoin, error) {
+	f.wait("list")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.listCalls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.coins, nil
+}
+
+func (f *fakeCatalogAPI) GetMarkets(_ context.Context, coinIDs []string) ([]webapi.CoinGeckoMarket, error) {
+	f.wait("markets")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.marketCalls++
+	var markets []webapi.CoinGeckoMarket
+	for _, id := range coinIDs {
+		if rank, ok := f.ranks[id]; ok {
+			markets = append(markets, webapi.CoinGeckoMarket{ID: id, MarketCapRank: rank})
+		}
+	}
+	return markets, nil
+}
+
+func (f *fakeCatalogAPI) wait(method string) {
+	if f.started == nil {
+		return
+	}
+
+	f.started <- method
+	<-f.release
+}
+
+func (f *fakeCatalogAPI) calls() (int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.listCalls, f.marketCalls
+}
+
+func newFakeCatalogAPI() *fakeCatalogAPI {
+	return &fakeCatalogAPI{
+		coins: []webapi.CoinGeckoCoin{
+			{ID: "verge", Symbol: "xvg", Name: "Verge"},
+			{ID: "bitcoin", Symbol: "btc", Name: "Bitcoin"},
+			{ID: "batcat", Symbol: "btc", Name: "batcat"},
+			{ID: "bitcoin-on-base", Symbol: "btc", Name: "Bitcoin on Base"},
+		},
+		ranks: map[string]int{"bitcoin": 1, "bitcoin-on-base": 2841},
+	}
+}
+
+func newTestResolver(t *testing.T, api *fakeCatalogAPI) *Resolver {
+	t.Helper()
+
+	dir := t.TempDir()
+	return NewResolver(api, filepath.Join(dir, "cache", "coins.json"), 24*time.Hour, filepath.Join(dir, "coin_map.json"))
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	api := newFakeCatalogAPI()
+	resolver := newTestResolver(t, api)
+	ctx := context.Background()
+
+	t.Run("Единственная монета с символом", func(t *testing.T) {
+		resolution, err := resolver.Resolve(ctx, " xvg ")
+		require.NoError(t, err)
+		assert.Equal(t, "XVG", resolution.Symbol)
+		assert.Equal(t, "verge", resolution.ID)
+		assert.Equal(t, SourceCatalogue, resolution.Source)
+	})
+
+	t.Run("Неоднозначный символ - лучший рейтинг капитализации", func(t *testing.T) {
+		resolution, err := resolver.Resolve(ctx, "BTC")
+		require.NoError(t, err)
+		assert.Equal(t, "bitcoin", resolution.ID)
+		assert.Equal(t, SourceRank, resolution.Source)
+		assert.Equal(t, []Candidate{
+			{ID: "bitcoin", Name: "Bitcoin", MarketCapRank: 1},
+			{ID: "bitcoin-on-base", Name: "Bitcoin on Base", MarketCapRank: 2841},
+			{ID: "batcat", Name: "batcat"},
+		}, resolution.Candidates)
+
+		// Рейтинг запрашивается один раз
+		_, err = resolver.Resolve(ctx, "btc")
+		require.NoError(t, err)
+		assert.Equal(t, 1, api.marketCalls)
+	})
+
+	t.Run("Символа нет в каталоге", func(t *testing.T) {
+		_, err := resolver.Resolve(ctx, "UNKNOWN")
+		assert.True(t, errors.Is(err, webapi.ErrPriceNotFound))
+	})
+
+	t.Run("Ручное сопоставление важнее каталога", func(t *testing.T) {
+		require.NoError(t, resolver.AddOverride(ctx, "btc", "batcat"))
+
+		id, err := resolver.ResolveCoinID(ctx, "BTC")
+		require.NoError(t, err)
+		assert.Equal(t, "batcat", id)
+
+		require.NoError(t, resolver.RemoveOverride("BTC"))
+		id, err = resolver.ResolveCoinID(ctx, "BTC")
+		require.NoError(t, err)
+		assert.Equal(t, "bitcoin", id)
+	})
+
+	t.Run("Ручное сопоставление проверяется по каталогу", func(t *testing.T) {
+		assert.Error(t, resolver.AddOverride(ctx, "BTC", "no-such-coin"))
+		assert.Error(t, resolver.RemoveOverride("XVG"))
+	})
+
+	assert.Equal(t, 1, api.listCalls)
+}
+
+func TestResolver_CatalogueCache(t *testing.T) {
+	api := newFakeCatalogAPI()
+	resolver := newTestResolver(t, api)
+	ctx := context.Background()
+
+	now := time.Date(2025, 12, 29, 10, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+
+	_, err := resolver.Resolve(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, 1, api.listCalls)
+
+	// Новый процесс читает каталог и рейтинги из файла
+	restarted := NewResolver(api, resolver.cacheFile, 24*time.Hour, resolver.overridesFile)
+	restarted.now = resolver.now
+	id, err := restarted.ResolveCoinID(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, "bitcoin", id)
+	assert.Equal(t, 1, api.listCalls)
+	assert.Equal(t, 1, api.marketCalls)
+
+	// Кэш устарел, а CoinGecko недоступен - используется устаревший кэш
+	now = now.Add(25 * time.Hour)
+	api.err = webapi.ErrTransport
+	id, err = restarted.ResolveCoinID(ctx, "XVG")
+	require.NoError(t, err)
+	assert.Equal(t, "verge", id)
+	assert.Equal(t, 2, api.listCalls)
+
+	// Кэш обновлен
+	api.err = nil
+	api.coins = append(api.coins, webapi.CoinGeckoCoin{ID: "new-coin", Symbol: "new", Name: "New"})
+	id, err = restarted.ResolveCoinID(ctx, "NEW")
+	require.NoError(t, err)
+	assert.Equal(t, "new-coin", id)
+	assert.Equal(t, 3, api.listCalls)
+}
+
+func TestResolver_NoCatalogue(t *testing.T) {
+	api := &fakeCatalogAPI{err: webapi.ErrTransport}
+	resolver := newTestResolver(t, api)
+
+	_, err := resolver.Resolve(context.Background(), "BTC")
+	assert.True(t, errors.Is(err, webapi.ErrTransport))
+}
+
+func TestResolver_ConcurrentLoads(t *testing.T) {
+	api := newFakeCatalogAPI()
+	resolver := newTestResolver(t, api)
+	ctx := context.Background()
+
+	resolveAsync := func(symbol string) chan error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := resolver.Resolve(ctx, symbol)
+			done <- err
+		}()
+		return done
+	}
+	requireDone := func(done chan error) {
+		t.Helper()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Resolve is blocked")
+		}
+	}
+
+	// Каталог загружается один раз, остальные вызовы ждут этой загрузки
+	api.started = make(chan string, 10)
+	api.release = make(chan struct{})
+	first := resolveAsync("XVG")
+	assert.Equal(t, "list", <-api.started)
+	second := resolveAsync("xvg")
+	close(api.release)
+	requireDone(first)
+	requireDone(second)
+	listCalls, _ := api.calls()
+	assert.Equal(t, 1, listCalls)
+
+	// Пока загружается рейтинг неоднозначного символа, символы из кэша разрешаются без ожидания
+	api.release = make(chan struct{})
+	ambiguous := resolveAsync("BTC")
+	assert.Equal(t, "markets", <-api.started)
+	requireDone(resolveAsync("XVG"))
+
+	sameSymbol := resolveAsync("btc")
+	close(api.release)
+	requireDone(ambiguous)
+	requireDone(sameSymbol)
+	_, marketCalls := api.calls()
+	assert.Equal(t, 1, marketCalls)
+}
@@ -2,9 +2,15 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
 	"github.com/drybin/TrackMyCoin/pkg/env"
+	"github.com/drybin/TrackMyCoin/pkg/ratelimit"
 	"github.com/drybin/TrackMyCoin/pkg/wrap"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
@@ -16,12 +22,53 @@ type Config struct {
 	GoogleServiceAccountFile string
 	GoogleSheetID            string
 	GoogleSheetRange         string
+	PriceHistoryTolerance    time.Duration
+	// SheetTargets таблицы и шаблоны названий листов, которые process и daemon обходят за один запуск
+	// (пусто - один лист GoogleSheetID / GoogleSheetRange)
+	SheetTargets []SheetTarget
+	// PriceRoutes колонка (или группа колонок) → провайдеры цен в порядке опроса
+	PriceRoutes map[string][]string
+	// ColumnAliases поле записи → дополнительные названия заголовка колонки
+	ColumnAliases map[string][]string
+	// Horizons горизонты, на которые фиксируется цена после сигнала
+	Horizons []model.Horizon
+	// RateLimits провайдер цен → ограничение частоты запросов к нему
+	RateLimits map[string]ratelimit.Rate
+	// PriceWorkers сколько запросов цен выполняется параллельно
+	PriceWorkers int
+	// CoinCatalogFile файл кэша каталога монет CoinGecko
+	CoinCatalogFile string
+	// CoinCatalogTTL как часто обновлять каталог монет CoinGecko
+	CoinCatalogTTL time.Duration
+	// CoinMapFile файл ручных сопоставлений символ → ID CoinGecko
+	CoinMapFile string
+	// DaemonPollInterval как часто daemon перечитывает лист, если ближайший горизонт еще не скоро
+	DaemonPollInterval time.Duration
+	// StatsSheet лист, в который process публикует статистику по горизонтам и источникам (пусто - не публиковать)
+	StatsSheet string
+	// Timezones часовые пояса даты и времени сигналов: листа (SHEET_TIMEZONE) и источников (SOURCE_TIMEZONES)
+	Timezones model.Timezones
+	// FormatNumbers после записи задавать числовой формат колонок цен (число знаков по величине цены)
+	FormatNumbers bool
+	// MarkNotDue писать WAIT в ячейки горизонтов, момент которых еще не наступил (по умолчанию они остаются пустыми)
+	MarkNotDue bool
+	// StoreFile файл SQLite, в котором сохраняются записи и все полученные цены (пусто - не сохранять)
+	StoreFile string
+}
+
+// SheetTarget таблица Google Sheets и шаблоны названий листов с сигналами
+type SheetTarget struct {
+	SpreadsheetID string
+	// Tabs шаблоны названий листов (*, ?, [...]), пусто - первый лист
+	Tabs []string
 }
 
 type TgConfig struct {
 	BotToken string
 	ChatId   string
 	Timeout  time.Duration
+	// AllowedChatIDs чаты, из которых бот принимает команды
+	AllowedChatIDs []int64
 }
 
 func (c Config) Validate() error {
@@ -29,6 +76,8 @@ func (c Config) Validate() error {
 
 	err := validation.ValidateStruct(&c,
 		validation.Field(&c.ServiceName, validation.Required),
+		validation.Field(&c.PriceWorkers, validation.Min(1)),
+		validation.Field(&c.DaemonPollInterval, validation.Min(time.Second)),
 	)
 	if err != nil {
 		return wrap.Errorf("failed to validate cli config: %w", err)
@@ -37,14 +86,82 @@ func (c Config) Validate() error {
 	return errors.Join(errs...)
 }
 
+// defaultPriceRoutes цена на Bybit берется с Bybit, временные колонки - по минутным свечам Bybit,
+// а если монеты там нет - с CoinGecko
+const defaultPriceRoutes = "bybit=bybit,coingecko;horizons=bybit,coingecko"
+
+// defaultRateLimits бесплатный тариф CoinGecko - около 30 запросов в минуту,
+// Bybit допускает 600 запросов за 5 секунд с одного IP, берем с запасом
+const defaultRateLimits = "coingecko=30/1m;bybit=20/1s"
+
 func InitConfig() (*Config, error) {
+	priceRoutes, err := parsePriceRoutes(env.GetString("PRICE_ROUTES", defaultPriceRoutes))
+	if err != nil {
+		return nil, wrap.Errorf("failed to parse PRICE_ROUTES: %w", err)
+	}
+
+	columnAliases, err := parseColumnAliases(env.GetString("COLUMN_ALIASES", ""))
+	if err != nil {
+		return nil, wrap.Errorf("failed to parse COLUMN_ALIASES: %w", err)
+	}
+
+	rateLimits, err := parseRateLimits(env.GetString("RATE_LIMITS", defaultRateLimits))
+	if err != nil {
+		return nil, wrap.Errorf("failed to parse RATE_LIMITS: %w", err)
+	}
+
+	sheetTargets, err := parseSheetTargets(env.GetString("SHEET_TARGETS", ""))
+	if err != nil {
+		return nil, wrap.Errorf("failed to parse SHEET_TARGETS: %w", err)
+	}
+
+	tgConfig, err := initTgConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	horizons := model.DefaultHorizons()
+	if spec := env.GetString("HORIZONS", ""); spec != "" {
+		if horizons, err = model.ParseHorizons(spec); err != nil {
+			return nil, wrap.Errorf("failed to parse HORIZONS: %w", err)
+		}
+	}
+
+	var sheetTimezone *time.Location
+	if name := env.GetString("SHEET_TIMEZONE", ""); name != "" {
+		if sheetTimezone, err = model.ParseTimezone(name); err != nil {
+			return nil, wrap.Errorf("failed to parse SHEET_TIMEZONE: %w", err)
+		}
+	}
+
+	sourceTimezones, err := parseSourceTimezones(env.GetString("SOURCE_TIMEZONES", ""))
+	if err != nil {
+		return nil, wrap.Errorf("failed to parse SOURCE_TIMEZONES: %w", err)
+	}
+
 	config := Config{
 		ServiceName:              env.GetString("APP_NAME", "TrackMyCoin"),
-		TgConfig:                 initTgConfig(),
+		TgConfig:                 tgConfig,
 		GoogleAPIKey:             env.GetString("GOOGLE_API_KEY", ""),
 		GoogleServiceAccountFile: env.GetString("GOOGLE_SERVICE_ACCOUNT_FILE", "service-account-file.json"),
 		GoogleSheetID:            env.GetString("GOOGLE_SHEET_ID", "1zDO5I9ZWnT9AbD--RT9NZX3aQgem6d1FEleq0ISsElk"),
 		GoogleSheetRange:         env.GetString("GOOGLE_SHEET_RANGE", ""), // Пусто = читать первый лист полностью
+		SheetTargets:             sheetTargets,
+		PriceHistoryTolerance:    env.GetDuration("PRICE_HISTORY_TOLERANCE", 15*time.Minute),
+		PriceRoutes:              priceRoutes,
+		ColumnAliases:            columnAliases,
+		Horizons:                 horizons,
+		RateLimits:               rateLimits,
+		PriceWorkers:             env.GetInt("PRICE_WORKERS", 4),
+		CoinCatalogFile:          env.GetString("COIN_CATALOG_FILE", ".cache/coingecko_coins.json"),
+		CoinCatalogTTL:           env.GetDuration("COIN_CATALOG_TTL", 24*time.Hour),
+		CoinMapFile:              env.GetString("COIN_MAP_FILE", "coin_map.json"),
+		DaemonPollInterval:       env.GetDuration("DAEMON_POLL_INTERVAL", 5*time.Minute),
+		Timezones:                model.NewTimezones(sheetTimezone, sourceTimezones),
+		StatsSheet:               parseOptional(env.GetString("STATS_SHEET", "Stats")),
+		StoreFile:                parseOptional(env.GetString("STORE_FILE", "")),
+		FormatNumbers:            env.GetBool("FORMAT_NUMBERS", true),
+		MarkNotDue:               env.GetBool("MARK_NOT_DUE", false),
 	}
 
 	if err := config.Validate(); err != nil {
@@ -54,10 +171,196 @@ func InitConfig() (*Config, error) {
 	return &config, nil
 }
 
-func initTgConfig() TgConfig {
+func initTgConfig() (TgConfig, error) {
+	chatID := env.GetString("TG_CHAT_ID", "")
+
+	// По умолчанию команды принимаются только из чата уведомлений
+	allowedChatIDs, err := parseChatIDs(env.GetString("TG_ALLOWED_CHAT_IDS", chatID))
+	if err != nil {
+		return TgConfig{}, wrap.Errorf("failed to parse TG_ALLOWED_CHAT_IDS: %w", err)
+	}
+
 	return TgConfig{
-		BotToken: env.GetString("TG_BOT_TOKEN", ""),
-		ChatId:   env.GetString("TG_CHAT_ID", ""),
-		Timeout:  10 * time.Second,
+		BotToken:       env.GetString("TG_BOT_TOKEN", ""),
+		ChatId:         chatID,
+		Timeout:        10 * time.Second,
+		AllowedChatIDs: allowedChatIDs,
+	}, nil
+}
+
+// parseOptional значение "off" отключает необязательную возможность (лист статистики, хранилище)
+func parseOptional(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "off") {
+		return ""
+	}
+
+	return value
+}
+
+// parseChatIDs разбирает список ID чатов Telegram вида "-1001234567890,42"
+func parseChatIDs(value string) ([]int64, error) {
+	var ids []int64
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat ID %q", item)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// parsePriceRoutes разбирает маршруты цен вида "bybit=bybit,coingecko;horizons=bybit,coingecko"
+func parsePriceRoutes(value string) (map[string][]string, error) {
+	routes := make(map[string][]string)
+
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		route, providersStr, ok := strings.Cut(item, "=")
+		route = strings.TrimSpace(route)
+		if !ok || route == "" {
+			return nil, fmt.Errorf("invalid route %q: expected column=provider[,provider...]", item)
+		}
+
+		var providers []string
+		for _, provider := range strings.Split(providersStr, ",") {
+			if provider = strings.TrimSpace(provider); provider != "" {
+				providers = append(providers, strings.ToLower(provider))
+			}
+		}
+		if len(providers) == 0 {
+			return nil, fmt.Errorf("invalid route %q: no providers", item)
+		}
+
+		routes[route] = providers
+	}
+
+	return routes, nil
+}
+
+// parseColumnAliases разбирает названия заголовков вида "Coin=Тикер|Ticker;BybitPrice=Bybit"
+func parseColumnAliases(value string) (map[string][]string, error) {
+	aliases := make(map[string][]string)
+
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		field, headersStr, ok := strings.Cut(item, "=")
+		field = strings.TrimSpace(field)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid column alias %q: expected Field=Header[|Header...]", item)
+		}
+
+		for _, header := range strings.Split(headersStr, "|") {
+			if header = strings.TrimSpace(header); header != "" {
+				aliases[field] = append(aliases[field], header)
+			}
+		}
+		if len(aliases[field]) == 0 {
+			return nil, fmt.Errorf("invalid column alias %q: no headers", item)
+		}
+	}
+
+	return aliases, nil
+}
+
+// parseSourceTimezones разбирает часовые пояса источников вида "ChannelA=UTC;ChannelB=Europe/Berlin"
+func parseSourceTimezones(value string) (map[string]*time.Location, error) {
+	timezones := make(map[string]*time.Location)
+
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		source, name, ok := strings.Cut(item, "=")
+		source = strings.TrimSpace(source)
+		if !ok || source == "" {
+			return nil, fmt.Errorf("invalid source timezone %q: expected Source=Timezone", item)
+		}
+
+		location, err := model.ParseTimezone(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone for %s: %w", source, err)
+		}
+		timezones[source] = location
 	}
+
+	return timezones, nil
+}
+
+// parseSheetTargets разбирает таблицы и шаблоны листов вида "<ID таблицы>=Сигналы 2025-*|Команда B;<ID таблицы>"
+// Таблица без шаблонов - ее первый лист.
+func parseSheetTargets(value string) ([]SheetTarget, error) {
+	var targets []SheetTarget
+
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		spreadsheetID, tabs, _ := strings.Cut(item, "=")
+		spreadsheetID = strings.TrimSpace(spreadsheetID)
+		if spreadsheetID == "" {
+			return nil, fmt.Errorf("invalid sheet target %q: expected SpreadsheetID=Tab|Tab", item)
+		}
+
+		target := SheetTarget{SpreadsheetID: spreadsheetID}
+		for _, pattern := range strings.Split(tabs, "|") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid tab pattern %q for %s: %w", pattern, spreadsheetID, err)
+			}
+			target.Tabs = append(target.Tabs, pattern)
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// parseRateLimits разбирает ограничения частоты вида "coingecko=30/1m;bybit=20/1s"
+func parseRateLimits(value string) (map[string]ratelimit.Rate, error) {
+	limits := make(map[string]ratelimit.Rate)
+
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		provider, rateStr, ok := strings.Cut(item, "=")
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if !ok || provider == "" {
+			return nil, fmt.Errorf("invalid rate limit %q: expected provider=limit/duration", item)
+		}
+
+		rate, err := ratelimit.ParseRate(rateStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit for %s: %w", provider, err)
+		}
+		limits[provider] = rate
+	}
+
+	return limits, nil
 }
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePriceRoutes(t *testing.T) {
+	t.Run("Маршруты по умолчанию", func(t *testing.T) {
+		routes, err := parsePriceRoutes(defaultPriceRoutes)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"bybit":    {"bybit", "coingecko"},
+			"horizons": {"bybit", "coingecko"},
+		}, routes)
+	})
+
+	t.Run("Пробелы и маршрут отдельной колонки", func(t *testing.T) {
+		routes, err := parsePriceRoutes(" bybit = Bybit ; 1M=coingecko ;")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"bybit": {"bybit"},
+			"1M":    {"coingecko"},
+		}, routes)
+	})
+
+	t.Run("Ошибки формата", func(t *testing.T) {
+		_, err := parsePriceRoutes("bybit")
+		assert.Error(t, err)
+
+		_, err = parsePriceRoutes("bybit=")
+		assert.Error(t, err)
+
+		_, err = parsePriceRoutes("=bybit")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseColumnAliases(t *testing.T) {
+	aliases, err := parseColumnAliases("Coin=Тикер | Ticker; BybitPrice=Bybit")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"Coin":       {"Тикер", "Ticker"},
+		"BybitPrice": {"Bybit"},
+	}, aliases)
+
+	aliases, err = parseColumnAliases("")
+	assert.NoError(t, err)
+	assert.Empty(t, aliases)
+
+	_, err = parseColumnAliases("Coin")
+	assert.Error(t, err)
+
+	_, err = parseColumnAliases("Coin=|")
+	assert.Error(t, err)
+}
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := parseRateLimits(defaultRateLimits)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]ratelimit.Rate{
+		"coingecko": {Limit: 30, Per: time.Minute},
+		"bybit":     {Limit: 20, Per: time.Second},
+	}, limits)
+
+	_, err = parseRateLimits("coingecko")
+	assert.Error(t, err)
+
+	_, err = parseRateLimits("coingecko=fast")
+	assert.Error(t, err)
+}
+
+func TestParseChatIDs(t *testing.T) {
+	ids, err := parseChatIDs(" -1001234567890, 42 ,")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{-1001234567890, 42}, ids)
+
+	ids, err = parseChatIDs("")
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	_, err = parseChatIDs("@channel")
+	assert.Error(t, err)
+}
+
+func TestParseSourceTimezones(t *testing.T) {
+	timezones, err := parseSourceTimezones(" ChannelA = UTC ; ChannelB=Europe/Berlin;")
+	assert.NoError(t, err)
+	assert.Len(t, timezones, 2)
+	assert.Equal(t, time.UTC, timezones["ChannelA"])
+	assert.Equal(t, "Europe/Berlin", timezones["ChannelB"].String())
+
+	_, err = parseSourceTimezones("ChannelA")
+	assert.Error(t, err)
+
+	_, err = parseSourceTimezones("ChannelA=Mars/Olympus")
+	assert.Error(t, err)
+}
+
+func TestParseSheetTargets(t *testing.T) {
+	targets, err := parseSheetTargets(" 1AbC-d_E = Сигналы 2025-* | Команда B ; 2xyz ;")
+	assert.NoError(t, err)
+	assert.Equal(t, []SheetTarget{
+		{SpreadsheetID: "1AbC-d_E", Tabs: []string{"Сигналы 2025-*", "Команда B"}},
+		{SpreadsheetID: "2xyz"},
+	}, targets)
+
+	targets, err = parseSheetTargets("")
+	assert.NoError(t, err)
+	assert.Empty(t, targets)
+
+	_, err = parseSheetTargets("=Лист1")
+	assert.Error(t, err)
+
+	_, err = parseSheetTargets("1AbC=[2025")
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+// Chain цепочка провайдеров цен
+// Запрос уходит первому провайдеру; если тот не нашел монету, упал в rate limit
+// или недоступен по сети, запрос повторяется у следующего провайдера.
+type Chain struct {
+	providers []IPriceProvider
+}
+
+// ChainError ошибки всех опрошенных провайдеров цепочки
+type ChainError struct {
+	Errors []error
+}
+
+func (e *ChainError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *ChainError) Unwrap() []error {
+	return e.Errors
+}
+
+func NewChain(providers ...IPriceProvider) *Chain {
+	return &Chain{
+		providers: providers,
+	}
+}
+
+// Names возвращает имена провайдеров в порядке опроса
+func (c *Chain) Names() []string {
+	names := make([]string, 0, len(c.providers))
+	for _, provider := range c.providers {
+		names = append(names, provider.Name())
+	}
+
+	return names
+}
+
+// GetCurrentPrice получает текущую цену у первого ответившего провайдера
+func (c *Chain) GetCurrentPrice(ctx context.Context, coinSymbol string) (model.PricePoint, error) {
+	return c.do(ctx, func(provider IPriceProvider) (model.PricePoint, error) {
+		return provider.GetCurrentPrice(ctx, coinSymbol)
+	})
+}
+
+// GetHistoricalPrice получает цену на момент at у первого ответившего провайдера
+func (c *Chain) GetHistoricalPrice(ctx context.Context, coinSymbol string, at time.Time) (model.PricePoint, error) {
+	return c.do(ctx, func(provider IPriceProvider) (model.PricePoint, error) {
+		return provider.GetHistoricalPrice(ctx, coinSymbol, at)
+	})
+}
+
+func (c *Chain) do(ctx context.Context, fetch func(provider IPriceProvider) (model.PricePoint, error)) (model.PricePoint, error) {
+	if len(c.providers) == 0 {
+		return model.PricePoint{}, fmt.Errorf("no price providers configured")
+	}
+
+	var errs []error
+	for _, provider := range c.providers {
+		point, err := fetch(provider)
+		if err == nil {
+			// Провайдер, который ответил, фиксируется в сэмпле
+			point.Provider = provider.Name()
+			return point, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+
+		if ctx.Err() != nil || !ShouldFallback(err) {
+			break
+		}
+	}
+
+	return model.PricePoint{}, &ChainError{Errors: errs}
+}
+
+// GetCurrentPrices получает текущие цены нескольких монет
+// Монеты, по которым провайдер не ответил, запрашиваются у следующего провайдера цепочки.
+// Результаты и ошибки возвращаются в порядке coinSymbols.
+func (c *Chain) GetCurrentPrices(ctx context.Context, coinSymbols []string) ([]model.PricePoint, []error) {
+	return c.doBatch(ctx, len(coinSymbols), func(provider IPriceProvider, indexes []int) ([]model.PricePoint, []error) {
+		coins := make([]string, len(indexes))
+		for i, index := range indexes {
+			coins[i] = coinSymbols[index]
+		}
+
+		if batch, ok := provider.(IBatchPriceProvider); ok {
+			return batch.GetCurrentPrices(ctx, coins)
+		}
+
+		points := make([]model.PricePoint, len(coins))
+		errs := make([]error, len(coins))
+		for i, coin := range coins {
+			points[i], errs[i] = provider.GetCurrentPrice(ctx, coin)
+		}
+		return points, errs
+	})
+}
+
+// GetHistoricalPrices получает цены монеты на несколько моментов
+// Моменты, по которым провайдер не ответил, запрашиваются у следующего провайдера цепочки.
+// Результаты и ошибки возвращаются в порядке ats.
+func (c *Chain) GetHistoricalPrices(ctx context.Context, coinSymbol string, ats []time.Time) ([]model.PricePoint, []error) {
+	return c.doBatch(ctx, len(ats), func(provider IPriceProvider, indexes []int) ([]model.PricePoint, []error) {
+		times := make([]time.Time, len(indexes))
+		for i, index := range indexes {
+			times[i] = ats[index]
+		}
+
+		if batch, ok := provider.(IBatchPriceProvider); ok {
+			return batch.GetHistoricalPrices(ctx, coinSymbol, times)
+		}
+
+		points := make([]model.PricePoint, len(times))
+		errs := make([]error, len(times))
+		for i, at := range times {
+			points[i], errs[i] = provider.GetHistoricalPrice(ctx, coinSymbol, at)
+		}
+		return points, errs
+	})
+}
+
+// doBatch опрашивает провайдеров по очереди, передавая следующему только те запросы (индексы),
+// по которым предыдущие провайдеры не ответили
+func (c *Chain) doBatch(
+	ctx context.Context,
+	count int,
+	fetch func(provider IPriceProvider, indexes []int) ([]model.PricePoint, []error),
+) ([]model.PricePoint, []error) {
+	points := make([]model.PricePoint, count)
+	errs := make([]error, count)
+	if len(c.providers) == 0 {
+		for i := range errs {
+			errs[i] = fmt.Errorf("no price providers configured")
+		}
+		return points, errs
+	}
+
+	providerErrs := make([][]error, count)
+	pending := make([]int, count)
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for _, provider := range c.providers {
+		if len(pending) == 0 {
+			break
+		}
+
+		batchPoints, batchErrs := fetch(provider, pending)
+
+		var next []int
+		for i, index := range pending {
+			if batchErrs[i] == nil {
+				// Провайдер, который ответил, фиксируется в сэмпле
+				points[index] = batchPoints[i]
+				points[index].Provider = provider.Name()
+				continue
+			}
+
+			providerErrs[index] = append(providerErrs[index], fmt.Errorf("%s: %w", provider.Name(), batchErrs[i]))
+			if ShouldFallback(batchErrs[i]) {
+				next = append(next, index)
+			}
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+		pending = next
+	}
+
+	for index, indexErrs := range providerErrs {
+		if len(indexErrs) > 0 && points[index].Provider == "" {
+			errs[index] = &ChainError{Errors: indexErrs}
+		}
+	}
+
+	return points, errs
+}
+
+// ShouldFallback определяет, есть ли смысл спросить цену у следующего провайдера
+func ShouldFallback(err error) bool {
+	return errors.Is(err, webapi.ErrPriceNotFound) ||
+		errors.Is(err, webapi.ErrPriceGap) ||
+		errors.Is(err, webapi.ErrRateLimited) ||
+		errors.Is(err, webapi.ErrTransport)
+}
+
+// IsNotAvailable проверяет, что ни один провайдер цепочки не нашел цену
+// (а не упал в rate limit или по сети) - повторный запрос ничего не даст
+func IsNotAvailable(err error) bool {
+	var chainErr *ChainError
+	if !errors.As(err, &chainErr) {
+		return errors.Is(err, webapi.ErrPriceNotFound)
+	}
+	if len(chainErr.Errors) == 0 {
+		return false
+	}
+
+	for _, providerErr := range chainErr.Errors {
+		if !errors.Is(providerErr, webapi.ErrPriceNotFound) {
+			return false
+		}
+	}
+
+	return true
+}
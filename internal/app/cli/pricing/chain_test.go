@@ -0,0 +1,195 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProvider struct {
+	name  string
+	price float64
+	err   error
+	calls int
+}
+
+func (f *fakeProvider) Name() string {
+	return f.name
+}
+
+func (f *fakeProvider) GetCurrentPrice(_ context.Context, _ string) (model.PricePoint, error) {
+	f.calls++
+	if f.err != nil {
+		return model.PricePoint{}, f.err
+	}
+	return model.PricePoint{Price: f.price, Timestamp: time.Now()}, nil
+}
+
+func (f *fakeProvider) GetHistoricalPrice(_ context.Context, _ string, at time.Time) (model.PricePoint, error) {
+	f.calls++
+	if f.err != nil {
+		return model.PricePoint{}, f.err
+	}
+	return model.PricePoint{Price: f.price, Timestamp: at}, nil
+}
+
+func TestChain_Fallback(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Первый провайдер ответил", func(t *testing.T) {
+		first := &fakeProvider{name: "bybit", price: 100}
+		second := &fakeProvider{name: "coingecko", price: 200}
+
+		point, err := NewChain(first, second).GetCurrentPrice(ctx, "BTC")
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, point.Price)
+		assert.Equal(t, "bybit", point.Provider)
+		assert.Equal(t, 0, second.calls)
+	})
+
+	for _, sentinel := range []error{webapi.ErrPriceNotFound, webapi.ErrPriceGap, webapi.ErrRateLimited, webapi.ErrTransport} {
+		t.Run("Переход к следующему провайдеру: "+sentinel.Error(), func(t *testing.T) {
+			first := &fakeProvider{name: "bybit", err: fmt.Errorf("wrapped: %w", sentinel)}
+			second := &fakeProvider{name: "coingecko", price: 200}
+
+			point, err := NewChain(first, second).GetHistoricalPrice(ctx, "BTC", time.Now())
+			assert.NoError(t, err)
+			assert.Equal(t, 200.0, point.Price)
+			assert.Equal(t, "coingecko", point.Provider)
+		})
+	}
+
+	t.Run("Прочие ошибки не переключают провайдер", func(t *testing.T) {
+		first := &fakeProvider{name: "bybit", err: errors.New("invalid response")}
+		second := &fakeProvider{name: "coingecko", price: 200}
+
+		_, err := NewChain(first, second).GetCurrentPrice(ctx, "BTC")
+		assert.Error(t, err)
+		assert.Equal(t, 0, second.calls)
+	})
+
+	t.Run("Все провайдеры не нашли цену", func(t *testing.T) {
+		first := &fakeProvider{name: "bybit", err: webapi.ErrPriceNotFound}
+		second := &fakeProvider{name: "coingecko", err: webapi.ErrRateLimited}
+
+		_, err := NewChain(first, second).GetCurrentPrice(ctx, "BTC")
+		assert.ErrorIs(t, err, webapi.ErrPriceNotFound)
+		assert.ErrorIs(t, err, webapi.ErrRateLimited)
+		assert.Contains(t, err.Error(), "bybit: ")
+		assert.Contains(t, err.Error(), "coingecko: ")
+	})
+}
+
+// bybitRoundTripper отвечает на любой запрос к Bybit статусом status и телом body
+type bybitRoundTripper struct {
+	status int
+	body   string
+}
+
+func (b bybitRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: b.status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(b.body)),
+		Request:    r,
+	}, nil
+}
+
+func TestChain_FallbackOnBybitRateLimit(t *testing.T) {
+	responses := map[string]bybitRoundTripper{
+		"retCode 10006": {status: http.StatusOK, body: `{"retCode":10006,"retMsg":"Too many visits!","result":{},"time":1767002400000}`},
+		"Статус 403":    {status: http.StatusForbidden, body: `{}`},
+	}
+
+	for name, response := range responses {
+		t.Run(name, func(t *testing.T) {
+			bybit := webapi.NewBybit(resty.New().SetTransport(response), 5*time.Minute, nil)
+			coingecko := &fakeProvider{name: "coingecko", price: 200}
+
+			point, err := NewChain(bybit, coingecko).GetCurrentPrice(context.Background(), "BTC")
+			assert.NoError(t, err)
+			assert.Equal(t, 200.0, point.Price)
+			assert.Equal(t, "coingecko", point.Provider)
+
+			_, err = bybit.GetCurrentPrice(context.Background(), "BTC")
+			assert.ErrorIs(t, err, webapi.ErrRateLimited)
+		})
+	}
+
+	t.Run("Прочие retCode - сбой API", func(t *testing.T) {
+		bybit := webapi.NewBybit(resty.New().SetTransport(bybitRoundTripper{
+			status: http.StatusOK,
+			body:   `{"retCode":10016,"retMsg":"Internal server error","result":{},"time":1767002400000}`,
+		}), 5*time.Minute, nil)
+
+		_, err := bybit.GetCurrentPrice(context.Background(), "BTC")
+		assert.ErrorIs(t, err, webapi.ErrTransport)
+		assert.True(t, ShouldFallback(err))
+	})
+}
+
+func TestRouter_Chain(t *testing.T) {
+	registry := NewRegistry(&fakeProvider{name: "bybit"}, &fakeProvider{name: "coingecko"})
+
+	router, err := NewRouter(registry, map[string][]string{
+		RouteBybit:    {"bybit"},
+		RouteHorizons: {"bybit", "coingecko"},
+		"1M":          {"coingecko"},
+	})
+	assert.NoError(t, err)
+
+	chain, err := router.Chain(RouteBybit)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bybit"}, chain.Names())
+
+	chain, err = router.Chain("10m", RouteHorizons)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bybit", "coingecko"}, chain.Names())
+
+	chain, err = router.Chain("1M", RouteHorizons)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"coingecko"}, chain.Names())
+
+	_, err = router.Chain("unknown")
+	assert.Error(t, err)
+
+	_, err = NewRouter(registry, map[string][]string{RouteBybit: {"binance"}})
+	assert.Error(t, err)
+}
+
+func TestIsNotAvailable(t *testing.T) {
+	notFound := fmt.Errorf("coin XYZ: %w", webapi.ErrPriceNotFound)
+	rateLimited := fmt.Errorf("429: %w", webapi.ErrRateLimited)
+
+	_, err := NewChain(
+		&fakeProvider{name: "bybit", err: notFound},
+		&fakeProvider{name: "coingecko", err: notFound},
+	).GetCurrentPrice(context.Background(), "XYZ")
+	assert.True(t, IsNotAvailable(err))
+
+	// Один из провайдеров не ответил - цена может найтись в следующий запуск
+	_, err = NewChain(
+		&fakeProvider{name: "bybit", err: notFound},
+		&fakeProvider{name: "coingecko", err: rateLimited},
+	).GetCurrentPrice(context.Background(), "XYZ")
+	assert.False(t, IsNotAvailable(err))
+
+	// Пропуск в исторических данных - не повод ставить N/A навсегда
+	_, err = NewChain(
+		&fakeProvider{name: "bybit", err: notFound},
+		&fakeProvider{name: "coingecko", err: fmt.Errorf("BTC: %w", webapi.ErrPriceGap)},
+	).GetHistoricalPrice(context.Background(), "BTC", time.Now())
+	assert.False(t, IsNotAvailable(err))
+
+	assert.False(t, IsNotAvailable(errors.New("no price providers configured")))
+}
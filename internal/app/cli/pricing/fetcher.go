@@ -0,0 +1,200 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+// Fetcher собирает запросы цен за запуск и выполняет их пачками
+// Одинаковые запросы (та же цепочка провайдеров, монета и момент) выполняются один раз,
+// результаты хранятся до конца запуска.
+type Fetcher struct {
+	router    *Router
+	pending   []fetchKey
+	requested map[fetchKey]bool
+
+	mu      sync.Mutex
+	results map[fetchKey]fetchResult
+}
+
+// fetchKey запрос цены: цепочка провайдеров, монета и момент в unix ms (0 - текущая цена)
+type fetchKey struct {
+	chain *Chain
+	coin  string
+	at    int64
+}
+
+type fetchResult struct {
+	point model.PricePoint
+	err   error
+}
+
+// NewFetcher создает сборщик запросов цен на один запуск
+func (r *Router) NewFetcher() *Fetcher {
+	return &Fetcher{
+		router:    r,
+		requested: make(map[fetchKey]bool),
+		results:   make(map[fetchKey]fetchResult),
+	}
+}
+
+// RequestCurrent добавляет запрос текущей цены монеты для колонки с маршрутами routes
+func (f *Fetcher) RequestCurrent(coinSymbol string, routes ...string) {
+	f.request(coinSymbol, time.Time{}, routes)
+}
+
+// RequestHistorical добавляет запрос цены монеты на момент at для колонки с маршрутами routes
+func (f *Fetcher) RequestHistorical(coinSymbol string, at time.Time, routes ...string) {
+	f.request(coinSymbol, at, routes)
+}
+
+// Fetch выполняет все добавленные запросы
+// Текущие цены запрашиваются одной пачкой на цепочку, исторические - одной пачкой на цепочку и монету.
+// Пачки выполняются параллельно не более чем workers горутинами; частоту запросов к каждому провайдеру
+// ограничивает лимитер провайдера. При отмене ctx невыполненные пачки завершаются с ошибкой ctx.
+func (f *Fetcher) Fetch(ctx context.Context, workers int) {
+	type historyGroup struct {
+		chain *Chain
+		coin  string
+	}
+
+	var currentChains []*Chain
+	currentKeys := make(map[*Chain][]fetchKey)
+	var historyGroups []historyGroup
+	historyKeys := make(map[historyGroup][]fetchKey)
+
+	for _, key := range f.pending {
+		if key.at == 0 {
+			if _, ok := currentKeys[key.chain]; !ok {
+				currentChains = append(currentChains, key.chain)
+			}
+			currentKeys[key.chain] = append(currentKeys[key.chain], key)
+			continue
+		}
+
+		group := historyGroup{chain: key.chain, coin: key.coin}
+		if _, ok := historyKeys[group]; !ok {
+			historyGroups = append(historyGroups, group)
+		}
+		historyKeys[group] = append(historyKeys[group], key)
+	}
+	f.pending = nil
+
+	var jobs []func()
+	for _, chain := range currentChains {
+		keys := currentKeys[chain]
+		jobs = append(jobs, func() {
+			coins := make([]string, len(keys))
+			for i, key := range keys {
+				coins[i] = key.coin
+			}
+
+			points, errs := chain.GetCurrentPrices(ctx, coins)
+			f.store(keys, points, errs)
+		})
+	}
+
+	for _, group := range historyGroups {
+		keys := historyKeys[group]
+		jobs = append(jobs, func() {
+			ats := make([]time.Time, len(keys))
+			for i, key := range keys {
+				ats[i] = time.UnixMilli(key.at)
+			}
+
+			points, errs := group.chain.GetHistoricalPrices(ctx, group.coin, ats)
+			f.store(keys, points, errs)
+		})
+	}
+
+	runPool(jobs, workers)
+}
+
+// runPool выполняет задачи не более чем workers горутинами и ждет их завершения
+func runPool(jobs []func(), workers int) {
+	queue := make(chan func())
+	var wg sync.WaitGroup
+
+	for i := 0; i < max(1, min(workers, len(jobs))); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+}
+
+func (f *Fetcher) store(keys []fetchKey, points []model.PricePoint, errs []error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, key := range keys {
+		f.results[key] = fetchResult{point: points[i], err: errs[i]}
+	}
+}
+
+// Current возвращает текущую цену монеты, полученную в Fetch
+func (f *Fetcher) Current(coinSymbol string, routes ...string) (model.PricePoint, error) {
+	return f.result(coinSymbol, time.Time{}, routes)
+}
+
+// Historical возвращает цену монеты на момент at, полученную в Fetch
+func (f *Fetcher) Historical(coinSymbol string, at time.Time, routes ...string) (model.PricePoint, error) {
+	return f.result(coinSymbol, at, routes)
+}
+
+func (f *Fetcher) request(coinSymbol string, at time.Time, routes []string) {
+	chain, err := f.router.Chain(routes...)
+	if err != nil {
+		// Ошибка маршрута вернется при получении результата
+		return
+	}
+
+	key := newFetchKey(chain, coinSymbol, at)
+	if f.requested[key] {
+		return
+	}
+	f.requested[key] = true
+	f.pending = append(f.pending, key)
+}
+
+func (f *Fetcher) result(coinSymbol string, at time.Time, routes []string) (model.PricePoint, error) {
+	chain, err := f.router.Chain(routes...)
+	if err != nil {
+		return model.PricePoint{}, err
+	}
+
+	f.mu.Lock()
+	result, ok := f.results[newFetchKey(chain, coinSymbol, at)]
+	f.mu.Unlock()
+	if !ok {
+		return model.PricePoint{}, fmt.Errorf("price of %s was not fetched: request it before Fetch", coinSymbol)
+	}
+
+	return result.point, result.err
+}
+
+func newFetchKey(chain *Chain, coinSymbol string, at time.Time) fetchKey {
+	key := fetchKey{
+		chain: chain,
+		coin:  strings.ToUpper(strings.TrimSpace(coinSymbol)),
+	}
+	if !at.IsZero() {
+		key.at = at.UnixMilli()
+	}
+
+	return key
+}
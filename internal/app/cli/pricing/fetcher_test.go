@@ -0,0 +1,155 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchProvider провайдер с пачечными запросами: знает цены только монет из prices
+type batchProvider struct {
+	fakeProvider
+	prices  map[string]float64
+	batches [][]string // Монеты (или моменты) каждого пачечного запроса
+}
+
+func (b *batchProvider) GetCurrentPrices(_ context.Context, coinSymbols []string) ([]model.PricePoint, []error) {
+	b.batches = append(b.batches, coinSymbols)
+
+	points := make([]model.PricePoint, len(coinSymbols))
+	errs := make([]error, len(coinSymbols))
+	for i, coin := range coinSymbols {
+		price, ok := b.prices[coin]
+		if !ok {
+			errs[i] = fmt.Errorf("%s: %w", coin, webapi.ErrPriceNotFound)
+			continue
+		}
+		points[i] = model.PricePoint{Price: price, Timestamp: time.Now()}
+	}
+
+	return points, errs
+}
+
+func (b *batchProvider) GetHistoricalPrices(_ context.Context, coinSymbol string, ats []time.Time) ([]model.PricePoint, []error) {
+	batch := make([]string, len(ats))
+	points := make([]model.PricePoint, len(ats))
+	errs := make([]error, len(ats))
+	for i, at := range ats {
+		batch[i] = coinSymbol + "@" + at.UTC().Format("15:04")
+		price, ok := b.prices[coinSymbol]
+		if !ok {
+			errs[i] = fmt.Errorf("%s: %w", coinSymbol, webapi.ErrPriceNotFound)
+			continue
+		}
+		points[i] = model.PricePoint{Price: price, Timestamp: at}
+	}
+	b.batches = append(b.batches, batch)
+
+	return points, errs
+}
+
+func TestChain_BatchFallback(t *testing.T) {
+	first := &batchProvider{fakeProvider: fakeProvider{name: "bybit"}, prices: map[string]float64{"BTC": 100}}
+	second := &fakeProvider{name: "coingecko", price: 200}
+
+	points, errs := NewChain(first, second).GetCurrentPrices(context.Background(), []string{"BTC", "XVG"})
+
+	assert.NoError(t, errs[0])
+	assert.Equal(t, model.PricePoint{Price: 100, Timestamp: points[0].Timestamp, Provider: "bybit"}, points[0])
+	// Монета, которую не нашел первый провайдер, запрошена у второго
+	assert.NoError(t, errs[1])
+	assert.Equal(t, 200.0, points[1].Price)
+	assert.Equal(t, "coingecko", points[1].Provider)
+	assert.Equal(t, 1, second.calls)
+}
+
+func TestFetcher_DeduplicatesAndBatches(t *testing.T) {
+	provider := &batchProvider{fakeProvider: fakeProvider{name: "bybit"}, prices: map[string]float64{"BTC": 100, "ETH": 10}}
+	router, err := NewRouter(NewRegistry(provider), map[string][]string{RouteDefault: {"bybit"}})
+	assert.NoError(t, err)
+
+	signal := time.Date(2025, 12, 29, 3, 0, 0, 0, time.UTC)
+	fetcher := router.NewFetcher()
+	for i := 0; i < 20; i++ {
+		fetcher.RequestCurrent("BTC", RouteBybit)
+		fetcher.RequestHistorical("btc", signal.Add(10*time.Minute), "10m", RouteHorizons)
+		fetcher.RequestHistorical("BTC", signal.Add(time.Hour), "1h", RouteHorizons)
+	}
+	fetcher.RequestCurrent("ETH", RouteBybit)
+	fetcher.RequestCurrent("XYZ", RouteBybit)
+
+	fetcher.Fetch(context.Background(), 1)
+
+	assert.Equal(t, [][]string{
+		{"BTC", "ETH", "XYZ"},
+		{"BTC@03:10", "BTC@04:00"},
+	}, provider.batches)
+
+	point, err := fetcher.Current("btc", RouteBybit)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, point.Price)
+
+	point, err = fetcher.Historical("BTC", signal.Add(time.Hour), "1h", RouteHorizons)
+	assert.NoError(t, err)
+	assert.True(t, signal.Add(time.Hour).Equal(point.Timestamp))
+
+	_, err = fetcher.Current("XYZ", RouteBybit)
+	assert.True(t, IsNotAvailable(err))
+
+	// Цена, которую не запросили до Fetch
+	_, err = fetcher.Current("SOL", RouteBybit)
+	assert.Error(t, err)
+}
+
+// barrierProvider отвечает только после того, как запрос пришел во все провайдеры barrier
+type barrierProvider struct {
+	fakeProvider
+	barrier *sync.WaitGroup
+}
+
+func (b *barrierProvider) GetCurrentPrice(ctx context.Context, coinSymbol string) (model.PricePoint, error) {
+	b.barrier.Done()
+	b.barrier.Wait()
+
+	return b.fakeProvider.GetCurrentPrice(ctx, coinSymbol)
+}
+
+func TestFetcher_RunsProvidersInParallel(t *testing.T) {
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+
+	bybit := &barrierProvider{fakeProvider: fakeProvider{name: "bybit", price: 1}, barrier: &barrier}
+	coinGecko := &barrierProvider{fakeProvider: fakeProvider{name: "coingecko", price: 2}, barrier: &barrier}
+	router, err := NewRouter(NewRegistry(bybit, coinGecko), map[string][]string{
+		RouteBybit:    {"bybit"},
+		RouteHorizons: {"coingecko"},
+	})
+	assert.NoError(t, err)
+
+	fetcher := router.NewFetcher()
+	fetcher.RequestCurrent("BTC", RouteBybit)
+	fetcher.RequestCurrent("BTC", RouteHorizons)
+
+	// С одним воркером запросы выполнялись бы по очереди и ждали друг друга бесконечно
+	done := make(chan struct{})
+	go func() {
+		fetcher.Fetch(context.Background(), 2)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("providers were not called in parallel")
+	}
+
+	point, err := fetcher.Current("BTC", RouteHorizons)
+	assert.NoError(t, err)
+	assert.Equal(t, "coingecko", point.Provider)
+}
@@ -0,0 +1,67 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+// IPriceProvider источник цен монет (Bybit, CoinGecko, ...)
+type IPriceProvider interface {
+	Name() string
+	GetCurrentPrice(ctx context.Context, coinSymbol string) (model.PricePoint, error)
+	GetHistoricalPrice(ctx context.Context, coinSymbol string, at time.Time) (model.PricePoint, error)
+}
+
+// IBatchPriceProvider провайдер, который умеет получать несколько цен меньшим числом запросов
+// Результаты и ошибки возвращаются в порядке запрошенных монет (моментов)
+type IBatchPriceProvider interface {
+	IPriceProvider
+	GetCurrentPrices(ctx context.Context, coinSymbols []string) ([]model.PricePoint, []error)
+	GetHistoricalPrices(ctx context.Context, coinSymbol string, ats []time.Time) ([]model.PricePoint, []error)
+}
+
+// Registry реестр провайдеров цен по имени
+type Registry struct {
+	providers map[string]IPriceProvider
+}
+
+func NewRegistry(providers ...IPriceProvider) *Registry {
+	registry := &Registry{
+		providers: make(map[string]IPriceProvider, len(providers)),
+	}
+	for _, provider := range providers {
+		registry.Register(provider)
+	}
+
+	return registry
+}
+
+// Register добавляет провайдер в реестр (провайдер с тем же именем заменяется)
+func (r *Registry) Register(provider IPriceProvider) {
+	r.providers[provider.Name()] = provider
+}
+
+// Get возвращает провайдер по имени
+func (r *Registry) Get(name string) (IPriceProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown price provider %q (available: %v)", name, r.Names())
+	}
+
+	return provider, nil
+}
+
+// Names возвращает отсортированный список имен зарегистрированных провайдеров
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,59 @@
+package pricing
+
+import (
+	"fmt"
+)
+
+// Маршруты цен по умолчанию
+const (
+	// RouteBybit колонка "Цена на Bybit"
+	RouteBybit = "bybit"
+	// RouteHorizons все временные колонки (10m, 1h, ...)
+	RouteHorizons = "horizons"
+	// RouteDefault используется, если для колонки нет своего маршрута
+	RouteDefault = "default"
+)
+
+// Router выбирает цепочку провайдеров для колонки таблицы
+type Router struct {
+	chains map[string]*Chain
+}
+
+// NewRouter строит цепочки провайдеров по маршрутам вида колонка → [провайдер, ...]
+func NewRouter(registry *Registry, routes map[string][]string) (*Router, error) {
+	router := &Router{
+		chains: make(map[string]*Chain, len(routes)),
+	}
+
+	for route, names := range routes {
+		providers := make([]IPriceProvider, 0, len(names))
+		for _, name := range names {
+			provider, err := registry.Get(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid price route %q: %w", route, err)
+			}
+			providers = append(providers, provider)
+		}
+
+		router.chains[route] = NewChain(providers...)
+	}
+
+	return router, nil
+}
+
+// Chain возвращает цепочку для первого из маршрутов, который настроен
+// Например, Chain("10m", RouteHorizons) вернет маршрут конкретной колонки,
+// если он задан, иначе общий маршрут для временных колонок, иначе RouteDefault.
+func (r *Router) Chain(routes ...string) (*Chain, error) {
+	for _, route := range routes {
+		if chain, ok := r.chains[route]; ok {
+			return chain, nil
+		}
+	}
+
+	if chain, ok := r.chains[RouteDefault]; ok {
+		return chain, nil
+	}
+
+	return nil, fmt.Errorf("no price route configured for %v", routes)
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"log"
 
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/adapter/storage"
 	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/coins"
 	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/pricing"
 	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
 	"github.com/drybin/TrackMyCoin/pkg/logger"
+	"github.com/drybin/TrackMyCoin/pkg/ratelimit"
 	"github.com/drybin/TrackMyCoin/pkg/wrap"
 	"github.com/go-resty/resty/v2"
 )
@@ -21,6 +26,14 @@ type Container struct {
 type Usecases struct {
 	HelloWorld *usecase.HelloWorld
 	Process    *usecase.Process
+	Daemon     *usecase.Daemon
+	Bot        *usecase.Bot
+	Report     *usecase.Report
+	Sources    *usecase.Sources
+	Restore    *usecase.Restore
+	Export     *usecase.Export
+	Import     *usecase.Import
+	Coins      *usecase.Coins
 }
 
 func NewContainer(
@@ -51,18 +64,94 @@ func NewContainer(
 		}
 	}
 
+	// Таблица с сигналами по умолчанию - лист Google Sheets из конфига
+	// Без клиента передается nil интерфейс, а не nil указатель
+	var records recordstore.IRecordStore
+	if googleSheets != nil {
+		records = recordstore.NewGoogleSheets(googleSheets, config.GoogleSheetID, config.GoogleSheetRange)
+	}
+
 	// Initialize CoinGecko client
-	coinGecko := webapi.NewCoinGecko(httpClient)
+	coinGecko := webapi.NewCoinGecko(httpClient, config.PriceHistoryTolerance, newRateLimiter(config, webapi.CoinGeckoProviderName))
+
+	// Каталог монет CoinGecko: символ из таблицы → ID монеты
+	coinResolver := coins.NewResolver(coinGecko, config.CoinCatalogFile, config.CoinCatalogTTL, config.CoinMapFile)
+	coinGecko.SetCoinIDResolver(coinResolver)
+
+	// Initialize Bybit client
+	bybit := webapi.NewBybit(httpClient, config.PriceHistoryTolerance, newRateLimiter(config, webapi.BybitProviderName))
+
+	// Провайдеры цен и маршруты колонок → цепочки провайдеров
+	priceRouter, err := pricing.NewRouter(pricing.NewRegistry(bybit, coinGecko), config.PriceRoutes)
+	if err != nil {
+		return nil, wrap.Errorf("failed to configure price routes: %w", err)
+	}
+
+	// Telegram бот: уведомления в чат TG_CHAT_ID и команды из TG_ALLOWED_CHAT_IDS
+	var notifier webapi.ITelegram
+	var telegramBot webapi.ITelegramBot
+	if config.TgConfig.BotToken != "" {
+		telegram := webapi.NewTelegram(httpClient, config.TgConfig.BotToken, config.TgConfig.ChatId, config.TgConfig.Timeout)
+		telegramBot = telegram
+		if config.TgConfig.ChatId != "" {
+			notifier = telegram
+		}
+	}
+
+	// Локальное хранилище записей и истории цен
+	var store storage.IStore
+	if config.StoreFile != "" {
+		sqliteStore, err := storage.NewSQLite(ctx, config.StoreFile)
+		if err != nil {
+			return nil, wrap.Errorf("failed to open store: %w", err)
+		}
+		store = sqliteStore
+	}
+
+	process := usecase.NewProcessUsecase(records, priceRouter, notifier, store, config)
+
+	// Несколько таблиц и листов за один запуск process и daemon
+	if googleSheets != nil && len(config.SheetTargets) > 0 {
+		spreadsheets := make([]recordstore.SpreadsheetTabs, 0, len(config.SheetTargets))
+		for _, target := range config.SheetTargets {
+			spreadsheets = append(spreadsheets, recordstore.SpreadsheetTabs{SpreadsheetID: target.SpreadsheetID, Tabs: target.Tabs})
+		}
+		isStatsSheet := func(title string) bool { return usecase.IsStatsSheet(config.StatsSheet, title) }
+		process.SetTargets(recordstore.NewGoogleTabs(googleSheets, spreadsheets, isStatsSheet))
+	}
 
 	container := Container{
 		Logger: appLogger,
 		Usecases: &Usecases{
 			HelloWorld: usecase.NewHelloWorldUsecase(),
-			Process:    usecase.NewProcessUsecase(googleSheets, coinGecko, config),
+			Process:    process,
+			Daemon:     usecase.NewDaemonUsecase(process, config),
+			Bot:        usecase.NewBotUsecase(records, priceRouter, telegramBot, coinResolver, config),
+			Report:     usecase.NewReportUsecase(records, config),
+			Sources:    usecase.NewSourcesUsecase(records, config),
+			Restore:    usecase.NewRestoreUsecase(records, store, config),
+			Export:     usecase.NewExportUsecase(records, config),
+			Import:     usecase.NewImportUsecase(records, config),
+			Coins:      usecase.NewCoinsUsecase(coinResolver),
 		},
 		Clean: func() {
+			if store != nil {
+				if err := store.Close(); err != nil {
+					log.Printf("Warning: failed to close store: %v", err)
+				}
+			}
 		},
 	}
 
 	return &container, nil
 }
+
+// newRateLimiter создает общий лимитер запросов к провайдеру (nil, если ограничение не задано)
+func newRateLimiter(config *config.Config, provider string) *ratelimit.Limiter {
+	rate, ok := config.RateLimits[provider]
+	if !ok {
+		return nil
+	}
+
+	return ratelimit.NewLimiter(rate, nil)
+}
@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/pricing"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+	"github.com/drybin/TrackMyCoin/pkg/ratelimit"
+)
+
+const (
+	// botPollTimeout сколько getUpdates ждет новых сообщений
+	botPollTimeout = 30 * time.Second
+	// botRetryDelay пауза перед повтором, если Bot API недоступен
+	botRetryDelay = 5 * time.Second
+
+	signalCommand = "/signal"
+	signalUsage   = "Usage: /signal <COIN> <long|short> [price] [source=<name>]"
+)
+
+type IBot interface {
+	Run(ctx context.Context) error
+}
+
+// Bot принимает команды Telegram бота и добавляет сигналы в лист
+type Bot struct {
+	records  recordstore.IRecordStore
+	prices   *pricing.Router
+	telegram webapi.ITelegramBot
+	coins    webapi.ICoinIDResolver
+	config   *config.Config
+	clock    ratelimit.Clock
+}
+
+// signalRequest разобранная команда /signal
+type signalRequest struct {
+	Coin      string
+	Direction string
+	Price     float64 // 0 - цена в источнике не указана
+	Source    string
+}
+
+func NewBotUsecase(
+	records recordstore.IRecordStore,
+	prices *pricing.Router,
+	telegram webapi.ITelegramBot,
+	coins webapi.ICoinIDResolver,
+	config *config.Config,
+) *Bot {
+	return &Bot{
+		records:  records,
+		prices:   prices,
+		telegram: telegram,
+		coins:    coins,
+		config:   config,
+		clock:    ratelimit.SystemClock,
+	}
+}
+
+// Run получает сообщения боту до отмены ctx
+// Команды принимаются только из чатов TG_ALLOWED_CHAT_IDS, сообщения из других чатов игнорируются.
+func (u *Bot) Run(ctx context.Context) error {
+	if u.telegram == nil {
+		return fmt.Errorf("telegram bot is not configured: set TG_BOT_TOKEN")
+	}
+	if u.records == nil {
+		return fmt.Errorf("google Sheets client is not initialized")
+	}
+	if len(u.config.TgConfig.AllowedChatIDs) == 0 {
+		return fmt.Errorf("no chats are allowed to send commands: set TG_ALLOWED_CHAT_IDS or TG_CHAT_ID")
+	}
+
+	log.Printf("Bot started, accepting commands from chats %v\n", u.config.TgConfig.AllowedChatIDs)
+
+	var offset int64
+	for {
+		updates, err := u.telegram.GetUpdates(ctx, offset, botPollTimeout)
+		if ctx.Err() != nil {
+			log.Println("Bot stopped")
+			return nil
+		}
+		if err != nil {
+			log.Printf("Failed to get Telegram updates: %v\n", err)
+			select {
+			case <-ctx.Done():
+				log.Println("Bot stopped")
+				return nil
+			case <-u.clock.After(botRetryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message != nil {
+				u.handleMessage(ctx, *update.Message)
+			}
+		}
+	}
+}
+
+func (u *Bot) handleMessage(ctx context.Context, message webapi.TelegramMessage) {
+	command, args, _ := strings.Cut(strings.TrimSpace(message.Text), " ")
+	// В группах команда приходит в виде /signal@bot_name
+	command, _, _ = strings.Cut(command, "@")
+	if command != signalCommand {
+		return
+	}
+
+	if !slices.Contains(u.config.TgConfig.AllowedChatIDs, message.Chat.ID) {
+		log.Printf("Ignoring %s from chat %d: chat is not allowed\n", command, message.Chat.ID)
+		return
+	}
+
+	request, err := parseSignalRequest(args)
+	if err != nil {
+		u.reply(ctx, message, fmt.Sprintf("❌ %v\n%s", err, signalUsage))
+		return
+	}
+	if request.Source == "" {
+		request.Source = "Telegram"
+		if message.From != nil && message.From.Username != "" {
+			request.Source = "@" + message.From.Username
+		}
+	}
+
+	// Время сигнала - время сообщения, а не момент обработки (бот мог быть недоступен)
+	at := u.clock.Now()
+	if message.Date > 0 {
+		at = time.Unix(message.Date, 0)
+	}
+
+	text, err := u.addSignal(ctx, request, at)
+	if err != nil {
+		log.Printf("Failed to add signal %s %s: %v\n", request.Coin, request.Direction, err)
+		u.reply(ctx, message, fmt.Sprintf("❌ %v", err))
+		return
+	}
+
+	u.reply(ctx, message, text)
+}
+
+// addSignal добавляет строку сигнала в конец листа и возвращает текст ответа
+// Цена на Bybit заполняется текущей ценой - это цена на момент сигнала.
+// Монета принимается, если ее цену вернул маршрут цены на Bybit или ее знает каталог CoinGecko:
+// монета, которой нет в каталоге CoinGecko, может торговаться на Bybit, а недоступность одного
+// из провайдеров не должна блокировать запись сигналов.
+func (u *Bot) addSignal(ctx context.Context, request signalRequest, at time.Time) (string, error) {
+	referenceLine := "Reference price: unavailable"
+	point, priceErr := u.referencePrice(ctx, request.Coin)
+	if priceErr == nil {
+		referenceLine = fmt.Sprintf("Reference price: %s (%s)", formatPrice(point.Price), point.Provider)
+	} else {
+		_, coinErr := u.coins.ResolveCoinID(ctx, request.Coin)
+		switch {
+		case coinErr == nil:
+			// Без цены на Bybit сигнал все равно записывается, process заполнит ее позже
+			log.Printf("Failed to get reference price of %s: %v\n", request.Coin, priceErr)
+		case pricing.IsNotAvailable(priceErr) && errors.Is(coinErr, webapi.ErrPriceNotFound):
+			return "", fmt.Errorf("unknown coin %s", request.Coin)
+		case pricing.IsNotAvailable(priceErr):
+			return "", fmt.Errorf("failed to check coin %s: %w", request.Coin, coinErr)
+		default:
+			return "", fmt.Errorf("failed to check coin %s: %w", request.Coin, priceErr)
+		}
+	}
+
+	_, layout, err := readSheetLayout(ctx, u.records, u.config)
+	if err != nil {
+		return "", err
+	}
+
+	record := layout.NewRecord()
+	record.Source = request.Source
+	// Время записывается в часовом поясе источника (SOURCE_TIMEZONES) или листа
+	record.SetDateTime(at)
+	record.Coin = request.Coin
+	record.Direction = request.Direction
+	record.SourcePrice = request.Price
+	if priceErr == nil {
+		record.BybitPrice = model.NewPriceValue(point.Price)
+	}
+
+	updatedRange, err := u.records.Append(ctx, [][]interface{}{record.ToRow()})
+	if err != nil {
+		return "", fmt.Errorf("failed to append signal: %w", err)
+	}
+
+	written, err := a1.Parse(updatedRange)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse appended range %q: %w", updatedRange, err)
+	}
+
+	log.Printf("Signal added to %s: %s\n", updatedRange, record.String())
+
+	return fmt.Sprintf("✅ Row %d: %s %s (%s, %s)\n%s",
+		written.StartRow, record.Coin, record.Direction, record.Source, record.GetDateTime(), referenceLine), nil
+}
+
+// referencePrice текущая цена монеты по маршруту колонки цены на Bybit
+func (u *Bot) referencePrice(ctx context.Context, coin string) (model.PricePoint, error) {
+	chain, err := u.prices.Chain(pricing.RouteBybit)
+	if err != nil {
+		return model.PricePoint{}, err
+	}
+
+	return chain.GetCurrentPrice(ctx, coin)
+}
+
+func (u *Bot) reply(ctx context.Context, message webapi.TelegramMessage, text string) {
+	if err := u.telegram.Reply(ctx, message, text); err != nil {
+		log.Printf("Failed to reply in chat %d: %v\n", message.Chat.ID, err)
+	}
+}
+
+// parseSignalRequest разбирает аргументы команды: "BTC long 45000 source=ChannelX"
+func parseSignalRequest(args string) (signalRequest, error) {
+	var request signalRequest
+	var positional []string
+
+	for _, token := range strings.Fields(args) {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok {
+			positional = append(positional, token)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "source":
+			request.Source = value
+		case "price":
+			positional = append(positional, value)
+		default:
+			return signalRequest{}, fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	if len(positional) < 2 || len(positional) > 3 {
+		return signalRequest{}, fmt.Errorf("expected coin and direction")
+	}
+
+	request.Coin = strings.ToUpper(positional[0])
+	request.Direction = strings.ToLower(positional[1])
+	if _, ok := model.ParseDirection(request.Direction); !ok {
+		return signalRequest{}, fmt.Errorf("unknown direction %q", positional[1])
+	}
+
+	if len(positional) == 3 {
+		price, err := model.ParseNumber(positional[2])
+		if err != nil {
+			return signalRequest{}, fmt.Errorf("invalid price: %w", err)
+		}
+		if price <= 0 {
+			return signalRequest{}, fmt.Errorf("invalid price %q", positional[2])
+		}
+		request.Price = price
+	}
+
+	return request, nil
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/pricing"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTelegramBot отдает сообщения одной пачкой и отменяет ctx на следующем getUpdates
+type fakeTelegramBot struct {
+	messages []webapi.TelegramMessage
+	cancel   context.CancelFunc
+	offsets  []int64
+	replies  map[int64]string // ID сообщения → ответ
+}
+
+func (f *fakeTelegramBot) GetUpdates(ctx context.Context, offset int64, _ time.Duration) ([]webapi.TelegramUpdate, error) {
+	f.offsets = append(f.offsets, offset)
+	if len(f.offsets) > 1 {
+		f.cancel()
+		return nil, ctx.Err()
+	}
+
+	var updates []webapi.TelegramUpdate
+	for i := range f.messages {
+		updates = append(updates, webapi.TelegramUpdate{UpdateID: int64(100 + i), Message: &f.messages[i]})
+	}
+
+	return updates, nil
+}
+
+func (f *fakeTelegramBot) Reply(_ context.Context, message webapi.TelegramMessage, text string) error {
+	f.replies[message.MessageID] = text
+	return nil
+}
+
+// knownCoins сопоставляет символы из списка, остальные считаются неизвестными
+type knownCoins []string
+
+func (k knownCoins) ResolveCoinID(_ context.Context, coinSymbol string) (string, error) {
+	for _, coin := range k {
+		if coin == coinSymbol {
+			return coin, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", webapi.ErrPriceNotFound, coinSymbol)
+}
+
+// bybitPrices текущие цены монет на Bybit: остальные монеты провайдер не знает, монеты down - сбой сети
+type bybitPrices struct {
+	prices map[string]float64
+	down   []string
+}
+
+func (b bybitPrices) Name() string {
+	return "fixed"
+}
+
+func (b bybitPrices) GetCurrentPrice(_ context.Context, coinSymbol string) (model.PricePoint, error) {
+	if slices.Contains(b.down, coinSymbol) {
+		return model.PricePoint{}, fmt.Errorf("%w: connection refused", webapi.ErrTransport)
+	}
+	price, ok := b.prices[coinSymbol]
+	if !ok {
+		return model.PricePoint{}, fmt.Errorf("%w: %s", webapi.ErrPriceNotFound, coinSymbol)
+	}
+	return model.PricePoint{Price: price, Timestamp: time.Now()}, nil
+}
+
+func (b bybitPrices) GetHistoricalPrice(ctx context.Context, coinSymbol string, _ time.Time) (model.PricePoint, error) {
+	return b.GetCurrentPrice(ctx, coinSymbol)
+}
+
+func TestBot_Signal(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "ETH", "long", "3000"},
+		},
+	}
+
+	router, err := pricing.NewRouter(
+		pricing.NewRegistry(bybitPrices{prices: map[string]float64{"BTC": 45010.5, "SHIB": 0.00001}, down: []string{"XVG", "PEPE"}}),
+		map[string][]string{pricing.RouteDefault: {"fixed"}},
+	)
+	require.NoError(t, err)
+
+	// 29.12.2025 12:30:00 GMT+7
+	sentAt := time.Date(2025, 12, 29, 5, 30, 0, 0, time.UTC).Unix()
+	allowed := webapi.TelegramChat{ID: -100123}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	telegram := &fakeTelegramBot{
+		cancel:  cancel,
+		replies: make(map[int64]string),
+		messages: []webapi.TelegramMessage{
+			{MessageID: 1, Chat: allowed, Date: sentAt, Text: "/signal btc LONG 45000 source=ChannelX"},
+			{MessageID: 2, Chat: webapi.TelegramChat{ID: 42}, Date: sentAt, Text: "/signal BTC long"},
+			{MessageID: 3, Chat: allowed, Date: sentAt, Text: "/signal@track_bot BTC hold"},
+			{MessageID: 4, Chat: allowed, Date: sentAt, Text: "/signal DOGE short"},
+			{MessageID: 5, Chat: allowed, Date: sentAt, Text: "просто сообщение"},
+			{MessageID: 6, Chat: allowed, Date: sentAt, Text: "/signal BTC short",
+				From: &webapi.TelegramUser{Username: "trader"}},
+			// SHIB торгуется на Bybit, но его нет в каталоге CoinGecko
+			{MessageID: 7, Chat: allowed, Date: sentAt, Text: "/signal SHIB long"},
+			// Провайдер цены недоступен: XVG есть в каталоге CoinGecko, PEPE проверить нечем
+			{MessageID: 8, Chat: allowed, Date: sentAt, Text: "/signal XVG long"},
+			{MessageID: 9, Chat: allowed, Date: sentAt, Text: "/signal PEPE long"},
+		},
+	}
+
+	cfg := &config.Config{GoogleSheetID: "test", TgConfig: config.TgConfig{AllowedChatIDs: []int64{-100123}}}
+	bot := NewBotUsecase(sheetStore(sheet), router, telegram, knownCoins{"BTC", "XVG"}, cfg)
+
+	require.NoError(t, bot.Run(ctx))
+	assert.Equal(t, []int64{0, 109}, telegram.offsets)
+
+	// Новые строки добавлены в конец листа в часовом поясе листа, цена на Bybit - текущая
+	require.Len(t, sheet.rows, 6)
+	assert.Equal(t, []interface{}{"29.12.2025", "12:30:00", "ChannelX", "BTC", "long", 45000.0, 45010.5,
+		"", "", "", "", "", "", "", "", "", "", ""}, sheet.rows[2])
+	assert.Equal(t, []interface{}{"29.12.2025", "12:30:00", "@trader", "BTC", "short", "", 45010.5,
+		"", "", "", "", "", "", "", "", "", "", ""}, sheet.rows[3])
+	assert.Equal(t, []interface{}{"29.12.2025", "12:30:00", "Telegram", "XVG", "long", "", "",
+		"", "", "", "", "", "", "", "", "", "", ""}, sheet.rows[5])
+
+	assert.Contains(t, telegram.replies[9], "❌ failed to check coin PEPE: ")
+	assert.Contains(t, telegram.replies[9], "connection refused")
+	delete(telegram.replies, 9)
+
+	assert.Equal(t, map[int64]string{
+		1: "✅ Row 3: BTC long (ChannelX, 29.12.2025 12:30:00)\nReference price: 45010.5 (fixed)",
+		3: "❌ unknown direction \"hold\"\n" + signalUsage,
+		4: "❌ unknown coin DOGE",
+		6: "✅ Row 4: BTC short (@trader, 29.12.2025 12:30:00)\nReference price: 45010.5 (fixed)",
+		7: "✅ Row 5: SHIB long (Telegram, 29.12.2025 12:30:00)\nReference price: 0.00001 (fixed)",
+		8: "✅ Row 6: XVG long (Telegram, 29.12.2025 12:30:00)\nReference price: unavailable",
+	}, telegram.replies)
+}
+
+func TestParseSignalRequest(t *testing.T) {
+	request, err := parseSignalRequest(" eth short price=3000,5 ")
+	require.NoError(t, err)
+	assert.Equal(t, signalRequest{Coin: "ETH", Direction: "short", Price: 3000.5}, request)
+
+	for _, args := range []string{"", "BTC", "BTC long 0", "BTC long x", "BTC long 1 2", "BTC long tp=50000"} {
+		_, err := parseSignalRequest(args)
+		assert.Error(t, err, args)
+	}
+
+	// 45,000 - это 45 или 45000: цену нужно записать без неоднозначной запятой
+	_, err = parseSignalRequest("BTC long 45,000")
+	assert.ErrorContains(t, err, "ambiguous")
+	request, err = parseSignalRequest("BTC long 45000")
+	require.NoError(t, err)
+	assert.Equal(t, 45000.0, request.Price)
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/coins"
+)
+
+type ICoins interface {
+	Resolve(ctx context.Context, symbol string) error
+	ListMappings(ctx context.Context) error
+	AddMapping(ctx context.Context, symbol string, coinID string) error
+	RemoveMapping(ctx context.Context, symbol string) error
+}
+
+// Coins просмотр и ручная настройка сопоставления символов монет с CoinGecko
+type Coins struct {
+	resolver *coins.Resolver
+}
+
+func NewCoinsUsecase(resolver *coins.Resolver) *Coins {
+	return &Coins{
+		resolver: resolver,
+	}
+}
+
+// Resolve показывает, какой монете CoinGecko соответствует символ и почему
+func (u *Coins) Resolve(ctx context.Context, symbol string) error {
+	resolution, err := u.resolver.Resolve(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", symbol, err)
+	}
+
+	log.Printf("%s → %s (%s)\n", resolution.Symbol, resolution.ID, resolution.Source)
+	if len(resolution.Candidates) > 1 {
+		log.Println("Candidates:")
+		for _, candidate := range resolution.Candidates {
+			rank := "-"
+			if candidate.MarketCapRank > 0 {
+				rank = fmt.Sprintf("#%d", candidate.MarketCapRank)
+			}
+			log.Printf("  %-6s %s (%s)\n", rank, candidate.ID, candidate.Name)
+		}
+		log.Printf("To pin another coin: coins map add %s <coingecko-id>\n", resolution.Symbol)
+	}
+
+	return nil
+}
+
+// ListMappings выводит ручные сопоставления
+func (u *Coins) ListMappings(_ context.Context) error {
+	overrides, err := u.resolver.Overrides()
+	if err != nil {
+		return err
+	}
+
+	if len(overrides) == 0 {
+		log.Println("No manual mappings")
+		return nil
+	}
+
+	symbols := make([]string, 0, len(overrides))
+	for symbol := range overrides {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		log.Printf("%s → %s\n", symbol, overrides[symbol])
+	}
+
+	return nil
+}
+
+// AddMapping сохраняет ручное сопоставление символа монете CoinGecko
+func (u *Coins) AddMapping(ctx context.Context, symbol string, coinID string) error {
+	if err := u.resolver.AddOverride(ctx, symbol, coinID); err != nil {
+		return fmt.Errorf("failed to add mapping: %w", err)
+	}
+
+	log.Printf("✅ %s → %s\n", symbol, coinID)
+	return nil
+}
+
+// RemoveMapping удаляет ручное сопоставление символа
+func (u *Coins) RemoveMapping(_ context.Context, symbol string) error {
+	if err := u.resolver.RemoveOverride(symbol); err != nil {
+		return fmt.Errorf("failed to remove mapping: %w", err)
+	}
+
+	log.Printf("✅ Removed mapping for %s\n", symbol)
+	return nil
+}
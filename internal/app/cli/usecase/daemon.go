@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/ratelimit"
+)
+
+// daemonCaptureWindow горизонты, наступившие не раньше чем daemonCaptureWindow назад,
+// daemon заполняет текущей ценой: он просыпается ровно в момент горизонта
+const daemonCaptureWindow = time.Minute
+
+type IDaemon interface {
+	Run(ctx context.Context) error
+}
+
+// Daemon выполняет process по расписанию: просыпается к ближайшему горизонту, цену на который
+// еще нужно получить, и не реже чем раз в DaemonPollInterval перечитывает лист, чтобы найти новые строки
+type Daemon struct {
+	process *Process
+	config  *config.Config
+	clock   ratelimit.Clock
+}
+
+func NewDaemonUsecase(
+	process *Process,
+	config *config.Config,
+) *Daemon {
+	return &Daemon{
+		process: process,
+		config:  config,
+		clock:   process.clock,
+	}
+}
+
+// Run выполняет проходы до отмены ctx
+// Ошибка прохода (например, недоступность Google Sheets) не останавливает daemon:
+// проход повторится при следующем пробуждении. Отмена ctx - штатное завершение.
+func (u *Daemon) Run(ctx context.Context) error {
+	if u.process.records == nil && u.process.targets == nil {
+		return fmt.Errorf("google Sheets client is not initialized")
+	}
+
+	log.Printf("Daemon started, sheet is re-read at least every %s\n", u.config.DaemonPollInterval)
+
+	for {
+		records, err := u.process.run(ctx, ProcessOptions{CaptureWindow: daemonCaptureWindow})
+		if ctx.Err() != nil {
+			log.Println("Daemon stopped")
+			return nil
+		}
+		if err != nil {
+			log.Printf("Daemon pass failed: %v\n", err)
+		}
+
+		now := u.clock.Now()
+		wakeAt := nextWakeUp(records, now, u.config.DaemonPollInterval)
+		log.Printf("Next pass at %s\n", wakeAt.Format(time.RFC3339))
+
+		select {
+		case <-ctx.Done():
+			log.Println("Daemon stopped")
+			return nil
+		case <-u.clock.After(wakeAt.Sub(now)):
+		}
+	}
+}
+
+// nextWakeUp возвращает момент следующего прохода: ближайший горизонт записей,
+// но не позже чем через pollInterval
+func nextWakeUp(records []*model.CoinPriceRecord, now time.Time, pollInterval time.Duration) time.Time {
+	wakeAt := now.Add(pollInterval)
+
+	for _, record := range records {
+		if record.Coin == "" {
+			continue
+		}
+		if due, ok := record.NextDue(now); ok && due.Before(wakeAt) {
+			wakeAt = due
+		}
+	}
+
+	return wakeAt
+}
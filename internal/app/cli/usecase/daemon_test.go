@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/pricing"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock часы, которые идут только при вызове Advance
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	waiting chan time.Time // Момент срабатывания каждого нового таймера
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	c.waiting <- c.now.Add(d)
+
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	var pending []fakeTimer
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- c.now
+	}
+	c.timers = pending
+}
+
+// liveProvider отличает текущую цену (live) от исторической (history)
+type liveProvider struct {
+	live    float64
+	history float64
+}
+
+func (p *liveProvider) Name() string {
+	return "live"
+}
+
+func (p *liveProvider) GetCurrentPrice(_ context.Context, _ string) (model.PricePoint, error) {
+	return model.PricePoint{Price: p.live, Timestamp: time.Now()}, nil
+}
+
+func (p *liveProvider) GetHistoricalPrice(_ context.Context, _ string, at time.Time) (model.PricePoint, error) {
+	return model.PricePoint{Price: p.history, Timestamp: at}, nil
+}
+
+func TestDaemon_WakesUpAtNextHorizon(t *testing.T) {
+	location := time.FixedZone("GMT+7", 7*60*60)
+	clock := &fakeClock{
+		now:     time.Date(2025, 12, 29, 10, 7, 0, 0, location),
+		waiting: make(chan time.Time, 10),
+	}
+
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "45000"},
+		},
+	}
+
+	router, err := pricing.NewRouter(
+		pricing.NewRegistry(&liveProvider{live: 2, history: 3}),
+		map[string][]string{pricing.RouteDefault: {"live"}},
+	)
+	require.NoError(t, err)
+
+	process := NewProcessUsecase(sheetStore(sheet), router, nil, nil, &config.Config{GoogleSheetID: "test", DaemonPollInterval: time.Hour})
+	process.clock = clock
+	daemon := NewDaemonUsecase(process, process.config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- daemon.Run(ctx)
+	}()
+
+	// Первый проход: цена Bybit получена, горизонты еще не наступили
+	wakeAt := <-clock.waiting
+	assert.Equal(t, time.Date(2025, 12, 29, 10, 10, 0, 0, location), wakeAt)
+	assert.Equal(t, 2.0, sheet.rows[1][6])
+	// Ячейки горизонтов, которые еще не наступили, остаются пустыми
+	assert.Len(t, sheet.rows[1], 7)
+
+	// Ровно в 10:10 цена через 10 минут берется текущая, а не историческая
+	clock.Advance(3 * time.Minute)
+	wakeAt = <-clock.waiting
+	assert.Equal(t, time.Date(2025, 12, 29, 10, 30, 0, 0, location), wakeAt)
+	assert.Equal(t, 2.0, sheet.rows[1][7])
+	assert.Len(t, sheet.rows[1], 8)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestDaemon_DoesNotRepeatFailureSummary(t *testing.T) {
+	location := time.FixedZone("GMT+7", 7*60*60)
+	clock := &fakeClock{
+		now:     time.Date(2025, 12, 29, 10, 7, 0, 0, location),
+		waiting: make(chan time.Time, 10),
+	}
+
+	// Все горизонты сигнала уже наступили: каждый проход запрашивает одни и те же ячейки ERR
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.11.2025", "10:00:00", "ChannelA", "BTC", "long", "45000"},
+		},
+	}
+
+	provider := &fixedPriceProvider{err: errors.New("provider is down")}
+	router, err := pricing.NewRouter(
+		pricing.NewRegistry(provider),
+		map[string][]string{pricing.RouteDefault: {"fixed"}},
+	)
+	require.NoError(t, err)
+
+	notifier := &memoryNotifier{}
+	process := NewProcessUsecase(sheetStore(sheet), router, notifier, nil, &config.Config{GoogleSheetID: "test", DaemonPollInterval: time.Hour})
+	process.clock = clock
+	daemon := NewDaemonUsecase(process, process.config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- daemon.Run(ctx)
+	}()
+
+	<-clock.waiting
+	assert.Equal(t, "ERR", sheet.rows[1][6])
+	require.Len(t, notifier.messages, 1)
+	assert.Contains(t, notifier.messages[0], "Filled: 0, failed: 12")
+
+	// Второй проход: те же ячейки снова не получены - сводка не повторяется
+	clock.Advance(time.Hour)
+	<-clock.waiting
+	assert.Len(t, notifier.messages, 1)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestNextWakeUp(t *testing.T) {
+	location := time.FixedZone("GMT+7", 7*60*60)
+	now := time.Date(2025, 12, 29, 10, 15, 0, 0, location)
+	records := []*model.CoinPriceRecord{
+		{Date: "29.12.2025", Time: "10:00:00", Coin: "BTC"},
+		{Date: "29.12.2025", Time: "09:00:00"}, // Пустая монета не планируется
+	}
+
+	// Ближайший горизонт BTC - 30m в 10:30
+	assert.Equal(t, time.Date(2025, 12, 29, 10, 30, 0, 0, location), nextWakeUp(records, now, time.Hour))
+
+	// Лист перечитывается не реже чем раз в pollInterval
+	assert.Equal(t, now.Add(5*time.Minute), nextWakeUp(records, now, 5*time.Minute))
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+type IExport interface {
+	Export(ctx context.Context, options ExportOptions) error
+}
+
+// ExportOptions параметры выгрузки записей
+type ExportOptions struct {
+	// Format формат: OutputCSV, OutputJSON или OutputNDJSON
+	Format string
+	// Returns добавить доходность на каждом горизонте
+	Returns bool
+	// Entry какая цена считается ценой входа для доходности
+	Entry model.EntryBasis
+	// Out куда выводятся записи (по умолчанию os.Stdout)
+	Out io.Writer
+	// Store таблица с сигналами: file:<путь> - локальный CSV или XLSX файл, пусто - Google Sheets из конфига
+	Store string
+}
+
+// Export выгружает распарсенные записи листа в CSV или JSON
+type Export struct {
+	records recordstore.IRecordStore
+	config  *config.Config
+}
+
+func NewExportUsecase(
+	records recordstore.IRecordStore,
+	config *config.Config,
+) *Export {
+	return &Export{
+		records: records,
+		config:  config,
+	}
+}
+
+func (u *Export) Export(ctx context.Context, options ExportOptions) error {
+	if err := validateRecordsFormat(options.Format); err != nil {
+		return err
+	}
+	if options.Entry == "" {
+		options.Entry = model.EntryAuto
+	}
+	if options.Out == nil {
+		options.Out = os.Stdout
+	}
+
+	table, err := openRecordStore(u.records, options.Store)
+	if err != nil {
+		return err
+	}
+	sheet, err := loadSheet(ctx, table, u.config)
+	if err != nil {
+		return err
+	}
+
+	var records []*model.CoinPriceRecord
+	var horizons []model.Horizon
+	if sheet != nil {
+		records = sheet.records
+		horizons = sheetHorizons(sheet.layout)
+	}
+
+	exported := make([]exportedRecord, 0, len(records))
+	for _, record := range records {
+		exported = append(exported, newExportedRecord(record, horizons, options.Returns, options.Entry))
+	}
+
+	switch options.Format {
+	case OutputJSON:
+		encoder := json.NewEncoder(options.Out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(exported)
+	case OutputNDJSON:
+		encoder := json.NewEncoder(options.Out)
+		for _, record := range exported {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		w := csv.NewWriter(options.Out)
+		if err := w.Write(recordColumns(horizons, options.Returns)); err != nil {
+			return err
+		}
+		for _, record := range exported {
+			if err := w.Write(csvRecordRow(record, horizons, options.Returns)); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	}
+}
+
+// validateRecordsFormat проверяет формат export и import
+func validateRecordsFormat(format string) error {
+	switch format {
+	case OutputCSV, OutputJSON, OutputNDJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (use %s, %s or %s)", format, OutputCSV, OutputJSON, OutputNDJSON)
+	}
+}
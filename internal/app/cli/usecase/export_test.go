@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportSheet(t *testing.T) (*memorySheets, *config.Config) {
+	t.Helper()
+
+	horizons, err := model.ParseHorizons("1h=Цена через 1 час;24h=Цена через 24 часа")
+	require.NoError(t, err)
+
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			{"Дата", "Время", "Источник", "Монета", "Направление", "Цена в источнике", "Цена на Bybit", "Цена через 1 час", "Цена через 24 часа"},
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "100", "101", "110", "WAIT"},
+			{"29.12.2025", "11:00:00", "ChannelB", "ETH", "short", "", "200", "190", ""},
+		},
+	}
+
+	return sheet, &config.Config{GoogleSheetID: "test", Horizons: horizons}
+}
+
+func TestExport(t *testing.T) {
+	sheet, cfg := newExportSheet(t)
+	export := NewExportUsecase(sheetStore(sheet), cfg)
+
+	t.Run("CSV с доходностью", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, export.Export(context.Background(), ExportOptions{Format: OutputCSV, Returns: true, Out: &out}))
+
+		assert.Equal(t, "row,date,time,timezone,signal_at,source,coin,direction,source_price,bybit_price,1h,24h,return_1h,return_24h\n"+
+			"2,29.12.2025,10:00:00,,2025-12-29T10:00:00+07:00,ChannelA,BTC,long,100,101,110,WAIT,10,\n"+
+			"3,29.12.2025,11:00:00,,2025-12-29T11:00:00+07:00,ChannelB,ETH,short,,200,190,,5,\n", out.String())
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, export.Export(context.Background(), ExportOptions{Format: OutputNDJSON, Out: &out}))
+
+		assert.Equal(t, `{"row":2,"date":"29.12.2025","time":"10:00:00","signal_at":"2025-12-29T10:00:00+07:00","source":"ChannelA","coin":"BTC","direction":"long","source_price":100,"bybit_price":101,"prices":{"1h":110,"24h":"WAIT"}}`+"\n"+
+			`{"row":3,"date":"29.12.2025","time":"11:00:00","signal_at":"2025-12-29T11:00:00+07:00","source":"ChannelB","coin":"ETH","direction":"short","source_price":null,"bybit_price":200,"prices":{"1h":190,"24h":null}}`+"\n",
+			out.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, export.Export(context.Background(), ExportOptions{Format: OutputJSON, Returns: true, Entry: model.EntrySource, Out: &out}))
+
+		assert.Contains(t, out.String(), `"returns": {`+"\n"+`      "1h": 10`)
+		// У ETH нет цены в источнике - доходности нет
+		assert.Equal(t, 1, bytes.Count(out.Bytes(), []byte(`"returns"`)))
+	})
+
+	t.Run("Неизвестный формат", func(t *testing.T) {
+		assert.Error(t, export.Export(context.Background(), ExportOptions{Format: "xml"}))
+	})
+}
@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+)
+
+// maxImportErrors сколько ошибок проверки выводится, остальные только считаются
+const maxImportErrors = 20
+
+type IImport interface {
+	Import(ctx context.Context, options ImportOptions) error
+}
+
+// ImportOptions параметры загрузки записей
+type ImportOptions struct {
+	// Format формат: OutputCSV, OutputJSON или OutputNDJSON (как у export)
+	Format string
+	// In откуда читаются записи
+	In io.Reader
+	// DryRun только проверить записи, не добавляя их в лист
+	DryRun bool
+}
+
+// Import проверяет записи из CSV или JSON и добавляет их строками в конец листа
+// Записи, которые уже есть в листе (по CoinPriceRecord.Key), пропускаются.
+type Import struct {
+	records recordstore.IRecordStore
+	config  *config.Config
+}
+
+func NewImportUsecase(
+	records recordstore.IRecordStore,
+	config *config.Config,
+) *Import {
+	return &Import{
+		records: records,
+		config:  config,
+	}
+}
+
+// importEntry запись из входных данных и ее место в них для сообщений об ошибках ("line 3", "record 2")
+type importEntry struct {
+	position string
+	record   exportedRecord
+}
+
+func (u *Import) Import(ctx context.Context, options ImportOptions) error {
+	if err := validateRecordsFormat(options.Format); err != nil {
+		return err
+	}
+	if u.records == nil {
+		return fmt.Errorf("google Sheets client is not initialized")
+	}
+
+	entries, err := readImportEntries(options.In, options.Format)
+	if err != nil {
+		return fmt.Errorf("failed to read %s input: %w", options.Format, err)
+	}
+
+	values, layout, err := readSheetLayout(ctx, u.records, u.config)
+	if err != nil {
+		return err
+	}
+
+	// Ключи записей, которые уже есть в листе: повторная загрузка того же файла не дублирует строки
+	existing := make(map[string]bool)
+	for _, row := range values[1:] {
+		if record, err := layout.ParseRow(row); err == nil {
+			existing[record.Key()] = true
+		}
+	}
+
+	var rows [][]interface{}
+	var invalid []string
+	skipped := 0
+	for _, entry := range entries {
+		record, err := entry.record.toRecord(layout)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", entry.position, err))
+			continue
+		}
+		if existing[record.Key()] {
+			skipped++
+			continue
+		}
+		existing[record.Key()] = true
+		rows = append(rows, record.ToRow())
+	}
+
+	// Ни одна строка не добавляется, пока во входных данных есть ошибки
+	if len(invalid) > 0 {
+		shown := invalid[:min(len(invalid), maxImportErrors)]
+		if hidden := len(invalid) - len(shown); hidden > 0 {
+			shown = append(shown, fmt.Sprintf("... and %d more", hidden))
+		}
+		return fmt.Errorf("%d of %d records are invalid, nothing imported:\n%s",
+			len(invalid), len(entries), strings.Join(shown, "\n"))
+	}
+
+	log.Printf("Records: %d, new: %d, already in sheet: %d\n", len(entries), len(rows), skipped)
+	if options.DryRun || len(rows) == 0 {
+		return nil
+	}
+
+	updatedRange, err := u.records.Append(ctx, rows)
+	if err != nil {
+		return fmt.Errorf("failed to append records: %w", err)
+	}
+
+	log.Printf("✅ Imported %d records to %s\n", len(rows), updatedRange)
+	return nil
+}
+
+// readImportEntries читает записи в формате export
+func readImportEntries(in io.Reader, format string) ([]importEntry, error) {
+	switch format {
+	case OutputJSON:
+		var records []exportedRecord
+		if err := json.NewDecoder(in).Decode(&records); err != nil {
+			return nil, err
+		}
+		entries := make([]importEntry, 0, len(records))
+		for i, record := range records {
+			entries = append(entries, importEntry{position: fmt.Sprintf("record %d", i+1), record: record})
+		}
+		return entries, nil
+	case OutputNDJSON:
+		return readNDJSONEntries(in)
+	default:
+		return readCSVEntries(in)
+	}
+}
+
+func readNDJSONEntries(in io.Reader) ([]importEntry, error) {
+	var entries []importEntry
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var record exportedRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, importEntry{position: fmt.Sprintf("line %d", line), record: record})
+	}
+
+	return entries, scanner.Err()
+}
+
+// readCSVEntries читает CSV с заголовком: колонки как у export, порядок любой
+// Колонки row, signal_at и return_* вычисляются и при загрузке не используются.
+func readCSVEntries(in io.Reader) ([]importEntry, error) {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []importEntry
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		record := exportedRecord{Prices: make(map[string]interface{})}
+		for i, column := range header {
+			// Excel добавляет BOM в начало CSV
+			column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+
+			switch column {
+			case "date":
+				record.Date = value
+			case "time":
+				record.Time = value
+			case "timezone":
+				record.Timezone = value
+			case "source":
+				record.Source = value
+			case "coin":
+				record.Coin = value
+			case "direction":
+				record.Direction = value
+			case "source_price":
+				record.SourcePrice = value
+			case "bybit_price":
+				record.BybitPrice = value
+			case "row", "signal_at", "":
+			default:
+				if strings.HasPrefix(column, returnColumnPrefix) {
+					continue
+				}
+				// Остальные колонки - цены горизонтов; неизвестные горизонты отклоняются при проверке
+				if value != "" {
+					record.Prices[column] = value
+				}
+			}
+		}
+		entries = append(entries, importEntry{position: fmt.Sprintf("line %d", line), record: record})
+	}
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	source, cfg := newExportSheet(t)
+
+	var exported bytes.Buffer
+	require.NoError(t, NewExportUsecase(sheetStore(source), cfg).Export(context.Background(), ExportOptions{Format: OutputCSV, Returns: true, Out: &exported}))
+
+	t.Run("Повторная загрузка export в пустой лист", func(t *testing.T) {
+		target := &memorySheets{title: "Лист1", rows: [][]interface{}{source.rows[0]}}
+		importer := NewImportUsecase(sheetStore(target), cfg)
+
+		require.NoError(t, importer.Import(context.Background(), ImportOptions{Format: OutputCSV, In: bytes.NewReader(exported.Bytes())}))
+		assert.Equal(t, [][]interface{}{
+			source.rows[0],
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", 100.0, 101.0, 110.0, "WAIT"},
+			{"29.12.2025", "11:00:00", "ChannelB", "ETH", "short", "", 200.0, 190.0, ""},
+		}, target.rows)
+
+		// Записи уже есть в листе - ничего не добавляется
+		require.NoError(t, importer.Import(context.Background(), ImportOptions{Format: OutputCSV, In: bytes.NewReader(exported.Bytes())}))
+		assert.Len(t, target.rows, 3)
+		assert.Len(t, target.writes, 1)
+	})
+
+	t.Run("JSON с ошибками не загружается", func(t *testing.T) {
+		target := &memorySheets{title: "Лист1", rows: [][]interface{}{source.rows[0]}}
+		input := `[
+			{"date": "30.12.2025", "time": "10:00", "source": "ChannelC", "coin": "sol", "direction": "buy", "source_price": 120, "prices": {"1h": 125}},
+			{"date": "30.12.2025", "time": "10:00", "source": "ChannelC", "coin": "XRP", "direction": "hold"},
+			{"date": "30.12.2025", "time": "10:00", "source": "ChannelC", "coin": "ADA", "direction": "long", "bybit_price": "soon"}
+		]`
+
+		err := NewImportUsecase(sheetStore(target), cfg).Import(context.Background(), ImportOptions{Format: OutputJSON, In: strings.NewReader(input)})
+		require.Error(t, err)
+		assert.Equal(t, "2 of 3 records are invalid, nothing imported:\n"+
+			"record 2: unknown direction \"hold\" (use long or short)\n"+
+			"record 3: bybit_price: invalid price \"soon\"", err.Error())
+		assert.Len(t, target.rows, 1)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		target := &memorySheets{title: "Лист1", rows: [][]interface{}{source.rows[0]}}
+		input := `{"date": "30.12.2025", "time": "10:00", "source": "ChannelC", "coin": "sol", "direction": "buy", "source_price": 120, "prices": {"1h": 125}}
+
+{"date": "30.12.2025", "time": "10:00", "source": "ChannelC", "coin": "ETH", "direction": "short", "prices": {"3d": 1}}`
+
+		importer := NewImportUsecase(sheetStore(target), cfg)
+		err := importer.Import(context.Background(), ImportOptions{Format: OutputNDJSON, In: strings.NewReader(input)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `line 3: unknown horizon "3d"`)
+
+		firstLine, _, _ := strings.Cut(input, "\n")
+		require.NoError(t, importer.Import(context.Background(), ImportOptions{Format: OutputNDJSON, In: strings.NewReader(firstLine), DryRun: true}))
+		assert.Len(t, target.rows, 1)
+
+		require.NoError(t, importer.Import(context.Background(), ImportOptions{Format: OutputNDJSON, In: strings.NewReader(firstLine)}))
+		assert.Equal(t, []interface{}{"30.12.2025", "10:00", "ChannelC", "SOL", "buy", 120.0, "", 125.0, ""}, target.rows[1])
+	})
+
+	t.Run("Часовой пояс записи в листе без колонки пояса", func(t *testing.T) {
+		target := &memorySheets{title: "Лист1", rows: [][]interface{}{source.rows[0]}}
+		input := `{"date": "2025-12-30", "time": "03:00", "timezone": "UTC", "source": "ChannelC", "coin": "BTC", "direction": "long"}`
+
+		require.NoError(t, NewImportUsecase(sheetStore(target), cfg).Import(context.Background(), ImportOptions{Format: OutputNDJSON, In: strings.NewReader(input)}))
+		// Время переведено в пояс листа (GMT+7)
+		assert.Equal(t, []interface{}{"30.12.2025", "10:00:00", "ChannelC", "BTC", "long"}, target.rows[1][:5])
+	})
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+// maxNotifiedParseErrors сколько ошибок парсинга перечисляется в сводке запуска
+const maxNotifiedParseErrors = 20
+
+// runSummary итоги запуска для уведомлений
+type runSummary struct {
+	sheet       string
+	table       string                   // Таблица (Name() хранилища), по ней не повторяются уведомления
+	filled      int                      // Сколько цен заполнено
+	failed      int                      // Сколько запросов цен не удалось
+	failedCells []string                 // Ячейки цен, запрос которых не удался (priceCellKey)
+	changed     int                      // Сколько ячеек изменено
+	parseErrors []string                 // Ошибки парсинга строк ("Row N: ...")
+	completed   []*model.CoinPriceRecord // Сигналы, последний горизонт которых заполнен в этом запуске
+	samples     []model.PriceSample      // Полученные цены с происхождением (для хранилища)
+	planned     []plannedChange          // Изменения ячеек, которые записал бы dry-run
+}
+
+// notifyRun отправляет сводку запуска и сообщения о завершенных сигналах
+// Ошибка отправки не прерывает запуск: таблица уже обновлена.
+func (u *Process) notifyRun(ctx context.Context, summary runSummary) {
+	if u.notifier == nil {
+		return
+	}
+
+	// Сводка отправляется, если заполнены цены, появились новые неудачные ячейки или новые ошибки парсинга:
+	// ячейки ERR запрашиваются каждый проход daemon, и без этого сводка повторялась бы при каждом сбое провайдера
+	parseErrors := strings.Join(summary.parseErrors, "\n")
+	newParseErrors := parseErrors != "" && parseErrors != u.lastParseErrors[summary.table]
+	u.lastParseErrors[summary.table] = parseErrors
+
+	newFailures := false
+	failedCells := make(map[string]bool, len(summary.failedCells))
+	for _, cell := range summary.failedCells {
+		failedCells[cell] = true
+		if !u.lastFailedCells[summary.table][cell] {
+			newFailures = true
+		}
+	}
+	u.lastFailedCells[summary.table] = failedCells
+
+	if summary.filled > 0 || newFailures || newParseErrors {
+		u.sendNotification(ctx, runSummaryMessage(summary))
+	}
+
+	for _, record := range summary.completed {
+		u.sendNotification(ctx, signalCompletedMessage(record))
+	}
+}
+
+func (u *Process) sendNotification(ctx context.Context, text string) {
+	if err := u.notifier.SendMessage(ctx, text); err != nil {
+		log.Printf("Warning: failed to send Telegram notification: %v\n", err)
+	}
+}
+
+// runSummaryMessage текст сводки запуска
+func runSummaryMessage(summary runSummary) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "TrackMyCoin: %s\n", summary.sheet)
+	fmt.Fprintf(&b, "Filled: %d, failed: %d\n", summary.filled, summary.failed)
+
+	if len(summary.parseErrors) > 0 {
+		fmt.Fprintf(&b, "\nParse errors (%d):\n", len(summary.parseErrors))
+		for i, errMsg := range summary.parseErrors {
+			if i == maxNotifiedParseErrors {
+				fmt.Fprintf(&b, "... and %d more\n", len(summary.parseErrors)-maxNotifiedParseErrors)
+				break
+			}
+			fmt.Fprintf(&b, "%s\n", errMsg)
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// signalCompletedMessage текст о завершенном сигнале: цена и доходность с учетом направления на каждом горизонте
+func signalCompletedMessage(record *model.CoinPriceRecord) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "✅ %s %s (%s, %s) - all horizons filled\n",
+		record.Coin, record.Direction, record.Source, record.GetDateTime())
+	if entry, ok := record.EntryPrice(model.EntryAuto); ok {
+		fmt.Fprintf(&b, "Entry: %s\n", formatPrice(entry))
+	}
+
+	for _, field := range record.GetPriceFields() {
+		if !record.HasColumn(field.Name) {
+			continue
+		}
+
+		value := record.Price(field.Name)
+		if !value.HasPrice() {
+			fmt.Fprintf(&b, "%s: %v\n", field.Name, value.Cell())
+			continue
+		}
+
+		if ret, ok := record.Return(field.Name, model.EntryAuto); ok {
+			fmt.Fprintf(&b, "%s: %s (%+.2f%%)\n", field.Name, formatPrice(value.Price), ret)
+		} else {
+			fmt.Fprintf(&b, "%s: %s\n", field.Name, formatPrice(value.Price))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+)
+
+// priceSignificantDigits сколько значащих цифр цены видно в ячейке: 45 000,00, 2,123, 0,004600
+const priceSignificantDigits = 4
+
+// minPriceDecimals и maxPriceDecimals границы числа знаков после запятой в формате цены
+const (
+	minPriceDecimals = 2
+	maxPriceDecimals = 10
+)
+
+// formatPrices задает числовой формат колонок цен (цена в источнике, на Bybit, горизонты) в строках записей
+// Число знаков после запятой подбирается по цене монеты в строке: у монет дешевле цента (XVG, SHIB)
+// формат "#,##0.00" показывал бы 0,00.
+func formatPrices(ctx context.Context, table recordstore.IRecordStore, sheet *sheetData) error {
+	formats := priceNumberFormats(sheet.records, sheet.layout, sheet.dataRange)
+	if len(formats) == 0 {
+		return nil
+	}
+
+	log.Printf("Formatting price columns: %d ranges\n", len(formats))
+	return table.FormatNumbers(ctx, formats)
+}
+
+// priceNumberFormats форматы колонок цен: соседние колонки цен и идущие подряд строки
+// с одинаковым форматом объединяются в один диапазон
+func priceNumberFormats(records []*model.CoinPriceRecord, layout *model.SheetLayout, dataRange a1.Range) []recordstore.NumberFormat {
+	firstCol := max(dataRange.StartCol, 1)
+
+	fields := []string{model.FieldSourcePrice, model.BybitPriceField}
+	for _, horizon := range layout.Horizons() {
+		fields = append(fields, horizon.Key)
+	}
+
+	var columns []int
+	for _, field := range fields {
+		if index, ok := layout.Index(field); ok {
+			columns = append(columns, firstCol+index)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	slices.Sort(columns)
+
+	// Группы соседних колонок: [первая, последняя]
+	var columnRuns [][2]int
+	for _, column := range columns {
+		if n := len(columnRuns); n > 0 && columnRuns[n-1][1] == column-1 {
+			columnRuns[n-1][1] = column
+			continue
+		}
+		columnRuns = append(columnRuns, [2]int{column, column})
+	}
+
+	sorted := slices.Clone(records)
+	slices.SortFunc(sorted, func(a, b *model.CoinPriceRecord) int { return a.RowNumber - b.RowNumber })
+
+	type rowBlock struct {
+		startRow int
+		endRow   int
+		pattern  string
+	}
+	var blocks []rowBlock
+	for _, record := range sorted {
+		price, ok := referencePrice(record)
+		if record.RowNumber == 0 || !ok {
+			continue
+		}
+
+		pattern := pricePattern(priceDecimals(price))
+		if n := len(blocks); n > 0 && blocks[n-1].pattern == pattern && blocks[n-1].endRow == record.RowNumber-1 {
+			blocks[n-1].endRow = record.RowNumber
+			continue
+		}
+		blocks = append(blocks, rowBlock{startRow: record.RowNumber, endRow: record.RowNumber, pattern: pattern})
+	}
+
+	formats := make([]recordstore.NumberFormat, 0, len(blocks)*len(columnRuns))
+	for _, block := range blocks {
+		for _, run := range columnRuns {
+			formats = append(formats, recordstore.NumberFormat{
+				Range:   a1.Rows(dataRange.Sheet, run[0], run[1], block.startRow, block.endRow),
+				Pattern: block.pattern,
+			})
+		}
+	}
+
+	return formats
+}
+
+// referencePrice цена, по величине которой выбирается формат строки:
+// цена в источнике, иначе цена на Bybit, иначе первая известная цена горизонта
+func referencePrice(record *model.CoinPriceRecord) (float64, bool) {
+	if record.SourcePrice != 0 {
+		return record.SourcePrice, true
+	}
+	if record.BybitPrice.HasPrice() {
+		return record.BybitPrice.Price, true
+	}
+	for _, field := range record.GetPriceFields() {
+		if value := record.Price(field.Name); value.HasPrice() {
+			return value.Price, true
+		}
+	}
+
+	return 0, false
+}
+
+// priceDecimals число знаков после запятой, при котором видно priceSignificantDigits значащих цифр цены
+func priceDecimals(price float64) int {
+	price = math.Abs(price)
+	if price == 0 {
+		return minPriceDecimals
+	}
+
+	integerDigits := int(math.Floor(math.Log10(price))) + 1
+	return min(max(priceSignificantDigits-integerDigits, minPriceDecimals), maxPriceDecimals)
+}
+
+// pricePattern формат числа с разделителем групп разрядов и decimals знаками после запятой
+// Разделители в ячейке отображаются по локали таблицы: 45 000,00 в русской, 45,000.00 в английской.
+func pricePattern(decimals int) string {
+	return "#,##0." + strings.Repeat("0", decimals)
+}
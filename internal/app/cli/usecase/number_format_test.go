@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceDecimals(t *testing.T) {
+	decimals := map[float64]int{
+		45000:      2,
+		3000.5:     2,
+		2.1234:     3,
+		0.5:        4,
+		0.0046:     6,
+		0.00001234: 8,
+		1e-12:      10,
+		0:          2,
+	}
+	for price, expected := range decimals {
+		assert.Equal(t, expected, priceDecimals(price), price)
+	}
+
+	assert.Equal(t, "#,##0.00", pricePattern(2))
+	assert.Equal(t, "#,##0.000000", pricePattern(6))
+}
+
+func TestProcess_FormatsPriceColumns(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", 45000.0},
+			{"29.12.2025", "11:00:00", "ChannelB", "ETH", "short", 3000.0},
+			{"29.12.2025", "12:00:00", "ChannelA", "XVG", "long", 0.0046},
+		},
+	}
+
+	process := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5})
+	process.config.FormatNumbers = true
+	require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+
+	// Строки с одинаковым форматом объединены, у XVG шесть знаков после запятой
+	assert.Equal(t, []webapi.NumberFormat{
+		{Range: "Лист1!F2:R3", Pattern: "#,##0.00"},
+		{Range: "Лист1!F4:R4", Pattern: "#,##0.000000"},
+	}, sheet.formats)
+
+	t.Run("Без изменений формат не задается", func(t *testing.T) {
+		sheet.formats = nil
+		require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+		assert.Empty(t, sheet.formats)
+	})
+
+	t.Run("FORMAT_NUMBERS=false", func(t *testing.T) {
+		sheet.rows = append(sheet.rows, []interface{}{"29.12.2025", "13:00:00", "ChannelA", "SOL", "long", 120.0})
+		process.config.FormatNumbers = false
+		require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+		assert.Empty(t, sheet.formats)
+	})
+}
@@ -3,218 +3,378 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"slices"
 	"time"
 
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/adapter/storage"
 	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
 	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/pricing"
 	"github.com/drybin/TrackMyCoin/internal/domain/model"
-	"google.golang.org/api/sheets/v4"
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+	"github.com/drybin/TrackMyCoin/pkg/ratelimit"
 )
 
 type IProcess interface {
-	Process(ctx context.Context) error
+	Process(ctx context.Context, options ProcessOptions) error
+}
+
+// ProcessOptions параметры запуска process
+type ProcessOptions struct {
+	// DryRun заполнить цены, но вместо записи в таблицу вывести запланированные изменения ячеек
+	DryRun bool
+	// Output формат вывода изменений в режиме DryRun: OutputTable или OutputJSON
+	Output string
+	// Out куда выводятся изменения в режиме DryRun (по умолчанию os.Stdout)
+	Out io.Writer
+	// CaptureWindow горизонты, момент которых наступил не раньше чем CaptureWindow назад,
+	// заполняются текущей ценой, а не исторической (daemon просыпается ровно в момент горизонта)
+	CaptureWindow time.Duration
+	// Store таблица с сигналами: file:<путь> - локальный CSV или XLSX файл, пусто - Google Sheets из конфига
+	Store string
 }
 
 type Process struct {
-	googleSheets *webapi.GoogleSheets
-	coinGecko    *webapi.CoinGecko
-	config       *config.Config
+	records  recordstore.IRecordStore
+	targets  recordstore.ITargets
+	prices   *pricing.Router
+	notifier webapi.ITelegram
+	store    storage.IStore
+	config   *config.Config
+	clock    ratelimit.Clock
+
+	// lastParseErrors таблица → ошибки парсинга, о которых уже отправлено уведомление
+	// (daemon не повторяет их каждый проход)
+	lastParseErrors map[string]string
+	// lastFailedCells таблица → ячейки цен, о неудаче которых уже отправлено уведомление
+	// (ячейки ERR запрашиваются снова каждый проход)
+	lastFailedCells map[string]map[string]bool
 }
 
-func NewProcessUsecase(googleSheets *webapi.GoogleSheets, coinGecko *webapi.CoinGecko, config *config.Config) *Process {
+// NewProcessUsecase создает usecase process
+// records - таблица с сигналами по умолчанию (nil, если клиент Google Sheets не настроен),
+// notifier nil - уведомления не отправляются, store nil - записи и цены не сохраняются локально.
+func NewProcessUsecase(
+	records recordstore.IRecordStore,
+	prices *pricing.Router,
+	notifier webapi.ITelegram,
+	store storage.IStore,
+	config *config.Config,
+) *Process {
 	return &Process{
-		googleSheets: googleSheets,
-		coinGecko:    coinGecko,
-		config:       config,
+		records:  records,
+		prices:   prices,
+		notifier: notifier,
+		store:    store,
+		clock:    ratelimit.SystemClock,
+		config:   config,
+
+		lastParseErrors: make(map[string]string),
+		lastFailedCells: make(map[string]map[string]bool),
 	}
 }
 
-func (u *Process) Process(ctx context.Context) error {
-	log.Println("Hello")
-	log.Println("Reading Google Sheets document...")
+// SetTargets задает таблицы, которые process обходит вместо таблицы по умолчанию (SHEET_TARGETS)
+// С --store обрабатывается только указанный файл.
+func (u *Process) SetTargets(targets recordstore.ITargets) {
+	u.targets = targets
+}
 
-	if u.googleSheets == nil {
-		log.Println("Google Sheets client is not initialized. Please set GOOGLE_API_KEY or GOOGLE_SERVICE_ACCOUNT_FILE in .env file")
-		return fmt.Errorf("google Sheets client is not initialized")
-	}
+func (u *Process) Process(ctx context.Context, options ProcessOptions) error {
+	_, err := u.run(ctx, options)
+	return err
+}
 
-	spreadsheetID := u.config.GoogleSheetID
+// run выполняет один проход: читает лист (или все цели из SHEET_TARGETS), заполняет цены и записывает изменения
+// Возвращает распарсенные записи (с учетом заполненных цен), по ним daemon планирует следующий проход
+func (u *Process) run(ctx context.Context, options ProcessOptions) ([]*model.CoinPriceRecord, error) {
+	log.Println("Hello")
+	if err := validateOutput(options.Output); err != nil {
+		return nil, err
+	}
 
-	log.Printf("Spreadsheet ID: %s\n", spreadsheetID)
+	// Цены общие для всех таблиц запуска: та же монета на тот же момент в разных листах запрашивается один раз
+	fetcher := u.prices.NewFetcher()
 
-	// Получаем информацию о таблице, включая названия листов
-	log.Println("Getting spreadsheet info...")
-	spreadsheet, err := u.googleSheets.GetSpreadsheetInfo(ctx, spreadsheetID)
-	if err != nil {
-		log.Printf("Error getting spreadsheet info: %v\n", err)
-		return fmt.Errorf("failed to get spreadsheet info: %w", err)
+	if options.Store != "" || u.targets == nil {
+		table, err := openRecordStore(u.records, options.Store)
+		if err != nil {
+			return nil, err
+		}
+		records, summary, err := u.processTable(ctx, table, fetcher, options, false)
+		if err != nil || !options.DryRun {
+			return records, err
+		}
+		return records, writePlannedChanges(options, summary.planned)
 	}
 
-	log.Printf("Spreadsheet title: %s\n", spreadsheet.Properties.Title)
-	log.Printf("Available sheets: %d\n", len(spreadsheet.Sheets))
+	return u.processTargets(ctx, u.targets.Resolve(ctx), fetcher, options)
+}
 
-	for i, sheet := range spreadsheet.Sheets {
-		log.Printf("  Sheet %d: %s (ID: %d)\n", i+1, sheet.Properties.Title, sheet.Properties.SheetId)
+// processTable обрабатывает одну таблицу: читает лист, заполняет цены и записывает изменения
+// statsPerTab - статистика публикуется в отдельный для листа лист (обход SHEET_TARGETS)
+func (u *Process) processTable(
+	ctx context.Context,
+	table recordstore.IRecordStore,
+	fetcher *pricing.Fetcher,
+	options ProcessOptions,
+	statsPerTab bool,
+) ([]*model.CoinPriceRecord, runSummary, error) {
+	sheet, err := loadSheet(ctx, table, u.config)
+	if err != nil || sheet == nil {
+		return nil, runSummary{}, err
 	}
+	records := sheet.records
+	dataRange := sheet.dataRange
 
-	// Определяем какой лист читать
-	var sheetName string
-	var readRange string
-
-	if u.config.GoogleSheetRange != "" {
-		// Если указан диапазон в конфиге, используем его
-		readRange = u.config.GoogleSheetRange
-	} else if len(spreadsheet.Sheets) > 0 {
-		// Иначе читаем первый лист полностью
-		sheetName = spreadsheet.Sheets[0].Properties.Title
-		readRange = sheetName // Просто имя листа без диапазона читает весь лист
-	} else {
-		return fmt.Errorf("no sheets found in spreadsheet")
+	// Заполняем пустые цены через провайдеров цен
+	summary, err := u.fillMissingPrices(ctx, fetcher, records, options.CaptureWindow)
+	if err != nil {
+		return nil, runSummary{}, fmt.Errorf("failed to fill missing prices: %w", err)
 	}
+	summary.table = table.Name()
+	summary.sheet = dataRange.Sheet
+	summary.parseErrors = sheet.parseErrors
 
-	log.Printf("Reading range: %s\n", readRange)
+	var changes []model.CellChange
+	for _, record := range records {
+		changes = append(changes, record.Changes()...)
+	}
+	summary.changed = len(changes)
 
-	data, err := u.googleSheets.ReadSpreadsheet(ctx, spreadsheetID, readRange)
-	if err != nil {
-		log.Printf("Error reading spreadsheet: %v\n", err)
-		return fmt.Errorf("failed to read spreadsheet: %w", err)
+	if options.DryRun {
+		// Таблица не изменяется: что было бы записано, выводит вызывающий (один раз на весь запуск)
+		log.Printf("\nDry run: %d cells would be updated, the sheet is not modified\n", len(changes))
+		summary.planned = plannedChanges(changes, sheet.header, dataRange)
+		return records, summary, nil
 	}
 
-	if len(data.Values) == 0 {
-		log.Println("No data found in spreadsheet")
-		return nil
+	// Сначала хранилище: полученные цены не теряются, даже если запись в лист не удастся
+	if u.store != nil {
+		if err := u.saveToStore(ctx, records, summary.samples); err != nil {
+			return nil, summary, fmt.Errorf("failed to save to store: %w", err)
+		}
 	}
 
-	log.Printf("Found %d rows\n", len(data.Values))
+	// Записываем обновленные данные обратно в таблицу
+	if err := updateSheet(ctx, table, changes, dataRange); err != nil {
+		return nil, summary, fmt.Errorf("failed to update %s: %w", table.Name(), err)
+	}
 
-	// Первая строка - заголовки
-	if len(data.Values) < 2 {
-		log.Println("No data rows found (only headers)")
-		return nil
+	// Формат чисел только влияет на отображение: ошибка не отменяет записанные цены
+	if u.config.FormatNumbers && len(changes) > 0 {
+		if err := formatPrices(ctx, table, sheet); err != nil {
+			log.Printf("Failed to format price columns: %v\n", err)
+		}
 	}
 
-	log.Println("\nHeaders:")
-	log.Println(data.Values[0])
+	// Статистика вторична: ошибка публикации не отменяет записанные цены
+	if err := u.publishStats(ctx, table, sheet, statsPerTab); err != nil {
+		log.Printf("Failed to publish statistics: %v\n", err)
+	}
 
-	log.Println("\n======================")
-	log.Println("Parsing coin price records:")
-	log.Println("======================")
+	// Уведомления отправляются только о том, что действительно записано в таблицу
+	u.notifyRun(ctx, summary)
 
-	var records []*model.CoinPriceRecord
-	var parseErrors []string
+	log.Println("\n✅ Process completed successfully!")
+	return records, summary, nil
+}
 
-	// Парсим строки начиная со второй (первая - заголовки)
-	for i, row := range data.Values[1:] {
-		rowNum := i + 2 // +2 потому что i начинается с 0 и пропускаем заголовок
+// targetResult итоги обработки одной цели для сводки запуска
+type targetResult struct {
+	name    string
+	summary runSummary
+	err     error
+}
 
-		record, err := model.ParseFromRow(row)
-		if err != nil {
-			errMsg := fmt.Sprintf("Row %d: parse error: %v", rowNum, err)
-			parseErrors = append(parseErrors, errMsg)
-			log.Println(errMsg)
-			continue
+// processTargets обрабатывает цели по очереди, каждую отдельно: недоступная таблица или лист
+// без обязательных колонок не прерывает остальные цели. В конце выводится сводка по целям,
+// а если какие-то цели не обработаны, возвращается ошибка с их числом.
+// В режиме DryRun изменения всех целей выводятся одним списком с названием цели.
+func (u *Process) processTargets(
+	ctx context.Context,
+	targets []recordstore.Target,
+	fetcher *pricing.Fetcher,
+	options ProcessOptions,
+) ([]*model.CoinPriceRecord, error) {
+	var all []*model.CoinPriceRecord
+	var planned []plannedChange
+	results := make([]targetResult, 0, len(targets))
+
+	for _, target := range targets {
+		result := targetResult{name: target.Name, err: target.Err}
+		if result.err == nil {
+			log.Println("\n######################")
+			log.Printf("Target: %s\n", target.Name)
+			log.Println("######################")
+
+			var records []*model.CoinPriceRecord
+			records, result.summary, result.err = u.processTable(ctx, target.Store, fetcher, options, true)
+			all = append(all, records...)
+			for _, change := range result.summary.planned {
+				change.Target = target.Name
+				planned = append(planned, change)
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			// Запуск прерван: остальные цели не обрабатываются
+			return all, err
 		}
 
-		records = append(records, record)
-		log.Printf("Row %d: %s\n", rowNum, record.String())
+		if result.err != nil {
+			log.Printf("❌ Target %s failed: %v\n", target.Name, result.err)
+		}
+		results = append(results, result)
 	}
 
 	log.Println("\n======================")
-	log.Printf("Successfully parsed: %d records\n", len(records))
-	if len(parseErrors) > 0 {
-		log.Printf("Parse errors: %d\n", len(parseErrors))
+	log.Printf("Targets summary (%d):\n", len(results))
+	failed := 0
+	for _, result := range results {
+		log.Println(targetSummaryLine(result))
+		if result.err != nil {
+			failed++
+		}
 	}
 	log.Println("======================")
 
-	// Заполняем пустые цены через CoinGecko API
-	if err := u.fillMissingPrices(ctx, records); err != nil {
-		return fmt.Errorf("failed to fill missing prices: %w", err)
+	if options.DryRun {
+		if err := writePlannedChanges(options, planned); err != nil {
+			return all, err
+		}
 	}
 
-	// Записываем обновленные данные обратно в Google Sheets
-	if err := u.updateGoogleSheets(ctx, spreadsheet, records, readRange); err != nil {
-		return fmt.Errorf("failed to update Google Sheets: %w", err)
+	if failed > 0 {
+		return all, fmt.Errorf("%d of %d targets failed", failed, len(results))
 	}
 
-	log.Println("\n✅ Process completed successfully!")
-	return nil
+	return all, nil
+}
+
+// targetSummaryLine строка сводки по цели
+func targetSummaryLine(result targetResult) string {
+	if result.err != nil {
+		return fmt.Sprintf("  ❌ %s: %v", result.name, result.err)
+	}
+
+	summary := result.summary
+	line := fmt.Sprintf("  ✅ %s: filled %d, failed %d, updated cells %d",
+		result.name, summary.filled, summary.failed, summary.changed)
+	if len(summary.parseErrors) > 0 {
+		line += fmt.Sprintf(", parse errors %d", len(summary.parseErrors))
+	}
+
+	return line
+}
+
+// priceTask цена, которую нужно получить для ячейки записи
+type priceTask struct {
+	recordNum int
+	record    *model.CoinPriceRecord
+	field     string    // Поле записи (BybitPriceField или ключ горизонта)
+	at        time.Time // Момент цены (нулевой - текущая цена)
+	live      bool      // Момент горизонта только что наступил - берем текущую цену
 }
 
-// fillMissingPrices заполняет пустые цены через CoinGecko API
-func (u *Process) fillMissingPrices(ctx context.Context, records []*model.CoinPriceRecord) error {
+// fillMissingPrices заполняет пустые цены через цепочки провайдеров, настроенные для колонок
+// Сначала собираются все нужные пары (монета, момент), затем они запрашиваются пачками
+// без повторов, и результаты раскладываются по записям.
+// Горизонты, наступившие не раньше чем captureWindow назад, заполняются текущей ценой.
+// fetcher общий для всех таблиц запуска: цены, уже полученные для другой таблицы, повторно не запрашиваются.
+func (u *Process) fillMissingPrices(
+	ctx context.Context,
+	fetcher *pricing.Fetcher,
+	records []*model.CoinPriceRecord,
+	captureWindow time.Duration,
+) (runSummary, error) {
 	log.Println("\n======================")
 	log.Println("Checking and filling missing prices...")
 	log.Println("======================")
 
-	now := time.Now()
-	totalMissingPrices := 0
-	totalUpdated := 0
-	var priceErrors []string
+	now := u.clock.Now()
+	tasks := collectPriceTasks(records, now, captureWindow, u.config.MarkNotDue)
 
-	for i, record := range records {
-		if record.Coin == "" {
-			continue
+	for _, task := range tasks {
+		if task.at.IsZero() {
+			fetcher.RequestCurrent(task.record.Coin, pricing.RouteBybit)
+		} else if task.live {
+			fetcher.RequestCurrent(task.record.Coin, task.field, pricing.RouteHorizons)
+		} else {
+			fetcher.RequestHistorical(task.record.Coin, task.at, task.field, pricing.RouteHorizons)
 		}
+	}
 
-		recordNum := i + 1
-		recordMissingCount := 0
-		recordUpdatedCount := 0
+	log.Printf("Fetching %d missing prices...\n", len(tasks))
+	fetcher.Fetch(ctx, u.config.PriceWorkers)
+	if err := ctx.Err(); err != nil {
+		// Запуск прерван: незавершенные запросы не должны попасть в таблицу как ERR
+		return runSummary{}, err
+	}
+	fetchedAt := u.clock.Now()
+
+	totalUpdated := 0
+	var priceErrors []string
+	var failedCells []string
+	var filledRecords []*model.CoinPriceRecord
+	var samples []model.PriceSample
 
-		// Проверяем и заполняем Bybit цену
-		if record.BybitPrice == 0 {
-			recordMissingCount++
-			log.Printf("Record %d (%s): Missing Bybit price, fetching from CoinGecko...\n", recordNum, record.Coin)
+	for _, task := range tasks {
+		record := task.record
 
-			price, err := u.coinGecko.GetCurrentPrice(ctx, record.Coin)
+		if task.at.IsZero() {
+			point, err := fetcher.Current(record.Coin, pricing.RouteBybit)
 			if err != nil {
-				errMsg := fmt.Sprintf("Record %d (%s): failed to get Bybit price: %v", recordNum, record.Coin, err)
+				record.BybitPrice = failedPrice(err)
+				errMsg := fmt.Sprintf("Record %d (%s): failed to get Bybit price: %v", task.recordNum, record.Coin, err)
 				priceErrors = append(priceErrors, errMsg)
+				failedCells = append(failedCells, priceCellKey(record, model.BybitPriceField))
 				log.Printf("  ❌ %s\n", errMsg)
-			} else {
-				record.BybitPrice = price
-				recordUpdatedCount++
-				log.Printf("  ✅ Updated Bybit price: $%.2f\n", price)
-			}
-		}
-
-		// Проверяем и заполняем временные поля
-		priceFields := record.GetPriceFields()
-		for _, field := range priceFields {
-			shouldFetch, err := record.ShouldFetchPrice(field, now)
-			if err != nil {
-				// Не можем распарсить дату/время, пропускаем эту запись
 				continue
 			}
 
-			if shouldFetch {
-				recordMissingCount++
-				log.Printf("Record %d (%s): Missing %s, fetching from CoinGecko...\n", recordNum, record.Coin, field.Name)
-
-				price, err := u.coinGecko.GetCurrentPrice(ctx, record.Coin)
-				if err != nil {
-					errMsg := fmt.Sprintf("Record %d (%s): failed to get %s: %v", recordNum, record.Coin, field.Name, err)
-					priceErrors = append(priceErrors, errMsg)
-					log.Printf("  ❌ %s\n", errMsg)
-				} else {
-					*field.Value = price
-					recordUpdatedCount++
-					log.Printf("  ✅ Updated %s: $%.2f\n", field.Name, price)
-				}
-			}
+			record.BybitPrice = model.NewPriceValue(point.Price)
+			record.RecordSample(model.BybitPriceField, point)
+			samples = append(samples, newPriceSample(record, model.BybitPriceField, point, now, fetchedAt))
+			totalUpdated++
+			log.Printf("Record %d (%s): ✅ Updated Bybit price: $%g via %s\n", task.recordNum, record.Coin, point.Price, point.Provider)
+			continue
 		}
 
-		if recordMissingCount > 0 {
-			log.Printf("Record %d (%s): filled %d/%d missing prices\n", recordNum, record.Coin, recordUpdatedCount, recordMissingCount)
+		// Цена нужна на момент время записи + интервал, а не на текущий момент
+		var point model.PricePoint
+		var err error
+		if task.live {
+			point, err = fetcher.Current(record.Coin, task.field, pricing.RouteHorizons)
+		} else {
+			point, err = fetcher.Historical(record.Coin, task.at, task.field, pricing.RouteHorizons)
+		}
+		if err != nil {
+			record.SetPrice(task.field, failedPrice(err))
+			errMsg := fmt.Sprintf("Record %d (%s): failed to get %s: %v", task.recordNum, record.Coin, task.field, err)
+			priceErrors = append(priceErrors, errMsg)
+			failedCells = append(failedCells, priceCellKey(record, task.field))
+			log.Printf("  ❌ %s\n", errMsg)
+			continue
 		}
 
-		totalMissingPrices += recordMissingCount
-		totalUpdated += recordUpdatedCount
+		record.SetPrice(task.field, model.NewPriceValue(point.Price))
+		record.RecordSample(task.field, point)
+		samples = append(samples, newPriceSample(record, task.field, point, task.at, fetchedAt))
+		totalUpdated++
+		if !slices.Contains(filledRecords, record) {
+			filledRecords = append(filledRecords, record)
+		}
+		log.Printf("Record %d (%s): ✅ Updated %s: $%g via %s (sample at %s)\n",
+			task.recordNum, record.Coin, task.field, point.Price, point.Provider,
+			point.Timestamp.In(task.at.Location()).Format(time.RFC3339))
 	}
 
 	log.Println("\n======================")
 	log.Println("Price filling summary:")
-	log.Printf("Total missing prices found: %d\n", totalMissingPrices)
+	log.Printf("Total missing prices found: %d\n", len(tasks))
 	log.Printf("Successfully updated: %d\n", totalUpdated)
 	if len(priceErrors) > 0 {
 		log.Printf("Failed to update: %d\n", len(priceErrors))
@@ -225,78 +385,175 @@ func (u *Process) fillMissingPrices(ctx context.Context, records []*model.CoinPr
 	}
 	log.Println("======================")
 
-	return nil
+	summary := runSummary{filled: totalUpdated, failed: len(priceErrors), failedCells: failedCells, samples: samples}
+	// Сигнал завершен, если в этом запуске заполнен его последний горизонт
+	for _, record := range filledRecords {
+		if record.IsComplete() {
+			summary.completed = append(summary.completed, record)
+		}
+	}
+
+	return summary, nil
 }
 
-// updateGoogleSheets записывает обновленные данные обратно в Google Sheets
-func (u *Process) updateGoogleSheets(ctx context.Context, spreadsheet *sheets.Spreadsheet, records []*model.CoinPriceRecord, readRange string) error {
-	log.Println("\n======================")
-	log.Println("Updating Google Sheets with new data...")
-	log.Println("======================")
+// priceCellKey ключ ячейки цены: запись и поле (не номер строки - строки могут сдвигаться)
+func priceCellKey(record *model.CoinPriceRecord, field string) string {
+	return record.Key() + "|" + field
+}
 
-	if u.googleSheets == nil {
-		log.Println("⚠️  Google Sheets client is not available. Skipping update.")
-		return nil
+// newPriceSample цена поля записи с происхождением: запрошенный момент, время сэмпла и время получения
+func newPriceSample(record *model.CoinPriceRecord, field string, point model.PricePoint, requestedAt time.Time, fetchedAt time.Time) model.PriceSample {
+	return model.PriceSample{
+		RecordKey:   record.Key(),
+		Field:       field,
+		Coin:        record.Coin,
+		Price:       point.Price,
+		Provider:    point.Provider,
+		RequestedAt: requestedAt,
+		SampledAt:   point.Timestamp,
+		FetchedAt:   fetchedAt,
 	}
+}
 
-	if len(records) == 0 {
-		log.Println("No records to update")
-		return nil
+// saveToStore сохраняет записи листа и полученные в этом запуске цены в локальное хранилище
+func (u *Process) saveToStore(ctx context.Context, records []*model.CoinPriceRecord, samples []model.PriceSample) error {
+	if err := u.store.SaveRecords(ctx, records); err != nil {
+		return err
 	}
-
-	// Конвертируем записи обратно в формат для Google Sheets
-	var values [][]interface{}
-	for _, record := range records {
-		values = append(values, record.ToRow())
+	if err := u.store.SaveSamples(ctx, samples); err != nil {
+		return err
 	}
 
-	// Определяем имя листа из readRange или используем первый лист
-	sheetName := readRange
-	// Если readRange содержит "!", то берем часть до "!"
-	if idx := len(readRange); idx > 0 {
-		for i, ch := range readRange {
-			if ch == '!' {
-				sheetName = readRange[:i]
-				break
+	log.Printf("Saved %d records and %d price samples to store\n", len(records), len(samples))
+	return nil
+}
+
+// collectPriceTasks собирает ячейки, для которых нужно получить цену
+// Горизонты, момент которых еще не наступил, не меняются; с markNotDue пустые ячейки таких горизонтов
+// помечаются как WAIT (по умолчанию нет: это запись во все будущие ячейки листа, а формулы
+// и условное форматирование листа могут проверять ячейку на пустоту).
+func collectPriceTasks(records []*model.CoinPriceRecord, now time.Time, captureWindow time.Duration, markNotDue bool) []priceTask {
+	var tasks []priceTask
+
+	for i, record := range records {
+		if record.Coin == "" {
+			continue
+		}
+
+		recordNum := i + 1
+
+		// Bybit цена - текущая
+		if record.BybitPrice.NeedsFetch() && record.HasColumn(model.BybitPriceField) {
+			tasks = append(tasks, priceTask{recordNum: recordNum, record: record, field: model.BybitPriceField})
+		}
+
+		// Временные поля - историческая цена на момент горизонта
+		for _, field := range record.GetPriceFields() {
+			if !record.HasColumn(field.Name) || !record.Price(field.Name).NeedsFetch() {
+				continue
+			}
+
+			due, err := record.IsDue(field, now)
+			if err != nil {
+				// Не можем распарсить дату/время, пропускаем эту запись
+				continue
+			}
+
+			if !due {
+				// Момент горизонта еще не наступил - цену запросим в следующих запусках
+				if markNotDue {
+					record.SetPrice(field.Name, model.PriceValue{State: model.PriceNotDue})
+				}
+				continue
+			}
+
+			targetTime, err := record.TargetTime(field)
+			if err != nil {
+				continue
 			}
+
+			tasks = append(tasks, priceTask{
+				recordNum: recordNum,
+				record:    record,
+				field:     field.Name,
+				at:        targetTime,
+				live:      now.Sub(targetTime) < captureWindow,
+			})
 		}
 	}
 
-	// Если имя листа пустое или это весь диапазон, используем первый лист
-	if sheetName == "" || sheetName == readRange {
-		if len(spreadsheet.Sheets) > 0 {
-			sheetName = spreadsheet.Sheets[0].Properties.Title
-		}
+	return tasks
+}
+
+// failedPrice возвращает значение ячейки для неудачного запроса цены:
+// N/A, если цену не нашел ни один провайдер, иначе ERR (запрос повторится в следующий запуск)
+func failedPrice(err error) model.PriceValue {
+	if pricing.IsNotAvailable(err) {
+		return model.PriceValue{State: model.PriceNotAvailable}
 	}
 
-	// Формируем диапазон для записи: начинаем со строки 2 (после заголовков)
-	// Строка 2 это первая строка данных, если у нас 10 записей, то последняя строка = 2 + 10 - 1 = 11
-	firstDataRow := 2
-	lastRow := firstDataRow + len(values) - 1
-	writeRange := fmt.Sprintf("%s!A%d:R%d", sheetName, firstDataRow, lastRow)
+	return model.PriceValue{State: model.PriceFetchFailed}
+}
+
+// updateSheet записывает в таблицу только изменившиеся ячейки (в Google Sheets - одним BatchUpdate запросом)
+// Остальные ячейки (в том числе строки, которые не удалось распарсить, и правки,
+// сделанные в таблице во время запуска) не затрагиваются
+func updateSheet(ctx context.Context, records recordstore.IRecordStore, changes []model.CellChange, dataRange a1.Range) error {
+	log.Println("\n======================")
+	log.Printf("Updating %s with new data...\n", records.Name())
+	log.Println("======================")
+
+	if len(changes) == 0 {
+		log.Println("No changed cells to update")
+		return nil
+	}
 
-	log.Printf("Sheet name: %s\n", sheetName)
-	log.Printf("Writing %d records to range: %s (rows %d-%d)\n", len(records), writeRange, firstDataRow, lastRow)
+	log.Printf("Sheet name: %s\n", dataRange.Sheet)
 
-	// Сначала очистим весь диапазон данных (все строки после заголовка), чтобы удалить старые данные
-	// Это важно, если в таблице было больше строк, чем мы пишем сейчас
-	clearRange := fmt.Sprintf("%s!A%d:R", sheetName, firstDataRow)
-	log.Printf("Clearing old data in range: %s\n", clearRange)
-	err := u.googleSheets.ClearSpreadsheet(ctx, u.config.GoogleSheetID, clearRange)
-	if err != nil {
-		log.Printf("⚠️  Warning: failed to clear old data: %v\n", err)
-		// Продолжаем даже если очистка не удалась
+	data := changeValueRanges(changes, dataRange.Sheet, max(dataRange.StartCol, 1))
+	for _, valueRange := range data {
+		log.Printf("Writing range: %s\n", valueRange.Range)
 	}
 
-	// Записываем данные
-	err = u.googleSheets.UpdateSpreadsheet(ctx, u.config.GoogleSheetID, writeRange, values)
+	err := records.Update(ctx, data)
 	if err != nil {
 		return fmt.Errorf("failed to write data: %w", err)
 	}
 
-	log.Println("✅ Successfully updated Google Sheets!")
-	log.Printf("Updated %d rows in spreadsheet\n", len(values))
+	log.Printf("✅ Successfully updated %s!\n", records.Name())
+	log.Printf("Updated %d cells in %d ranges\n", len(changes), len(data))
 	log.Println("======================")
 
 	return nil
 }
+
+// changeValueRanges объединяет изменения соседних ячеек одной строки в диапазоны
+// firstCol - номер колонки листа, с которой начинается строка записи
+func changeValueRanges(changes []model.CellChange, sheet string, firstCol int) []recordstore.ValueRange {
+	var data []recordstore.ValueRange
+
+	for start := 0; start < len(changes); {
+		end := start + 1
+		for end < len(changes) &&
+			changes[end].Row == changes[start].Row &&
+			changes[end].Column == changes[end-1].Column+1 {
+			end++
+		}
+
+		values := make([]interface{}, 0, end-start)
+		for _, change := range changes[start:end] {
+			values = append(values, change.NewValue)
+		}
+
+		startCol := firstCol + changes[start].Column - 1
+		endCol := firstCol + changes[end-1].Column - 1
+		data = append(data, recordstore.ValueRange{
+			Range:  a1.Rows(sheet, startCol, endCol, changes[start].Row, changes[start].Row),
+			Values: [][]interface{}{values},
+		})
+
+		start = end
+	}
+
+	return data
+}
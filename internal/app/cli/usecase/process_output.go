@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+)
+
+// Форматы вывода запланированных изменений
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// plannedChange изменение ячейки в выводе dry-run
+type plannedChange struct {
+	Cell     string      `json:"cell"`   // Ячейка в A1 нотации, например "Лист1!H3"
+	Row      int         `json:"row"`    // Номер строки в листе
+	Column   string      `json:"column"` // Буква колонки
+	Header   string      `json:"header"` // Заголовок колонки
+	Field    string      `json:"field,omitempty"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+	Provider string      `json:"provider,omitempty"`
+	Target   string      `json:"target,omitempty"` // Цель SHEET_TARGETS (только при обходе нескольких целей)
+}
+
+// plannedChanges изменения ячеек листа в виде для вывода dry-run
+func plannedChanges(changes []model.CellChange, header []interface{}, dataRange a1.Range) []plannedChange {
+	firstCol := max(dataRange.StartCol, 1)
+	planned := make([]plannedChange, 0, len(changes))
+	for _, change := range changes {
+		column := a1.ColumnLetter(firstCol + change.Column - 1)
+
+		var headerText string
+		if change.Column <= len(header) {
+			headerText = fmt.Sprintf("%v", header[change.Column-1])
+		}
+
+		planned = append(planned, plannedChange{
+			Cell:     a1.Cell(dataRange.Sheet, firstCol+change.Column-1, change.Row),
+			Row:      change.Row,
+			Column:   column,
+			Header:   headerText,
+			Field:    change.Field,
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+			Provider: change.Provider,
+		})
+	}
+
+	return planned
+}
+
+// writePlannedChanges выводит изменения ячеек, которые были бы записаны в таблицу
+// При обходе нескольких целей изменения всех целей выводятся одним списком (один JSON массив).
+func writePlannedChanges(options ProcessOptions, planned []plannedChange) error {
+	out := options.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if planned == nil {
+		planned = []plannedChange{}
+	}
+
+	if options.Output == OutputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(planned)
+	}
+
+	return writeChangesTable(out, planned)
+}
+
+// validateOutput проверяет формат вывода до запуска, чтобы не запрашивать цены зря
+func validateOutput(output string) error {
+	switch output {
+	case "", OutputTable, OutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (use %s or %s)", output, OutputTable, OutputJSON)
+	}
+}
+
+func writeChangesTable(out io.Writer, planned []plannedChange) error {
+	if len(planned) == 0 {
+		_, err := fmt.Fprintln(out, "No changes")
+		return err
+	}
+
+	// Колонка цели только при обходе нескольких целей
+	withTarget := slices.ContainsFunc(planned, func(change plannedChange) bool { return change.Target != "" })
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if withTarget {
+		fmt.Fprint(w, "TARGET\t")
+	}
+	fmt.Fprintln(w, "ROW\tCOLUMN\tHEADER\tOLD\tNEW\tPROVIDER")
+	for _, change := range planned {
+		if withTarget {
+			fmt.Fprintf(w, "%s\t", change.Target)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%v\t%v\t%s\n",
+			change.Row, change.Column, change.Header, change.OldValue, change.NewValue, change.Provider)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,622 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/adapter/webapi"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/pricing"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/sheets/v4"
+)
+
+// memorySheets таблица в памяти вместо Google Sheets
+type memorySheets struct {
+	title   string
+	rows    [][]interface{}
+	writes  []string                   // Диапазоны, в которые выполнялась запись
+	tabs    map[string][][]interface{} // Остальные листы таблицы
+	formats []webapi.NumberFormat      // Заданные числовые форматы
+}
+
+// sheetStore таблица с сигналами в памяти через адаптер Google Sheets
+func sheetStore(sheet *memorySheets) recordstore.IRecordStore {
+	return recordstore.NewGoogleSheets(sheet, "test", "")
+}
+
+func (m *memorySheets) ReadSpreadsheet(_ context.Context, _ string, _ string) (*sheets.ValueRange, error) {
+	values := make([][]interface{}, len(m.rows))
+	for i, row := range m.rows {
+		values[i] = append([]interface{}{}, row...)
+	}
+
+	return &sheets.ValueRange{
+		Range:  a1.Rows(m.title, 1, 18, 1, len(m.rows)),
+		Values: values,
+	}, nil
+}
+
+func (m *memorySheets) GetSpreadsheetInfo(_ context.Context, _ string) (*sheets.Spreadsheet, error) {
+	return &sheets.Spreadsheet{
+		Properties: &sheets.SpreadsheetProperties{Title: "Test"},
+		Sheets: []*sheets.Sheet{
+			{Properties: &sheets.SheetProperties{Title: m.title}},
+		},
+	}, nil
+}
+
+func (m *memorySheets) UpdateSpreadsheet(_ context.Context, _ string, writeRange string, values [][]interface{}) error {
+	r, err := a1.Parse(writeRange)
+	if err != nil {
+		return err
+	}
+	if r.Sheet != m.title {
+		return fmt.Errorf("unknown sheet %q", r.Sheet)
+	}
+
+	for i, row := range values {
+		for j, value := range row {
+			m.set(r.StartRow+i, r.StartCol+j, value)
+		}
+	}
+	m.writes = append(m.writes, writeRange)
+
+	return nil
+}
+
+func (m *memorySheets) BatchUpdateSpreadsheet(ctx context.Context, spreadsheetID string, data []*sheets.ValueRange) error {
+	for _, valueRange := range data {
+		if err := m.UpdateSpreadsheet(ctx, spreadsheetID, valueRange.Range, valueRange.Values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *memorySheets) ClearSpreadsheet(_ context.Context, _ string, clearRange string) error {
+	r, err := a1.Parse(clearRange)
+	if err != nil {
+		return err
+	}
+
+	for row := r.StartRow; row <= len(m.rows) && (r.EndRow == 0 || row <= r.EndRow); row++ {
+		for col := r.StartCol; col <= len(m.rows[row-1]) && (r.EndCol == 0 || col <= r.EndCol); col++ {
+			m.rows[row-1][col-1] = ""
+		}
+	}
+
+	return nil
+}
+
+func (m *memorySheets) AppendSpreadsheet(_ context.Context, _ string, tableRange string, values [][]interface{}) (string, error) {
+	r, err := a1.Parse(tableRange)
+	if err != nil {
+		return "", err
+	}
+
+	firstCol := max(r.StartCol, 1)
+	firstRow := len(m.rows) + 1
+	for i, row := range values {
+		for j, value := range row {
+			m.set(firstRow+i, firstCol+j, value)
+		}
+	}
+	m.writes = append(m.writes, tableRange)
+
+	return a1.Rows(m.title, firstCol, firstCol+len(values[0])-1, firstRow, firstRow+len(values)-1), nil
+}
+
+func (m *memorySheets) EnsureSheet(_ context.Context, _ string, title string) (bool, error) {
+	if _, ok := m.tabs[title]; ok || title == m.title {
+		return false, nil
+	}
+	if m.tabs == nil {
+		m.tabs = make(map[string][][]interface{})
+	}
+	m.tabs[title] = nil
+
+	return true, nil
+}
+
+func (m *memorySheets) OverwriteSheet(_ context.Context, _ string, title string, values [][]interface{}) error {
+	if _, ok := m.tabs[title]; !ok {
+		return fmt.Errorf("unknown sheet %q", title)
+	}
+	m.tabs[title] = values
+
+	return nil
+}
+
+func (m *memorySheets) FormatNumbers(_ context.Context, _ string, formats []webapi.NumberFormat) error {
+	m.formats = append(m.formats, formats...)
+
+	return nil
+}
+
+func (m *memorySheets) set(row int, col int, value interface{}) {
+	for len(m.rows) < row {
+		m.rows = append(m.rows, []interface{}{})
+	}
+	for len(m.rows[row-1]) < col {
+		m.rows[row-1] = append(m.rows[row-1], "")
+	}
+	m.rows[row-1][col-1] = value
+}
+
+// fixedPriceProvider всегда возвращает одну и ту же цену (или ошибку err)
+type fixedPriceProvider struct {
+	price   float64
+	err     error
+	onFetch func() // Вызывается при каждом запросе цены
+}
+
+func (f *fixedPriceProvider) Name() string {
+	return "fixed"
+}
+
+func (f *fixedPriceProvider) GetCurrentPrice(_ context.Context, _ string) (model.PricePoint, error) {
+	if f.onFetch != nil {
+		f.onFetch()
+	}
+	if f.err != nil {
+		return model.PricePoint{}, f.err
+	}
+	return model.PricePoint{Price: f.price, Timestamp: time.Now()}, nil
+}
+
+func (f *fixedPriceProvider) GetHistoricalPrice(_ context.Context, _ string, at time.Time) (model.PricePoint, error) {
+	if f.onFetch != nil {
+		f.onFetch()
+	}
+	if f.err != nil {
+		return model.PricePoint{}, f.err
+	}
+	return model.PricePoint{Price: f.price, Timestamp: at}, nil
+}
+
+// testHeader заголовки листа в порядке колонок по умолчанию
+var testHeader = []interface{}{
+	"Дата", "Время", "Источник", "Монета", "Направление", "Цена в источнике", "Цена на Bybit",
+	"Цена через 10 минут", "Цена через 30 минут", "Цена через 1 час", "Цена через 2 часа",
+	"Цена через 6 часов", "Цена через 12 часов", "Цена через 24 часов",
+	"Цена через 3 дня", "Цена через 5 дней", "Цена через 7 дней", "Цена через 1 месяц",
+}
+
+func newTestProcess(t *testing.T, sheet *memorySheets, provider *fixedPriceProvider) *Process {
+	t.Helper()
+
+	router, err := pricing.NewRouter(
+		pricing.NewRegistry(provider),
+		map[string][]string{pricing.RouteDefault: {"fixed"}},
+	)
+	require.NoError(t, err)
+
+	return NewProcessUsecase(sheetStore(sheet), router, nil, nil, &config.Config{GoogleSheetID: "test"})
+}
+
+func TestProcess_KeepsUnparsedRowsInPlace(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", 45000.0},
+			{"broken", "row"},
+			{"29.12.2025", "11:00:00", "ChannelB", "ETH", "short", 3000.0},
+			{},
+			{"29.12.2025", "12:00:00", "ChannelA", "SOL", "long", 120.0},
+		},
+	}
+
+	err := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5}).Process(context.Background(), ProcessOptions{})
+	require.NoError(t, err)
+
+	// Строки, которые не удалось распарсить, остались на своих местах без изменений
+	assert.Equal(t, []interface{}{"broken", "row"}, sheet.rows[2])
+	assert.Equal(t, []interface{}{}, sheet.rows[4])
+
+	// Распарсенные записи записаны в свои исходные строки
+	for rowIdx, coin := range map[int]string{1: "BTC", 3: "ETH", 5: "SOL"} {
+		row := sheet.rows[rowIdx]
+		assert.Equal(t, coin, row[3], "row %d", rowIdx+1)
+		assert.Equal(t, 1.5, row[6], "row %d: Bybit price", rowIdx+1)
+		assert.Equal(t, 1.5, row[7], "row %d: 10 min price", rowIdx+1)
+	}
+	assert.Equal(t, 3000.0, sheet.rows[3][5])
+}
+
+func TestProcess_FillsRowWithInvalidSourcePrice(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "45,000"},
+		},
+	}
+
+	notifier := &memoryNotifier{}
+	process := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5})
+	process.notifier = notifier
+	require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+
+	// Цена в источнике осталась как есть, цены Bybit и горизонтов заполнены
+	assert.Equal(t, "45,000", sheet.rows[1][5])
+	assert.Equal(t, 1.5, sheet.rows[1][6])
+	assert.Equal(t, 1.5, sheet.rows[1][7])
+
+	require.NotEmpty(t, notifier.messages)
+	assert.Contains(t, notifier.messages[0], `Row 2: parse error: invalid source price: ambiguous number "45,000"`)
+}
+
+func TestProcess_WritesOnlyChangedCells(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", 45000.0, 45010.0, 45100.0, 45200.0,
+				45300.0, 45400.0, 45500.0, 45600.0, 45700.0, 46000.0, 46500.0, 47000.0},
+			{"29.12.2025", "11:00:00", "ChannelB", "ETH", "short", 3000.0, 3001.0},
+		},
+	}
+
+	provider := &fixedPriceProvider{price: 1.5}
+	provider.onFetch = func() {
+		// Коллега правит таблицу, пока идет запуск
+		sheet.rows[1][2] = "ChannelC"
+		sheet.rows = append(sheet.rows[:3], []interface{}{"30.12.2025", "09:00:00", "ChannelD", "SOL"})
+	}
+
+	err := newTestProcess(t, sheet, provider).Process(context.Background(), ProcessOptions{})
+	require.NoError(t, err)
+
+	// Строка BTC: дописан только месяц, строка ETH: все временные колонки одним диапазоном
+	assert.Equal(t, []string{"Лист1!R2:R2", "Лист1!H3:R3"}, sheet.writes)
+
+	// Правки, сделанные во время запуска, не затерты
+	assert.Equal(t, "ChannelC", sheet.rows[1][2])
+	assert.Equal(t, []interface{}{"30.12.2025", "09:00:00", "ChannelD", "SOL"}, sheet.rows[3])
+
+	assert.Equal(t, 1.5, sheet.rows[1][17])
+	assert.Equal(t, 3001.0, sheet.rows[2][6])
+	assert.Equal(t, 1.5, sheet.rows[2][7])
+}
+
+func TestProcess_FileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.csv")
+	content := strings.Join([]string{
+		"Дата,Время,Источник,Монета,Направление,Цена в источнике,Цена на Bybit,Цена через 10 минут",
+		"29.12.2025,10:00:00,ChannelA,BTC,long,45000,,",
+		"29.12.2025,11:00:00,ChannelB,ETH,short,3000,3001,",
+	}, "\n") + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	sheet := &memorySheets{title: "Лист1", rows: [][]interface{}{testHeader}}
+	err := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5}).Process(context.Background(), ProcessOptions{Store: "file:" + path})
+	require.NoError(t, err)
+
+	// Цены записаны в файл, лист Google Sheets не затронут
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "45000", "1.5", "1.5"}, rows[1])
+	assert.Equal(t, []string{"29.12.2025", "11:00:00", "ChannelB", "ETH", "short", "3000", "3001", "1.5"}, rows[2])
+	assert.Empty(t, sheet.writes)
+
+	err = newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5}).Process(context.Background(), ProcessOptions{Store: "file:signals.ods"})
+	assert.Error(t, err)
+}
+
+// staticTargets цели process, заданные заранее
+type staticTargets []recordstore.Target
+
+func (s staticTargets) Resolve(_ context.Context) []recordstore.Target {
+	return s
+}
+
+func TestProcess_Targets(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, lines ...string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+		return path
+	}
+
+	header := "Дата,Время,Источник,Монета,Направление,Цена в источнике,Цена на Bybit,Цена через 10 минут,Цена через 1 час"
+	january := writeFile("january.csv", header, "29.12.2025,10:00:00,ChannelA,BTC,long,45000,,,")
+	teamB := writeFile("team-b.csv", header, "29.12.2025,10:00:00,ChannelB,BTC,short,45000,,,")
+	broken := writeFile("broken.csv", "Дата,Время,Источник", "29.12.2025,10:00:00,ChannelA")
+
+	requests := 0
+	provider := &fixedPriceProvider{price: 1.5, onFetch: func() { requests++ }}
+	process := newTestProcess(t, &memorySheets{title: "Лист1"}, provider)
+	process.SetTargets(staticTargets{
+		{Name: "Команда A / 2025-12", Store: recordstore.NewCSVFile(january)},
+		{Name: "Команда A / Сломанный", Store: recordstore.NewCSVFile(broken)},
+		{Name: "Команда C", Err: fmt.Errorf("spreadsheet not found")},
+		{Name: "Команда B / 2025-12", Store: recordstore.NewCSVFile(teamB)},
+	})
+
+	records, err := process.run(context.Background(), ProcessOptions{})
+	// Ошибки двух целей не помешали обработать остальные
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 of 4 targets failed")
+	assert.Len(t, records, 2)
+
+	for _, path := range []string{january, teamB} {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.5", "1.5", "1.5"}, rows[1][6:], path)
+	}
+
+	// Цены общие для целей: та же монета на те же моменты запрошена один раз (Bybit и два горизонта)
+	assert.Equal(t, 3, requests)
+
+	t.Run("Сводка по целям", func(t *testing.T) {
+		assert.Equal(t, "  ✅ Команда A / 2025-12: filled 3, failed 0, updated cells 3",
+			targetSummaryLine(targetResult{name: "Команда A / 2025-12", summary: runSummary{filled: 3, changed: 3}}))
+		assert.Equal(t, "  ✅ Лист1: filled 0, failed 1, updated cells 1, parse errors 2",
+			targetSummaryLine(targetResult{name: "Лист1", summary: runSummary{failed: 1, changed: 1, parseErrors: []string{"a", "b"}}}))
+		assert.Equal(t, "  ❌ Команда C: spreadsheet not found",
+			targetSummaryLine(targetResult{name: "Команда C", err: fmt.Errorf("spreadsheet not found")}))
+	})
+
+	t.Run("--store обрабатывает только файл", func(t *testing.T) {
+		requests = 0
+		single := writeFile("single.csv", header, "29.12.2025,11:00:00,ChannelA,ETH,long,3000,,,")
+		require.NoError(t, process.Process(context.Background(), ProcessOptions{Store: "file:" + single}))
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("Dry run выводит один JSON массив на все цели", func(t *testing.T) {
+		march := writeFile("march.csv", header, "29.12.2025,12:00:00,ChannelA,BTC,long,45000,,,")
+		april := writeFile("april.csv", header, "29.12.2025,12:00:00,ChannelB,ETH,long,3000,1,1,")
+		dryRun := newTestProcess(t, &memorySheets{title: "Лист1"}, provider)
+		dryRun.SetTargets(staticTargets{
+			{Name: "Команда A / 2026-03", Store: recordstore.NewCSVFile(march)},
+			{Name: "Команда A / 2026-04", Store: recordstore.NewCSVFile(april)},
+		})
+
+		var out bytes.Buffer
+		_, err := dryRun.run(context.Background(), ProcessOptions{DryRun: true, Output: OutputJSON, Out: &out})
+		require.NoError(t, err)
+
+		var planned []map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &planned))
+		var targets []interface{}
+		for _, change := range planned {
+			targets = append(targets, change["target"])
+		}
+		assert.Equal(t, []interface{}{
+			"Команда A / 2026-03", "Команда A / 2026-03", "Команда A / 2026-03", "Команда A / 2026-04",
+		}, targets)
+	})
+}
+
+func TestProcess_ResolvesColumnsFromHeader(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			{"Coin", "Заметки", "Date", "Time", "Source", "Direction", "Bybit Price", "Ссылка", "Price 1 hour"},
+			{"BTC", "вход по рынку", "29.12.2025", "10:00:00", "ChannelA", "long", "", "https://t.me/x/1", ""},
+		},
+	}
+
+	err := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5}).Process(context.Background(), ProcessOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{
+		"BTC", "вход по рынку", "29.12.2025", "10:00:00", "ChannelA", "long", 1.5, "https://t.me/x/1", 1.5,
+	}, sheet.rows[1])
+	assert.Equal(t, []string{"Лист1!G2:G2", "Лист1!I2:I2"}, sheet.writes)
+}
+
+func TestProcess_FailsOnMissingRequiredColumn(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			{"Дата", "Время", "Источник", "Направление"},
+			{"29.12.2025", "10:00:00", "ChannelA", "long"},
+		},
+	}
+
+	err := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5}).Process(context.Background(), ProcessOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Coin (Монета / Coin)")
+	assert.Empty(t, sheet.writes)
+}
+
+func TestProcess_WritesPriceStates(t *testing.T) {
+	// Запись сделана только что: короткие горизонты еще не наступили
+	now := time.Now().In(time.FixedZone("GMT+7", 7*60*60))
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{now.Format("02.01.2006"), now.Format("15:04:05"), "ChannelA", "XYZ", "long", "1", "", "", "*1.2", "ERR"},
+			{"29.12.2025", "10:00:00", "ChannelB", "XYZ", "short", "1", "N/A", "ERR", "WAIT"},
+		},
+	}
+
+	provider := &fixedPriceProvider{err: fmt.Errorf("coin XYZ: %w", webapi.ErrPriceNotFound)}
+	err := newTestProcess(t, sheet, provider).Process(context.Background(), ProcessOptions{})
+	require.NoError(t, err)
+
+	// Свежая запись: Bybit не знает монету, ненаступившие горизонты и ручная цена не тронуты
+	fresh := sheet.rows[1]
+	assert.Equal(t, "N/A", fresh[6])
+	assert.Equal(t, "", fresh[7])
+	assert.Equal(t, "*1.2", fresh[8])
+	assert.Equal(t, "ERR", fresh[9])
+	assert.Len(t, fresh, 10)
+
+	// Старая запись: N/A не запрашивается повторно, ERR и WAIT запрошены и стали N/A
+	old := sheet.rows[2]
+	assert.Equal(t, "N/A", old[6])
+	assert.Equal(t, "N/A", old[7])
+	assert.Equal(t, "N/A", old[8])
+	assert.False(t, slices.Contains(sheet.writes, "Лист1!G3:G3"))
+
+	// MARK_NOT_DUE: пустые и ERR ячейки ненаступивших горизонтов помечаются WAIT
+	process := newTestProcess(t, sheet, provider)
+	process.config.MarkNotDue = true
+	require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+
+	fresh = sheet.rows[1]
+	assert.Equal(t, "WAIT", fresh[7])
+	assert.Equal(t, "*1.2", fresh[8])
+	assert.Equal(t, "WAIT", fresh[9])
+	assert.Equal(t, "WAIT", fresh[17])
+}
+
+func TestProcess_DeduplicatesPriceRequests(t *testing.T) {
+	sheet := &memorySheets{title: "Лист1", rows: [][]interface{}{testHeader}}
+	for i := 0; i < 20; i++ {
+		sheet.rows = append(sheet.rows, []interface{}{"29.12.2025", "10:00:00", fmt.Sprintf("Channel%d", i), "BTC", "long"})
+	}
+
+	requests := 0
+	provider := &fixedPriceProvider{price: 1.5, onFetch: func() { requests++ }}
+
+	err := newTestProcess(t, sheet, provider).Process(context.Background(), ProcessOptions{})
+	require.NoError(t, err)
+
+	// Одна текущая цена и по одной цене на каждый горизонт вместо 20 × 12 запросов
+	assert.Equal(t, 1+len(model.DefaultHorizons()), requests)
+	for _, row := range sheet.rows[1:] {
+		assert.Equal(t, 1.5, row[6])
+		assert.Equal(t, 1.5, row[17])
+	}
+}
+
+func TestProcess_CanceledRunDoesNotWrite(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long"},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	provider := &fixedPriceProvider{price: 1.5, onFetch: cancel}
+
+	err := newTestProcess(t, sheet, provider).Process(ctx, ProcessOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, sheet.writes)
+}
+
+func TestProcess_DryRun(t *testing.T) {
+	newSheet := func() *memorySheets {
+		return &memorySheets{
+			title: "Лист1",
+			rows: [][]interface{}{
+				testHeader,
+				{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", 45000.0, 45010.0, 45100.0, 45200.0,
+					45300.0, 45400.0, 45500.0, 45600.0, 45700.0, 46000.0, 46500.0, 47000.0},
+			},
+		}
+	}
+
+	t.Run("Таблица", func(t *testing.T) {
+		sheet := newSheet()
+		var out bytes.Buffer
+
+		err := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5}).
+			Process(context.Background(), ProcessOptions{DryRun: true, Output: OutputTable, Out: &out})
+		require.NoError(t, err)
+
+		assert.Empty(t, sheet.writes)
+		assert.Equal(t, "ROW  COLUMN  HEADER              OLD  NEW  PROVIDER\n"+
+			"2    R       Цена через 1 месяц       1.5  fixed\n", out.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		sheet := newSheet()
+		var out bytes.Buffer
+
+		err := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5}).
+			Process(context.Background(), ProcessOptions{DryRun: true, Output: OutputJSON, Out: &out})
+		require.NoError(t, err)
+		assert.Empty(t, sheet.writes)
+
+		var planned []map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &planned))
+		assert.Equal(t, []map[string]interface{}{{
+			"cell":      "Лист1!R2",
+			"row":       float64(2),
+			"column":    "R",
+			"header":    "Цена через 1 месяц",
+			"field":     "1M",
+			"old_value": "",
+			"new_value": 1.5,
+			"provider":  "fixed",
+		}}, planned)
+	})
+
+	t.Run("Неизвестный формат", func(t *testing.T) {
+		sheet := newSheet()
+		err := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5}).
+			Process(context.Background(), ProcessOptions{DryRun: true, Output: "xml"})
+		assert.Error(t, err)
+		assert.Empty(t, sheet.writes)
+	})
+}
+
+// memoryNotifier сохраняет уведомления вместо отправки в Telegram
+type memoryNotifier struct {
+	messages []string
+}
+
+func (m *memoryNotifier) SendMessage(_ context.Context, text string) error {
+	m.messages = append(m.messages, text)
+	return nil
+}
+
+func TestProcess_Notifications(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "short", "100", "100", "101", "102",
+				"103", "104", "105", "106", "107", "108", "109", "N/A"},
+			{"broken", "row"},
+		},
+	}
+
+	notifier := &memoryNotifier{}
+	process := newTestProcess(t, sheet, &fixedPriceProvider{price: 90})
+	process.notifier = notifier
+
+	require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+	require.Len(t, notifier.messages, 2)
+	assert.Equal(t, "TrackMyCoin: Лист1\n"+
+		"Filled: 1, failed: 0\n\n"+
+		"Parse errors (1):\n"+
+		"Row 3: parse error: invalid row: expected at least 5 columns, got 2", notifier.messages[0])
+	assert.Equal(t, "✅ BTC short (ChannelA, 29.12.2025 10:00:00) - all horizons filled\n"+
+		"Entry: 100\n"+
+		"10m: 101 (-1.00%)\n30m: 102 (-2.00%)\n1h: 103 (-3.00%)\n2h: 104 (-4.00%)\n"+
+		"6h: 105 (-5.00%)\n12h: 106 (-6.00%)\n24h: 107 (-7.00%)\n3d: 108 (-8.00%)\n"+
+		"5d: 109 (-9.00%)\n7d: N/A\n1M: 90 (+10.00%)", notifier.messages[1])
+
+	// Повторный запуск: цены не запрашивались, ошибки парсинга те же - уведомлений нет
+	require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+	assert.Len(t, notifier.messages, 2)
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+// OutputNDJSON одна запись JSON на строку
+const OutputNDJSON = "ndjson"
+
+// returnColumnPrefix префикс колонок доходности в CSV (return_1h, ...)
+const returnColumnPrefix = "return_"
+
+// exportedRecord запись в формате export и import
+// Цены - число, служебное состояние ячейки (WAIT, ERR, N/A, *45000) или null для пустой ячейки.
+type exportedRecord struct {
+	Row         int                    `json:"row,omitempty"`
+	Date        string                 `json:"date"`
+	Time        string                 `json:"time"`
+	Timezone    string                 `json:"timezone,omitempty"`
+	SignalAt    string                 `json:"signal_at,omitempty"`
+	Source      string                 `json:"source"`
+	Coin        string                 `json:"coin"`
+	Direction   string                 `json:"direction"`
+	SourcePrice interface{}            `json:"source_price"`
+	BybitPrice  interface{}            `json:"bybit_price"`
+	Prices      map[string]interface{} `json:"prices"`
+	// Returns доходность с учетом направления на горизонтах, где она известна, %
+	Returns map[string]float64 `json:"returns,omitempty"`
+}
+
+// newExportedRecord представление записи для export; returns - добавить доходности от цены входа entry
+func newExportedRecord(record *model.CoinPriceRecord, horizons []model.Horizon, returns bool, entry model.EntryBasis) exportedRecord {
+	exported := exportedRecord{
+		Row:        record.RowNumber,
+		Date:       record.Date,
+		Time:       record.Time,
+		Timezone:   record.Timezone,
+		Source:     record.Source,
+		Coin:       record.Coin,
+		Direction:  record.Direction,
+		BybitPrice: priceCellValue(record.BybitPrice),
+		Prices:     make(map[string]interface{}, len(horizons)),
+	}
+	if signalAt, err := record.TryParseDateTime(); err == nil {
+		exported.SignalAt = signalAt.Format(time.RFC3339)
+	}
+	if record.SourcePrice != 0 {
+		exported.SourcePrice = record.SourcePrice
+	}
+
+	for _, horizon := range horizons {
+		exported.Prices[horizon.Key] = priceCellValue(record.Price(horizon.Key))
+		if !returns {
+			continue
+		}
+		if ret, ok := record.Return(horizon.Key, entry); ok {
+			if exported.Returns == nil {
+				exported.Returns = make(map[string]float64)
+			}
+			exported.Returns[horizon.Key] = ret
+		}
+	}
+
+	return exported
+}
+
+// priceCellValue значение цены для export: nil для пустой ячейки
+func priceCellValue(value model.PriceValue) interface{} {
+	if value.State == model.PriceEmpty {
+		return nil
+	}
+
+	return value.Cell()
+}
+
+// recordColumns колонки CSV: поля записи, цены горизонтов и (если нужны) доходности
+func recordColumns(horizons []model.Horizon, returns bool) []string {
+	columns := []string{"row", "date", "time", "timezone", "signal_at", "source", "coin", "direction", "source_price", "bybit_price"}
+	for _, horizon := range horizons {
+		columns = append(columns, horizon.Key)
+	}
+	if returns {
+		for _, horizon := range horizons {
+			columns = append(columns, returnColumnPrefix+horizon.Key)
+		}
+	}
+
+	return columns
+}
+
+// csvRecordRow строка CSV в порядке recordColumns
+func csvRecordRow(exported exportedRecord, horizons []model.Horizon, returns bool) []string {
+	row := []string{
+		"", exported.Date, exported.Time, exported.Timezone, exported.SignalAt, exported.Source, exported.Coin, exported.Direction,
+		csvValue(exported.SourcePrice), csvValue(exported.BybitPrice),
+	}
+	if exported.Row > 0 {
+		row[0] = strconv.Itoa(exported.Row)
+	}
+	for _, horizon := range horizons {
+		row = append(row, csvValue(exported.Prices[horizon.Key]))
+	}
+	if returns {
+		for _, horizon := range horizons {
+			value := ""
+			if ret, ok := exported.Returns[horizon.Key]; ok {
+				value = strconv.FormatFloat(ret, 'f', -1, 64)
+			}
+			row = append(row, value)
+		}
+	}
+
+	return row
+}
+
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// toRecord проверяет запись из import и собирает из нее запись листа с раскладкой layout
+func (e exportedRecord) toRecord(layout *model.SheetLayout) (*model.CoinPriceRecord, error) {
+	record := layout.NewRecord()
+	record.Date = strings.TrimSpace(e.Date)
+	record.Time = strings.TrimSpace(e.Time)
+	record.Source = strings.TrimSpace(e.Source)
+	record.Coin = strings.ToUpper(strings.TrimSpace(e.Coin))
+	record.Direction = strings.TrimSpace(e.Direction)
+	record.Timezone = strings.TrimSpace(e.Timezone)
+
+	required := []struct{ name, value string }{
+		{"date", record.Date}, {"time", record.Time}, {"source", record.Source}, {"coin", record.Coin}, {"direction", record.Direction},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			return nil, fmt.Errorf("%s is required", field.name)
+		}
+	}
+	signalAt, err := record.TryParseDateTime()
+	if err != nil {
+		return nil, err
+	}
+	// В листе без колонки часового пояса время записывается в поясе источника или листа
+	if !record.HasColumn(model.FieldTimezone) && record.Timezone != "" {
+		record.Timezone = ""
+		record.SetDateTime(signalAt)
+	}
+	if _, ok := model.ParseDirection(record.Direction); !ok {
+		return nil, fmt.Errorf("unknown direction %q (use long or short)", record.Direction)
+	}
+
+	sourcePrice, err := importedPrice(e.SourcePrice)
+	if err != nil {
+		return nil, fmt.Errorf("source_price: %w", err)
+	}
+	if sourcePrice.State != model.PriceEmpty && sourcePrice.State != model.PriceFilled {
+		return nil, fmt.Errorf("source_price: expected a number, got %v", e.SourcePrice)
+	}
+	record.SourcePrice = sourcePrice.Price
+
+	if record.BybitPrice, err = importedPrice(e.BybitPrice); err != nil {
+		return nil, fmt.Errorf("bybit_price: %w", err)
+	}
+
+	keys := make([]string, 0, len(e.Prices))
+	for key := range e.Prices {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		_, ok := layout.Index(key)
+		if !ok || !slices.ContainsFunc(layout.Horizons(), func(h model.Horizon) bool { return h.Key == key }) {
+			return nil, fmt.Errorf("unknown horizon %q: the sheet has no column for it", key)
+		}
+		price, err := importedPrice(e.Prices[key])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		record.SetPrice(key, price)
+	}
+
+	return record, nil
+}
+
+// importedPrice разбирает цену из import: пусто, число или служебное состояние ячейки
+// Произвольный текст не принимается, чтобы опечатка не попала в лист как ручное значение.
+func importedPrice(value interface{}) (model.PriceValue, error) {
+	price := model.ParsePriceValue(value)
+	if price.State == model.PriceManual && price.Text != "" {
+		return model.PriceValue{}, fmt.Errorf("invalid price %q", price.Text)
+	}
+	if price.HasPrice() && price.Price < 0 {
+		return model.PriceValue{}, fmt.Errorf("negative price %g", price.Price)
+	}
+
+	return price, nil
+}
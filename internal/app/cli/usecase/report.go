@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+// Дополнительные форматы вывода отчетов
+const (
+	OutputCSV      = "csv"
+	OutputMarkdown = "markdown"
+)
+
+type IReport interface {
+	Report(ctx context.Context, options ReportOptions) error
+}
+
+// ReportOptions параметры отчета
+type ReportOptions struct {
+	// Entry какая цена считается ценой входа
+	Entry model.EntryBasis
+	// Output формат вывода: OutputTable, OutputCSV или OutputMarkdown
+	Output string
+	// Out куда выводится отчет (по умолчанию os.Stdout)
+	Out io.Writer
+	// Store таблица с сигналами: file:<путь> - локальный CSV или XLSX файл, пусто - Google Sheets из конфига
+	Store string
+}
+
+// Report считает результаты сигналов из листа: доходность с учетом направления на каждом горизонте
+type Report struct {
+	records recordstore.IRecordStore
+	config  *config.Config
+}
+
+func NewReportUsecase(
+	records recordstore.IRecordStore,
+	config *config.Config,
+) *Report {
+	return &Report{
+		records: records,
+		config:  config,
+	}
+}
+
+func (u *Report) Report(ctx context.Context, options ReportOptions) error {
+	switch options.Output {
+	case "", OutputTable, OutputCSV, OutputMarkdown:
+	default:
+		return fmt.Errorf("unknown output format %q (use %s, %s or %s)", options.Output, OutputTable, OutputCSV, OutputMarkdown)
+	}
+	if options.Entry == "" {
+		options.Entry = model.EntryAuto
+	}
+	if options.Out == nil {
+		options.Out = os.Stdout
+	}
+
+	table, err := openRecordStore(u.records, options.Store)
+	if err != nil {
+		return err
+	}
+	sheet, err := loadSheet(ctx, table, u.config)
+	if err != nil {
+		return err
+	}
+
+	var records []*model.CoinPriceRecord
+	var horizons []model.Horizon
+	if sheet != nil {
+		records = sheet.records
+		horizons = sheetHorizons(sheet.layout)
+	}
+
+	stats := model.ComputeHorizonStats(records, horizons, options.Entry)
+
+	switch options.Output {
+	case OutputCSV:
+		return writeReportCSV(options.Out, stats)
+	case OutputMarkdown:
+		return writeReportMarkdown(options.Out, stats, len(records), options.Entry)
+	default:
+		return writeReportTable(options.Out, stats, len(records), options.Entry)
+	}
+}
+
+// sheetHorizons горизонты, колонки которых есть в листе
+func sheetHorizons(layout *model.SheetLayout) []model.Horizon {
+	var horizons []model.Horizon
+	for _, horizon := range layout.Horizons() {
+		if _, ok := layout.Index(horizon.Key); ok {
+			horizons = append(horizons, horizon)
+		}
+	}
+
+	return horizons
+}
+
+var reportHeader = []string{"HORIZON", "SIGNALS", "WINS", "WIN RATE %", "MEAN %", "MEDIAN %"}
+
+// reportRow строка отчета; для горизонта без сигналов проценты пустые
+func reportRow(stats model.HorizonStats) []string {
+	row := []string{stats.Horizon.Key, strconv.Itoa(stats.Count), strconv.Itoa(stats.Wins), "", "", ""}
+	if stats.Count > 0 {
+		row[3] = strconv.FormatFloat(stats.WinRate, 'f', 2, 64)
+		row[4] = strconv.FormatFloat(stats.Mean, 'f', 2, 64)
+		row[5] = strconv.FormatFloat(stats.Median, 'f', 2, 64)
+	}
+
+	return row
+}
+
+func writeReportTable(out io.Writer, stats []model.HorizonStats, records int, entry model.EntryBasis) error {
+	fmt.Fprintf(out, "Signals: %d, entry price: %s\n\n", records, entry)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(reportHeader, "\t"))
+	for _, horizonStats := range stats {
+		fmt.Fprintln(w, strings.Join(reportRow(horizonStats), "\t"))
+	}
+
+	return w.Flush()
+}
+
+func writeReportCSV(out io.Writer, stats []model.HorizonStats) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"horizon", "signals", "wins", "win_rate", "mean", "median"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, horizonStats := range stats {
+		if err := w.Write(reportRow(horizonStats)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func writeReportMarkdown(out io.Writer, stats []model.HorizonStats, records int, entry model.EntryBasis) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Signals: %d, entry price: %s\n\n", records, entry)
+	b.WriteString("| Horizon | Signals | Wins | Win rate % | Mean % | Median % |\n")
+	b.WriteString("|---|---:|---:|---:|---:|---:|\n")
+	for _, horizonStats := range stats {
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(reportRow(horizonStats), " | "))
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
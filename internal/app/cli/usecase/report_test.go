@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	horizons, err := model.ParseHorizons("1h=Цена через 1 час;24h=Цена через 24 часа")
+	require.NoError(t, err)
+
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			{"Дата", "Время", "Источник", "Монета", "Направление", "Цена в источнике", "Цена на Bybit", "Цена через 1 час", "Цена через 24 часа"},
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "100", "101", "110", "120"},
+			{"29.12.2025", "11:00:00", "ChannelA", "ETH", "short", "100", "99", "95", "N/A"},
+			{"29.12.2025", "12:00:00", "ChannelB", "SOL", "long", "", "100", "98", "WAIT"},
+			{"29.12.2025", "13:00:00", "ChannelB", "XRP", "short", "100", "", "101", ""},
+		},
+	}
+	report := NewReportUsecase(sheetStore(sheet), &config.Config{GoogleSheetID: "test", Horizons: horizons})
+
+	t.Run("Таблица", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, report.Report(context.Background(), ReportOptions{Out: &out}))
+
+		assert.Equal(t, "Signals: 4, entry price: auto\n\n"+
+			"HORIZON  SIGNALS  WINS  WIN RATE %  MEAN %  MEDIAN %\n"+
+			"1h       4        2     50.00       3.00    2.00\n"+
+			"24h      1        1     100.00      20.00   20.00\n", out.String())
+	})
+
+	t.Run("CSV от цены на Bybit", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, report.Report(context.Background(), ReportOptions{Entry: model.EntryBybit, Output: OutputCSV, Out: &out}))
+
+		// XRP без цены на Bybit не учитывается; BTC +8.91%, ETH +4.04%, SOL -2%
+		assert.Equal(t, "horizon,signals,wins,win_rate,mean,median\n"+
+			"1h,3,2,66.67,3.65,4.04\n"+
+			"24h,1,1,100.00,18.81,18.81\n", out.String())
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, report.Report(context.Background(), ReportOptions{Entry: model.EntrySource, Output: OutputMarkdown, Out: &out}))
+
+		assert.Equal(t, "Signals: 4, entry price: source\n\n"+
+			"| Horizon | Signals | Wins | Win rate % | Mean % | Median % |\n"+
+			"|---|---:|---:|---:|---:|---:|\n"+
+			"| 1h | 3 | 2 | 66.67 | 4.67 | 5.00 |\n"+
+			"| 24h | 1 | 1 | 100.00 | 20.00 | 20.00 |\n", out.String())
+	})
+
+	t.Run("Неизвестный формат", func(t *testing.T) {
+		assert.Error(t, report.Report(context.Background(), ReportOptions{Output: "xml"}))
+	})
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/adapter/storage"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+type IRestore interface {
+	Restore(ctx context.Context, options RestoreOptions) error
+}
+
+// RestoreOptions параметры восстановления листа из хранилища
+type RestoreOptions struct {
+	// Sheet лист, в который записываются записи хранилища (создается, если его нет)
+	Sheet string
+	// Force разрешает перезаписать лист с сигналами
+	Force bool
+}
+
+// Restore заново строит лист с сигналами из локального хранилища
+type Restore struct {
+	records recordstore.IRecordStore
+	store   storage.IStore
+	config  *config.Config
+}
+
+func NewRestoreUsecase(
+	records recordstore.IRecordStore,
+	store storage.IStore,
+	config *config.Config,
+) *Restore {
+	return &Restore{
+		records: records,
+		store:   store,
+		config:  config,
+	}
+}
+
+func (u *Restore) Restore(ctx context.Context, options RestoreOptions) error {
+	if u.store == nil {
+		return fmt.Errorf("store is disabled: set STORE_FILE")
+	}
+	if u.records == nil {
+		return fmt.Errorf("google Sheets client is not initialized")
+	}
+	if options.Sheet == "" {
+		return fmt.Errorf("target sheet is not set")
+	}
+
+	table, err := u.records.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read data sheet: %w", err)
+	}
+	if options.Sheet == table.Range.Sheet && !options.Force {
+		return fmt.Errorf("sheet %q is the data sheet, use --force to overwrite it", options.Sheet)
+	}
+
+	header, layout := u.restoreLayout(table)
+
+	stored, err := u.store.Records(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read store: %w", err)
+	}
+
+	// Восстанавливаются записи листа с сигналами; записи без листа сохранены до появления SheetID
+	dataSheet := sheetID(u.records, table.Range.Sheet)
+	var records []*model.CoinPriceRecord
+	for _, record := range stored {
+		if record.SheetID == dataSheet || record.SheetID == "" {
+			records = append(records, record)
+		}
+	}
+
+	values := [][]interface{}{header}
+	for _, record := range records {
+		record.SetLayout(layout)
+		values = append(values, record.ToRow())
+	}
+
+	if _, err := u.records.WriteSheet(ctx, options.Sheet, values); err != nil {
+		return fmt.Errorf("failed to write sheet %q: %w", options.Sheet, err)
+	}
+
+	log.Printf("✅ Restored %d records from store to sheet %q\n", len(records), options.Sheet)
+	return nil
+}
+
+// restoreLayout раскладка восстановленного листа: как у листа с сигналами,
+// а если его заголовок не прочитать - колонки по умолчанию с горизонтами из конфига
+func (u *Restore) restoreLayout(table *recordstore.Table) ([]interface{}, *model.SheetLayout) {
+	if len(table.Values) > 0 {
+		layout, err := model.NewSheetLayoutFromHeader(table.Values[0], u.config.ColumnAliases, u.config.Horizons)
+		if err == nil {
+			layout.SetTimezones(u.config.Timezones)
+			return table.Values[0], layout
+		}
+		log.Printf("Data sheet header is not usable, default columns are used: %v\n", err)
+	}
+
+	horizons := u.config.Horizons
+	if len(horizons) == 0 {
+		horizons = model.DefaultHorizons()
+	}
+	layout := model.NewSheetLayout(horizons)
+	layout.SetTimezones(u.config.Timezones)
+
+	return layout.Header(), layout
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/storage"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcess_StoreAndRestore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := storage.NewSQLite(ctx, filepath.Join(t.TempDir(), "store.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelB", "ETH", "short", "3000"},
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "45000"},
+		},
+	}
+
+	now := time.Date(2025, 12, 29, 10, 15, 0, 0, model.DefaultSheetTimezone)
+	process := newTestProcess(t, sheet, &fixedPriceProvider{price: 1.5})
+	process.store = store
+	process.clock = &fakeClock{now: now}
+
+	require.NoError(t, process.Process(ctx, ProcessOptions{}))
+
+	// Ключ записи включает таблицу и лист
+	btcKey := "test!Лист1|29.12.2025|10:00:00|ChannelA|BTC|long"
+	samples, err := store.Samples(ctx, btcKey)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	for _, sample := range samples {
+		assert.Equal(t, "fixed", sample.Provider)
+		assert.Equal(t, 1.5, sample.Price)
+		assert.True(t, now.Equal(sample.FetchedAt), sample.Field)
+	}
+	byField := map[string]model.PriceSample{samples[0].Field: samples[0], samples[1].Field: samples[1]}
+	assert.True(t, now.Equal(byField[model.BybitPriceField].RequestedAt))
+	assert.True(t, now.Add(-5*time.Minute).Equal(byField["10m"].RequestedAt))
+	assert.True(t, now.Add(-5*time.Minute).Equal(byField["10m"].SampledAt))
+
+	// Строку удалили из листа - в хранилище она осталась
+	sheet.rows = append(sheet.rows[:1], sheet.rows[2:]...)
+	require.NoError(t, process.Process(ctx, ProcessOptions{}))
+
+	// Записи других листов в восстановленный лист не попадают
+	require.NoError(t, store.SaveRecords(ctx, []*model.CoinPriceRecord{{
+		Date: "29.12.2025", Time: "10:00:00", Source: "ChannelA", Coin: "SOL", Direction: "long", SheetID: "test!2025-11",
+	}}))
+
+	restore := NewRestoreUsecase(sheetStore(sheet), store, process.config)
+	assert.Error(t, restore.Restore(ctx, RestoreOptions{Sheet: "Лист1"}))
+	require.NoError(t, restore.Restore(ctx, RestoreOptions{Sheet: "Restored"}))
+
+	// Горизонты, которые еще не наступили, пустые
+	waiting := []interface{}{"", "", "", "", "", "", "", "", "", ""}
+	assert.Equal(t, [][]interface{}{
+		testHeader,
+		append([]interface{}{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", 45000.0, 1.5, 1.5}, waiting...),
+		append([]interface{}{"29.12.2025", "10:00:00", "ChannelB", "ETH", "short", 3000.0, 1.5, 1.5}, waiting...),
+	}, sheet.tabs["Restored"])
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+)
+
+// sheetData лист с сигналами, прочитанный из таблицы
+type sheetData struct {
+	header      []interface{} // Строка заголовков
+	dataRange   a1.Range      // Фактический диапазон данных (с именем листа и первой строкой)
+	layout      *model.SheetLayout
+	records     []*model.CoinPriceRecord
+	parseErrors []string // Строки, которые не удалось распарсить ("Row N: ...")
+}
+
+// openRecordStore выбирает таблицу с сигналами по значению --store:
+// file:<путь> - локальный CSV или XLSX файл, пусто - таблица по умолчанию (Google Sheets из конфига)
+func openRecordStore(defaultStore recordstore.IRecordStore, spec string) (recordstore.IRecordStore, error) {
+	if spec == "" {
+		return defaultStore, nil
+	}
+
+	return recordstore.OpenFile(spec)
+}
+
+// loadSheet читает лист с сигналами и парсит строки в записи
+// Возвращает nil без ошибки, если в листе нет строк с данными.
+func loadSheet(ctx context.Context, store recordstore.IRecordStore, config *config.Config) (*sheetData, error) {
+	if store == nil {
+		log.Println("Google Sheets client is not initialized. Please set GOOGLE_API_KEY or GOOGLE_SERVICE_ACCOUNT_FILE in .env file")
+		return nil, fmt.Errorf("google Sheets client is not initialized")
+	}
+
+	log.Printf("Reading %s...\n", store.Name())
+
+	data, err := store.Read(ctx)
+	if err != nil {
+		log.Printf("Error reading spreadsheet: %v\n", err)
+		return nil, fmt.Errorf("failed to read spreadsheet: %w", err)
+	}
+
+	if len(data.Values) == 0 {
+		log.Println("No data found in spreadsheet")
+		return nil, nil
+	}
+
+	log.Printf("Found %d rows\n", len(data.Values))
+
+	// Первая строка - заголовки
+	if len(data.Values) < 2 {
+		log.Println("No data rows found (only headers)")
+		return nil, nil
+	}
+
+	log.Println("\nHeaders:")
+	log.Println(data.Values[0])
+
+	// Колонки определяются по строке заголовков, а не по фиксированным позициям
+	layout, err := model.NewSheetLayoutFromHeader(data.Values[0], config.ColumnAliases, config.Horizons)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sheet header: %w", err)
+	}
+	layout.SetTimezones(config.Timezones)
+	if missing := layout.MissingFields(); len(missing) > 0 {
+		log.Printf("Columns not found in header, they will not be filled: %v\n", missing)
+	}
+
+	log.Println("\n======================")
+	log.Println("Parsing coin price records:")
+	log.Println("======================")
+
+	dataRange := data.Range
+	headerRow := max(dataRange.StartRow, 1)
+
+	var records []*model.CoinPriceRecord
+	var parseErrors []string
+
+	// Парсим строки начиная со второй (первая - заголовки)
+	for i, row := range data.Values[1:] {
+		rowNum := headerRow + i + 1 // Номер строки в листе: строка заголовка + смещение
+
+		record, err := layout.ParseRow(row)
+		if err != nil {
+			errMsg := fmt.Sprintf("Row %d: parse error: %v", rowNum, err)
+			parseErrors = append(parseErrors, errMsg)
+			log.Println(errMsg)
+			continue
+		}
+
+		// Цены строки заполняются и при нераспознанной цене в источнике, но ошибка показывается
+		if record.SourcePriceErr != nil {
+			errMsg := fmt.Sprintf("Row %d: parse error: %v", rowNum, record.SourcePriceErr)
+			parseErrors = append(parseErrors, errMsg)
+			log.Println(errMsg)
+		}
+
+		// Запоминаем строку, чтобы записать запись ровно туда, откуда она прочитана
+		record.RowNumber = rowNum
+		record.SheetID = sheetID(store, dataRange.Sheet)
+		records = append(records, record)
+		log.Printf("Row %d: %s\n", rowNum, record.String())
+	}
+
+	log.Println("\n======================")
+	log.Printf("Successfully parsed: %d records\n", len(records))
+	if len(parseErrors) > 0 {
+		log.Printf("Parse errors: %d\n", len(parseErrors))
+	}
+	log.Println("======================")
+
+	return &sheetData{
+		header:      data.Values[0],
+		dataRange:   dataRange,
+		layout:      layout,
+		records:     records,
+		parseErrors: parseErrors,
+	}, nil
+}
+
+// sheetID идентификатор листа sheet таблицы store для ключей записей (CoinPriceRecord.SheetID)
+func sheetID(store recordstore.IRecordStore, sheet string) string {
+	return store.ID() + "!" + sheet
+}
+
+// readSheetLayout читает лист с сигналами и раскладку колонок по строке заголовков
+// В отличие от loadSheet, лист с одним заголовком не считается пустым: в него можно добавлять строки.
+func readSheetLayout(ctx context.Context, store recordstore.IRecordStore, config *config.Config) ([][]interface{}, *model.SheetLayout, error) {
+	data, err := store.Read(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read spreadsheet: %w", err)
+	}
+	if len(data.Values) == 0 {
+		return nil, nil, fmt.Errorf("sheet %s has no header row", data.Range.Sheet)
+	}
+
+	// Строка собирается по заголовку листа, как и при чтении в process
+	layout, err := model.NewSheetLayoutFromHeader(data.Values[0], config.ColumnAliases, config.Horizons)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid sheet header: %w", err)
+	}
+	layout.SetTimezones(config.Timezones)
+
+	return data.Values, layout, nil
+}
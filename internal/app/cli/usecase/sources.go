@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+type ISources interface {
+	Sources(ctx context.Context, options SourcesOptions) error
+}
+
+// SourcesOptions параметры рейтинга источников
+type SourcesOptions struct {
+	// Entry какая цена считается ценой входа
+	Entry model.EntryBasis
+	// Output формат вывода: OutputTable, OutputCSV или OutputMarkdown
+	Output string
+	// Out куда выводится рейтинг (по умолчанию os.Stdout)
+	Out io.Writer
+	// MinSignals источники с меньшим числом сигналов не показываются
+	MinSignals int
+	// From, To учитываются только сигналы с датой в этом интервале (включительно, нулевое значение - без границы)
+	// Берется только календарная дата: день отсчитывается в часовом поясе таблицы.
+	From time.Time
+	To   time.Time
+	// Sort метрика сортировки (model.SortByMean по умолчанию)
+	Sort string
+}
+
+// Sources строит рейтинг источников сигналов по результатам на всех горизонтах
+type Sources struct {
+	records recordstore.IRecordStore
+	config  *config.Config
+}
+
+func NewSourcesUsecase(
+	records recordstore.IRecordStore,
+	config *config.Config,
+) *Sources {
+	return &Sources{
+		records: records,
+		config:  config,
+	}
+}
+
+func (u *Sources) Sources(ctx context.Context, options SourcesOptions) error {
+	switch options.Output {
+	case "", OutputTable, OutputCSV, OutputMarkdown:
+	default:
+		return fmt.Errorf("unknown output format %q (use %s, %s or %s)", options.Output, OutputTable, OutputCSV, OutputMarkdown)
+	}
+	if options.Entry == "" {
+		options.Entry = model.EntryAuto
+	}
+	if options.Sort == "" {
+		options.Sort = model.SortByMean
+	}
+	if options.Out == nil {
+		options.Out = os.Stdout
+	}
+	if !options.From.IsZero() && !options.To.IsZero() && options.To.Before(options.From) {
+		return fmt.Errorf("invalid date range: %s is before %s",
+			options.To.Format(model.SheetDateFormat), options.From.Format(model.SheetDateFormat))
+	}
+
+	sheet, err := loadSheet(ctx, u.records, u.config)
+	if err != nil {
+		return err
+	}
+
+	var records []*model.CoinPriceRecord
+	var horizons []model.Horizon
+	if sheet != nil {
+		records = filterByDate(sheet.records, options.From, options.To, sheet.layout.Timezones().Sheet())
+		horizons = sheetHorizons(sheet.layout)
+	}
+
+	stats := model.ComputeSourceStats(records, horizons, options.Entry, options.MinSignals)
+	if err := model.SortSourceStats(stats, options.Sort); err != nil {
+		return err
+	}
+
+	switch options.Output {
+	case OutputCSV:
+		return writeSourcesCSV(options.Out, stats)
+	case OutputMarkdown:
+		return writeSourcesMarkdown(options.Out, stats, len(records), options.Entry)
+	default:
+		return writeSourcesTable(options.Out, stats, len(records), options.Entry)
+	}
+}
+
+// filterByDate оставляет записи с датой сигнала в интервале [from, to]
+// to - день целиком; записи с нераспознанной датой при заданном интервале отбрасываются.
+func filterByDate(records []*model.CoinPriceRecord, from time.Time, to time.Time, location *time.Location) []*model.CoinPriceRecord {
+	if from.IsZero() && to.IsZero() {
+		return records
+	}
+
+	from, to = sheetDay(from, location), sheetDay(to, location)
+
+	var filtered []*model.CoinPriceRecord
+	for _, record := range records {
+		signalTime, err := record.TryParseDateTime()
+		if err != nil {
+			continue
+		}
+		if !from.IsZero() && signalTime.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !signalTime.Before(to.AddDate(0, 0, 1)) {
+			continue
+		}
+		filtered = append(filtered, record)
+	}
+
+	return filtered
+}
+
+// sheetDay начало того же календарного дня в часовом поясе таблицы (нулевое значение не меняется)
+func sheetDay(day time.Time, location *time.Location) time.Time {
+	if day.IsZero() {
+		return day
+	}
+
+	y, m, d := day.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, location)
+}
+
+var sourcesHeader = []string{"SOURCE", "SIGNALS", "RETURNS", "HIT RATE %", "MEAN %", "MEDIAN %", "BEST", "WORST"}
+
+// sourcesRow строка рейтинга; для источника без доходностей метрики пустые
+func sourcesRow(stats model.SourceStats) []string {
+	row := []string{stats.Source, strconv.Itoa(stats.Signals), strconv.Itoa(stats.Returns), "", "", "", "", ""}
+	if stats.Returns > 0 {
+		row[3] = strconv.FormatFloat(stats.HitRate, 'f', 2, 64)
+		row[4] = strconv.FormatFloat(stats.Mean, 'f', 2, 64)
+		row[5] = strconv.FormatFloat(stats.Median, 'f', 2, 64)
+	}
+	if stats.Best != nil {
+		row[6] = fmt.Sprintf("%s (%+.2f%%)", stats.Best.Horizon.Key, stats.Best.Mean)
+		row[7] = fmt.Sprintf("%s (%+.2f%%)", stats.Worst.Horizon.Key, stats.Worst.Mean)
+	}
+
+	return row
+}
+
+func writeSourcesTable(out io.Writer, stats []model.SourceStats, records int, entry model.EntryBasis) error {
+	fmt.Fprintf(out, "Signals: %d, sources: %d, entry price: %s\n\n", records, len(stats), entry)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(sourcesHeader, "\t"))
+	for _, sourceStats := range stats {
+		fmt.Fprintln(w, strings.Join(sourcesRow(sourceStats), "\t"))
+	}
+
+	return w.Flush()
+}
+
+func writeSourcesCSV(out io.Writer, stats []model.SourceStats) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"source", "signals", "returns", "hit_rate", "mean", "median", "best", "worst"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, sourceStats := range stats {
+		if err := w.Write(sourcesRow(sourceStats)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func writeSourcesMarkdown(out io.Writer, stats []model.SourceStats, records int, entry model.EntryBasis) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Signals: %d, sources: %d, entry price: %s\n\n", records, len(stats), entry)
+	b.WriteString("| Source | Signals | Returns | Hit rate % | Mean % | Median % | Best | Worst |\n")
+	b.WriteString("|---|---:|---:|---:|---:|---:|---|---|\n")
+	for _, sourceStats := range stats {
+		row := sourcesRow(sourceStats)
+		row[0] = strings.ReplaceAll(row[0], "|", `\|`)
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(row, " | "))
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
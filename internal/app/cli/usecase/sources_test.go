@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/config"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources(t *testing.T) {
+	horizons, err := model.ParseHorizons("1h=Цена через 1 час;24h=Цена через 24 часа")
+	require.NoError(t, err)
+
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			{"Дата", "Время", "Источник", "Монета", "Направление", "Цена в источнике", "Цена на Bybit", "Цена через 1 час", "Цена через 24 часа"},
+			{"30.12.2025", "10:00:00", "ChannelA", "BTC", "long", "100", "", "110", "120"},
+			{"30.12.2025", "11:00:00", "ChannelA", "ETH", "short", "100", "", "95", ""},
+			{"31.12.2025", "12:00:00", "ChannelB", "SOL", "long", "100", "", "98", ""},
+			{"29.12.2025", "13:00:00", "ChannelB", "XRP", "short", "100", "", "101", ""},
+			{"01.01.2026", "14:00:00", "ChannelC", "DOGE", "long", "100", "", "103", ""},
+		},
+	}
+	sources := NewSourcesUsecase(sheetStore(sheet), &config.Config{GoogleSheetID: "test", Horizons: horizons})
+
+	t.Run("Таблица по средней доходности", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, sources.Sources(context.Background(), SourcesOptions{Out: &out}))
+
+		assert.Equal(t, "Signals: 5, sources: 3, entry price: auto\n\n"+
+			"SOURCE    SIGNALS  RETURNS  HIT RATE %  MEAN %  MEDIAN %  BEST           WORST\n"+
+			"ChannelA  2        3        100.00      11.67   10.00     24h (+20.00%)  1h (+7.50%)\n"+
+			"ChannelC  1        1        100.00      3.00    3.00      1h (+3.00%)    1h (+3.00%)\n"+
+			"ChannelB  2        2        0.00        -1.50   -1.50     1h (-1.50%)    1h (-1.50%)\n", out.String())
+	})
+
+	t.Run("Интервал дат и порог сигналов", func(t *testing.T) {
+		from := time.Date(2025, 12, 30, 0, 0, 0, 0, model.DefaultSheetTimezone)
+		to := time.Date(2025, 12, 31, 0, 0, 0, 0, model.DefaultSheetTimezone)
+
+		var out bytes.Buffer
+		require.NoError(t, sources.Sources(context.Background(), SourcesOptions{
+			Output: OutputCSV, Out: &out, From: from, To: to, Sort: model.SortBySource,
+		}))
+		assert.Equal(t, "source,signals,returns,hit_rate,mean,median,best,worst\n"+
+			"ChannelA,2,3,100.00,11.67,10.00,24h (+20.00%),1h (+7.50%)\n"+
+			"ChannelB,1,1,0.00,-2.00,-2.00,1h (-2.00%),1h (-2.00%)\n", out.String())
+
+		out.Reset()
+		require.NoError(t, sources.Sources(context.Background(), SourcesOptions{
+			Output: OutputCSV, Out: &out, From: from, To: to, MinSignals: 2,
+		}))
+		assert.Equal(t, "source,signals,returns,hit_rate,mean,median,best,worst\n"+
+			"ChannelA,2,3,100.00,11.67,10.00,24h (+20.00%),1h (+7.50%)\n", out.String())
+	})
+
+	t.Run("Ошибки параметров", func(t *testing.T) {
+		assert.Error(t, sources.Sources(context.Background(), SourcesOptions{Sort: "profit"}))
+		assert.Error(t, sources.Sources(context.Background(), SourcesOptions{Output: "xml"}))
+		assert.Error(t, sources.Sources(context.Background(), SourcesOptions{
+			From: time.Date(2026, 1, 2, 0, 0, 0, 0, model.DefaultSheetTimezone),
+			To:   time.Date(2026, 1, 1, 0, 0, 0, 0, model.DefaultSheetTimezone),
+		}))
+	})
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+)
+
+// publishStats перезаписывает лист статистики таблицами по горизонтам и по источникам
+// Лист с сигналами не затрагивается; если листа статистики нет, он создается.
+// perTab - у листа своя статистика (см. StatsSheetTitle): при обходе SHEET_TARGETS листы
+// одной таблицы иначе перезаписывали бы статистику друг друга.
+func (u *Process) publishStats(ctx context.Context, records recordstore.IRecordStore, sheet *sheetData, perTab bool) error {
+	title := u.config.StatsSheet
+	if title == "" {
+		return nil
+	}
+	if perTab {
+		title = StatsSheetTitle(title, sheet.dataRange.Sheet)
+	}
+	if title == sheet.dataRange.Sheet {
+		return fmt.Errorf("stats sheet %q is the data sheet", title)
+	}
+
+	now := u.clock.Now().In(sheet.layout.Timezones().Sheet())
+	values := statsSheetValues(sheet.records, sheetHorizons(sheet.layout), model.EntryAuto, now)
+	created, err := records.WriteSheet(ctx, title, values)
+	if err != nil {
+		return err
+	}
+	if created {
+		log.Printf("Created sheet %q for statistics\n", title)
+	}
+
+	log.Printf("Statistics published to sheet %q\n", title)
+	return nil
+}
+
+// StatsSheetTitle лист статистики листа с сигналами tab: "Stats (Сигналы 2025-01)"
+// Без названия листа (CSV файл) - общий лист статистики.
+func StatsSheetTitle(statsSheet string, tab string) string {
+	if statsSheet == "" || tab == "" {
+		return statsSheet
+	}
+
+	return statsSheet + " (" + tab + ")"
+}
+
+// IsStatsSheet проверяет, что лист - лист статистики: общий или лист статистики отдельного листа
+// Такие листы process пропускает, даже если они подходят под шаблон SHEET_TARGETS.
+func IsStatsSheet(statsSheet string, title string) bool {
+	if statsSheet == "" {
+		return false
+	}
+
+	return title == statsSheet || strings.HasPrefix(title, statsSheet+" (") && strings.HasSuffix(title, ")")
+}
+
+// statsSheetValues содержимое листа статистики: время обновления, таблица по горизонтам и рейтинг источников
+// now - время обновления в часовом поясе листа.
+// Проценты записываются числами, округленными до сотых, чтобы по ним можно было строить графики.
+func statsSheetValues(records []*model.CoinPriceRecord, horizons []model.Horizon, entry model.EntryBasis, now time.Time) [][]interface{} {
+	values := [][]interface{}{
+		{"Updated", now.Format(model.SheetDateFormat + " " + model.SheetTimeFormat)},
+		{"Signals", len(records)},
+		{"Entry price", string(entry)},
+		{},
+		{"By horizon"},
+		{"Horizon", "Signals", "Wins", "Win rate %", "Mean %", "Median %"},
+	}
+
+	for _, stats := range model.ComputeHorizonStats(records, horizons, entry) {
+		row := []interface{}{stats.Horizon.Key, stats.Count, stats.Wins, "", "", ""}
+		if stats.Count > 0 {
+			row[3], row[4], row[5] = roundPercent(stats.WinRate), roundPercent(stats.Mean), roundPercent(stats.Median)
+		}
+		values = append(values, row)
+	}
+
+	values = append(values,
+		[]interface{}{},
+		[]interface{}{"By source"},
+		[]interface{}{"Source", "Signals", "Returns", "Hit rate %", "Mean %", "Median %", "Best", "Best mean %", "Worst", "Worst mean %"},
+	)
+
+	sources := model.ComputeSourceStats(records, horizons, entry, 1)
+	_ = model.SortSourceStats(sources, model.SortByMean)
+	for _, stats := range sources {
+		row := []interface{}{stats.Source, stats.Signals, stats.Returns, "", "", "", "", "", "", ""}
+		if stats.Returns > 0 {
+			row[3], row[4], row[5] = roundPercent(stats.HitRate), roundPercent(stats.Mean), roundPercent(stats.Median)
+		}
+		if stats.Best != nil {
+			row[6], row[7] = stats.Best.Horizon.Key, roundPercent(stats.Best.Mean)
+			row[8], row[9] = stats.Worst.Horizon.Key, roundPercent(stats.Worst.Mean)
+		}
+		values = append(values, row)
+	}
+
+	return values
+}
+
+func roundPercent(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/adapter/recordstore"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/drybin/TrackMyCoin/pkg/a1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestProcess_PublishesStats(t *testing.T) {
+	sheet := &memorySheets{
+		title: "Лист1",
+		rows: [][]interface{}{
+			testHeader,
+			{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "100", "100", "110"},
+			{"29.12.2025", "10:00:00", "ChannelB", "ETH", "short", "100", "100", "102"},
+		},
+	}
+
+	process := newTestProcess(t, sheet, &fixedPriceProvider{price: 105})
+	process.config.StatsSheet = "Stats"
+	process.clock = &fakeClock{now: time.Date(2025, 12, 29, 10, 35, 0, 0, model.DefaultSheetTimezone)}
+
+	require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+
+	// Лист с сигналами: заполнены только цены, статистика туда не пишется
+	assert.Len(t, sheet.rows, 3)
+	assert.Equal(t, 105.0, sheet.rows[1][8])
+
+	// 10m: BTC +10%, ETH -2%; 30m: BTC +5%, ETH -5%
+	assert.Equal(t, [][]interface{}{
+		{"Updated", "29.12.2025 10:35:00"},
+		{"Signals", 2},
+		{"Entry price", "auto"},
+		{},
+		{"By horizon"},
+		{"Horizon", "Signals", "Wins", "Win rate %", "Mean %", "Median %"},
+		{"10m", 2, 1, 50.0, 4.0, 4.0},
+		{"30m", 2, 1, 50.0, 0.0, 0.0},
+		{"1h", 0, 0, "", "", ""},
+		{"2h", 0, 0, "", "", ""},
+		{"6h", 0, 0, "", "", ""},
+		{"12h", 0, 0, "", "", ""},
+		{"24h", 0, 0, "", "", ""},
+		{"3d", 0, 0, "", "", ""},
+		{"5d", 0, 0, "", "", ""},
+		{"7d", 0, 0, "", "", ""},
+		{"1M", 0, 0, "", "", ""},
+		{},
+		{"By source"},
+		{"Source", "Signals", "Returns", "Hit rate %", "Mean %", "Median %", "Best", "Best mean %", "Worst", "Worst mean %"},
+		{"ChannelA", 1, 2, 100.0, 7.5, 7.5, "10m", 10.0, "30m", 5.0},
+		{"ChannelB", 1, 2, 0.0, -3.5, -3.5, "10m", -2.0, "30m", -5.0},
+	}, sheet.tabs["Stats"])
+
+	// Лист статистики совпадает с листом данных - цены записаны, статистика пропущена
+	process.config.StatsSheet = "Лист1"
+	sheet.rows[2][8] = ""
+	require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+	assert.Equal(t, 105.0, sheet.rows[2][8])
+	assert.Len(t, sheet.rows, 3)
+}
+
+// spreadsheetTabs таблица с несколькими листами сигналов: чтение и запись идут в лист из диапазона,
+// листы статистики (tabs встроенной memorySheets) общие для всех листов
+type spreadsheetTabs struct {
+	*memorySheets
+	sheets map[string]*memorySheets
+}
+
+func (s *spreadsheetTabs) ReadSpreadsheet(ctx context.Context, spreadsheetID string, readRange string) (*sheets.ValueRange, error) {
+	r, err := a1.Parse(readRange)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sheets[r.Sheet].ReadSpreadsheet(ctx, spreadsheetID, readRange)
+}
+
+func (s *spreadsheetTabs) BatchUpdateSpreadsheet(ctx context.Context, spreadsheetID string, data []*sheets.ValueRange) error {
+	for _, valueRange := range data {
+		r, err := a1.Parse(valueRange.Range)
+		if err != nil {
+			return err
+		}
+		if err := s.sheets[r.Sheet].UpdateSpreadsheet(ctx, spreadsheetID, valueRange.Range, valueRange.Values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestProcess_PublishesStatsPerTab(t *testing.T) {
+	client := &spreadsheetTabs{
+		memorySheets: &memorySheets{},
+		sheets: map[string]*memorySheets{
+			"Январь": {title: "Январь", rows: [][]interface{}{
+				testHeader,
+				{"29.12.2025", "10:00:00", "ChannelA", "BTC", "long", "100", "100", "110"},
+			}},
+			"Февраль": {title: "Февраль", rows: [][]interface{}{
+				testHeader,
+				{"29.12.2025", "10:00:00", "ChannelB", "ETH", "short", "100", "100", "102"},
+				{"29.12.2025", "10:00:00", "ChannelB", "SOL", "short", "100", "100", "102"},
+			}},
+		},
+	}
+
+	process := newTestProcess(t, client.memorySheets, &fixedPriceProvider{price: 105})
+	process.config.StatsSheet = "Stats"
+	process.clock = &fakeClock{now: time.Date(2025, 12, 29, 10, 35, 0, 0, model.DefaultSheetTimezone)}
+	process.SetTargets(staticTargets{
+		{Name: "Test / Январь", Store: recordstore.NewGoogleSheets(client, "test", "'Январь'")},
+		{Name: "Test / Февраль", Store: recordstore.NewGoogleSheets(client, "test", "'Февраль'")},
+	})
+
+	require.NoError(t, process.Process(context.Background(), ProcessOptions{}))
+
+	// Листы одной таблицы не перезаписывают статистику друг друга
+	require.Contains(t, client.tabs, "Stats (Январь)")
+	require.Contains(t, client.tabs, "Stats (Февраль)")
+	assert.NotContains(t, client.tabs, "Stats")
+	assert.Equal(t, []interface{}{"Signals", 1}, client.tabs["Stats (Январь)"][1])
+	assert.Equal(t, []interface{}{"Signals", 2}, client.tabs["Stats (Февраль)"][1])
+	assert.Equal(t, []interface{}{"ChannelA", 1, 2, 100.0, 7.5, 7.5, "10m", 10.0, "30m", 5.0}, client.tabs["Stats (Январь)"][20])
+	assert.Equal(t, []interface{}{"ChannelB", 2, 4, 0.0, -3.5, -3.5, "10m", -2.0, "30m", -5.0}, client.tabs["Stats (Февраль)"][20])
+}
+
+func TestIsStatsSheet(t *testing.T) {
+	assert.True(t, IsStatsSheet("Stats", "Stats"))
+	assert.True(t, IsStatsSheet("Stats", "Stats (Сигналы 2025-01)"))
+	assert.False(t, IsStatsSheet("Stats", "Сигналы 2025-01"))
+	assert.False(t, IsStatsSheet("Stats", "Stats 2025"))
+	// Публикация статистики выключена - все листы могут быть листами с сигналами
+	assert.False(t, IsStatsSheet("", "Stats"))
+}
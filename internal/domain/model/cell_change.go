@@ -0,0 +1,70 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CellChange изменение одной ячейки записи относительно значения, прочитанного из таблицы
+type CellChange struct {
+	Row      int         // Номер строки в листе
+	Column   int         // Номер колонки в строке записи, начиная с 1
+	Field    string      // Поле записи (например, "10m")
+	OldValue interface{} // Значение, прочитанное из таблицы
+	NewValue interface{} // Значение, которое нужно записать
+	Provider string      // Провайдер цены, если значение получено у провайдера
+}
+
+// Changes возвращает ячейки, значения которых отличаются от прочитанных из таблицы
+func (r *CoinPriceRecord) Changes() []CellChange {
+	var changes []CellChange
+
+	layout := r.layout
+	if layout == nil {
+		layout = DefaultSheetLayout()
+	}
+
+	for i, value := range r.ToRow() {
+		var oldValue interface{} = ""
+		if i < len(r.originalRow) && r.originalRow[i] != nil {
+			oldValue = r.originalRow[i]
+		}
+
+		if cellValuesEqual(oldValue, value) {
+			continue
+		}
+
+		field := layout.Field(i)
+		changes = append(changes, CellChange{
+			Row:      r.RowNumber,
+			Column:   i + 1,
+			Field:    field,
+			OldValue: oldValue,
+			NewValue: value,
+			Provider: r.Samples[field].Provider,
+		})
+	}
+
+	return changes
+}
+
+// cellValuesEqual сравнивает значения ячеек: "45000.50" и "45 000,50" считаются одинаковыми
+// Текст и число не равны, даже если текст - то же число: текстовая ячейка перезаписывается числом.
+func cellValuesEqual(a interface{}, b interface{}) bool {
+	if _, isText := a.(string); isText {
+		if _, isNumber := b.(float64); isNumber {
+			return false
+		}
+	}
+
+	aStr := strings.TrimSpace(fmt.Sprintf("%v", a))
+	bStr := strings.TrimSpace(fmt.Sprintf("%v", b))
+	if aStr == bStr {
+		return true
+	}
+
+	aFloat, aErr := ParseNumber(aStr)
+	bFloat, bErr := ParseNumber(bStr)
+
+	return aErr == nil && bErr == nil && aFloat == bFloat
+}
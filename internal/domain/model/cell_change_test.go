@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoinPriceRecord_Changes(t *testing.T) {
+	t.Run("Без изменений", func(t *testing.T) {
+		record, err := ParseFromRow([]interface{}{"29.12.2025", "10:30:00", "Binance", "BTC", "UP", 45000.5, 45010.0})
+		assert.NoError(t, err)
+
+		assert.Empty(t, record.Changes())
+	})
+
+	t.Run("Заполненные цены", func(t *testing.T) {
+		record, err := ParseFromRow([]interface{}{"29.12.2025", "10:30:00", "Binance", "BTC", "UP", 45000.5, ""})
+		assert.NoError(t, err)
+		record.RowNumber = 7
+
+		record.BybitPrice = NewPriceValue(45010)
+		record.RecordSample(BybitPriceField, PricePoint{Price: 45010, Provider: "bybit"})
+		record.SetPrice("1h", NewPriceValue(45300))
+
+		changes := record.Changes()
+		assert.Equal(t, []CellChange{
+			{Row: 7, Column: 7, Field: BybitPriceField, OldValue: "", NewValue: 45010.0, Provider: "bybit"},
+			{Row: 7, Column: 10, Field: "1h", OldValue: "", NewValue: 45300.0},
+		}, changes)
+	})
+
+	t.Run("Цена записана текстом", func(t *testing.T) {
+		// Ячейки с текстом вместо числа: числовой формат колонки к ним не применяется
+		record, err := ParseFromRow([]interface{}{"29.12.2025", "10:30:00", "Binance", "BTC", "UP", "45000.50", "45 010,00", "WAIT"})
+		assert.NoError(t, err)
+		record.RowNumber = 7
+
+		assert.Equal(t, []CellChange{
+			{Row: 7, Column: 6, Field: FieldSourcePrice, OldValue: "45000.50", NewValue: 45000.5},
+			{Row: 7, Column: 7, Field: BybitPriceField, OldValue: "45 010,00", NewValue: 45010.0},
+		}, record.Changes())
+	})
+}
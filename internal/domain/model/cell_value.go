@@ -0,0 +1,149 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// sheetsEpoch день 0 серийных дат Google Sheets (и Excel): 30.12.1899
+var sheetsEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// maxSerialDate серийный номер 31.12.9999: большие числа в колонке даты - Unix время, а не серийная дата
+const maxSerialDate = 2958465
+
+// SerialTime переводит серийный номер даты и времени Google Sheets (дни с 30.12.1899, дробная часть - время суток)
+// в момент с теми же датой и временем на часах в часовом поясе location
+func SerialTime(serial float64, location *time.Location) time.Time {
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+
+	// Дата собирается из календарных полей, а не прибавлением длительности: переход на летнее время не сдвигает часы
+	return time.Date(sheetsEpoch.Year(), sheetsEpoch.Month(), sheetsEpoch.Day()+int(days), 0, 0, int(seconds), 0, location)
+}
+
+// textCellValue текст поля записи из ячейки листа
+// Ячейки дат и времени, прочитанные без форматирования, приходят серийными номерами: дата переводится
+// в SheetDateFormat (с временем, если в ячейке дата и время), время - в SheetTimeFormat.
+func textCellValue(field string, cell interface{}) string {
+	switch value := cell.(type) {
+	case nil:
+		return ""
+	case float64:
+		switch {
+		case field == FieldDate && value >= 0 && value <= maxSerialDate:
+			t := SerialTime(value, time.UTC)
+			if value == math.Floor(value) {
+				return t.Format(SheetDateFormat)
+			}
+			return t.Format(SheetDateFormat + " " + SheetTimeFormat)
+		case field == FieldTime && value >= 0 && value < 1:
+			return SerialTime(value, time.UTC).Format(SheetTimeFormat)
+		default:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	default:
+		return fmt.Sprintf("%v", cell)
+	}
+}
+
+// numberCellValue число из ячейки листа: пустая ячейка - 0, текст разбирается ParseNumber
+func numberCellValue(cell interface{}) (float64, error) {
+	switch value := cell.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return value, nil
+	case int:
+		return float64(value), nil
+	}
+
+	text := strings.TrimSpace(fmt.Sprintf("%v", cell))
+	if text == "" {
+		return 0, nil
+	}
+
+	return ParseNumber(text)
+}
+
+// currencySymbols символы валют, которые допускаются перед числом или после него
+const currencySymbols = "$€£¥₽₮₿"
+
+// ParseNumber разбирает число, записанное в листе текстом в любой локали:
+// 45000.5, 45 000,50, 45,000.50, 45.000,50, $0.0046, 0,0046 ₽, 1.2e-5
+// Разделитель, который встречается несколько раз, - разделитель групп разрядов. Если есть и точка, и запятая,
+// десятичный разделитель - последний из них. Одиночный разделитель считается десятичным (45,5, 0.004),
+// кроме неоднозначного случая, когда за ним ровно три цифры: 45,000 и 45.000 одинаково читаются
+// как 45 и как 45000, поэтому такой текст - ошибка, а не угаданное число.
+func ParseNumber(text string) (float64, error) {
+	original := text
+	text = strings.TrimSpace(text)
+	text = strings.TrimFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(currencySymbols, r)
+	})
+
+	// Пробелы (в том числе неразрывные) и апостроф - разделители групп разрядов
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '\'' {
+			return -1
+		}
+		return r
+	}, text)
+
+	// Знак может стоять перед символом валюты: -$5
+	sign := ""
+	if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		sign, text = text[:1], strings.TrimLeft(text[1:], currencySymbols)
+	}
+
+	mantissa, exponent := text, ""
+	if i := strings.IndexAny(text, "eE"); i > 0 {
+		mantissa, exponent = text[:i], text[i:]
+	}
+
+	commas := strings.Count(mantissa, ",")
+	dots := strings.Count(mantissa, ".")
+	switch {
+	case commas > 0 && dots > 0:
+		if strings.LastIndex(mantissa, ",") > strings.LastIndex(mantissa, ".") {
+			mantissa = strings.ReplaceAll(strings.ReplaceAll(mantissa, ".", ""), ",", ".")
+		} else {
+			mantissa = strings.ReplaceAll(mantissa, ",", "")
+		}
+	case commas > 1:
+		mantissa = strings.ReplaceAll(mantissa, ",", "")
+	case commas == 1:
+		if ambiguousSeparator(mantissa, ",") {
+			return 0, fmt.Errorf("ambiguous number %q: the comma may separate decimals or thousands", original)
+		}
+		mantissa = strings.Replace(mantissa, ",", ".", 1)
+	case dots > 1:
+		mantissa = strings.ReplaceAll(mantissa, ".", "")
+	case dots == 1:
+		if ambiguousSeparator(mantissa, ".") {
+			return 0, fmt.Errorf("ambiguous number %q: the dot may separate decimals or thousands", original)
+		}
+	}
+
+	value, err := strconv.ParseFloat(sign+mantissa+exponent, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("invalid number %q", original)
+	}
+
+	return value, nil
+}
+
+// ambiguousSeparator проверяет, что одиночный разделитель separator может быть и десятичным, и разделителем
+// групп разрядов: 45,000, 1.234. Целая часть с ведущим нулем (0,004) или длиннее трех цифр (1234.567)
+// группой не бывает.
+func ambiguousSeparator(mantissa string, separator string) bool {
+	integer, fraction, _ := strings.Cut(mantissa, separator)
+	if len(fraction) != 3 || len(integer) == 0 || len(integer) > 3 || integer[0] == '0' {
+		return false
+	}
+
+	return isDigits(integer) && isDigits(fraction)
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumber(t *testing.T) {
+	numbers := map[string]float64{
+		"45000.5":   45000.5,
+		"45 000,50": 45000.5,
+		"45 000,50": 45000.5,
+		"45,000.50": 45000.5,
+		"45.000,50": 45000.5,
+		"1,234,567": 1234567,
+		"1.234.567": 1234567,
+		"1'234.5":   1234.5,
+		"$0.0046":   0.0046,
+		"0,0046 ₽":  0.0046,
+		"-$5":       -5,
+		"1.2e-5":    0.000012,
+		"1,2E-5":    0.000012,
+		" 3000 ":    3000,
+		"45,5":      45.5,
+		"0,004":     0.004,
+		"1234,567":  1234.567,
+		"1234.567":  1234.567,
+		"0.004":     0.004,
+		"45,0000":   45,
+	}
+	for text, expected := range numbers {
+		value, err := ParseNumber(text)
+		require.NoError(t, err, text)
+		assert.InDelta(t, expected, value, 1e-12, text)
+	}
+
+	for _, text := range []string{"", "abc", "12abc", "N/A", "NaN", "Inf", "1.2.3,4,5"} {
+		_, err := ParseNumber(text)
+		assert.Error(t, err, text)
+	}
+
+	// Одиночный разделитель и ровно три цифры после него: 45 или 45000 - не угадываем
+	for _, text := range []string{"45,000", "$1,234", "-1,500", "999,999 ₽", "45.000", "$1.234", "-1.500"} {
+		_, err := ParseNumber(text)
+		assert.ErrorContains(t, err, "ambiguous", text)
+	}
+}
+
+func TestSerialTime(t *testing.T) {
+	assert.Equal(t, time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC), SerialTime(46020, time.UTC))
+	assert.Equal(t, time.Date(2025, 12, 29, 10, 30, 0, 0, DefaultSheetTimezone), SerialTime(46020.4375, DefaultSheetTimezone))
+	assert.Equal(t, time.Date(1899, 12, 30, 18, 0, 0, 0, time.UTC), SerialTime(0.75, time.UTC))
+
+	// Часы не сдвигаются переходом на летнее время
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 30, 12, 0, 0, 0, berlin), SerialTime(45746.5, berlin))
+}
+
+func TestSheetLayout_ParseUnformattedRow(t *testing.T) {
+	layout := NewSheetLayout([]Horizon{{Key: "1h", Headers: []string{"Цена через 1 час"}, Minutes: 60}})
+
+	t.Run("Серийные дата и время и числа", func(t *testing.T) {
+		row := []interface{}{46020.0, 0.4375, "ChannelA", "BTC", "long", 45000.5, "45 010,25", "1,2e-5"}
+		record, err := layout.ParseRow(row)
+		require.NoError(t, err)
+
+		assert.Equal(t, "29.12.2025", record.Date)
+		assert.Equal(t, "10:30:00", record.Time)
+		assert.Equal(t, 45000.5, record.SourcePrice)
+		assert.Equal(t, NewPriceValue(45010.25), record.BybitPrice)
+		assert.Equal(t, NewPriceValue(0.000012), record.Price("1h"))
+
+		signalAt, err := record.TryParseDateTime()
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 12, 29, 10, 30, 0, 0, DefaultSheetTimezone), signalAt)
+
+		// Дата остается серийным номером, цены, записанные текстом, перезаписываются числами
+		assert.Equal(t, []CellChange{
+			{Column: 7, Field: BybitPriceField, OldValue: "45 010,25", NewValue: 45010.25},
+			{Column: 8, Field: "1h", OldValue: "1,2e-5", NewValue: 0.000012},
+		}, record.Changes())
+		assert.Equal(t, []interface{}{46020.0, 0.4375, "ChannelA", "BTC", "long", 45000.5, 45010.25, 0.000012}, record.ToRow())
+	})
+
+	t.Run("Дата и время в одной ячейке", func(t *testing.T) {
+		record, err := layout.ParseRow([]interface{}{46020.4375, "", "ChannelA", "BTC", "long"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "29.12.2025 10:30:00", record.Date)
+		signalAt, err := record.TryParseDateTime()
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 12, 29, 10, 30, 0, 0, DefaultSheetTimezone), signalAt)
+	})
+
+	t.Run("Unix время числом", func(t *testing.T) {
+		record, err := layout.ParseRow([]interface{}{1767002400.0, "", "ChannelA", "BTC", "long"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "1767002400", record.Date)
+		signalAt, err := record.TryParseDateTime()
+		require.NoError(t, err)
+		assert.True(t, time.Date(2025, 12, 29, 10, 0, 0, 0, time.UTC).Equal(signalAt))
+	})
+
+	t.Run("Нераспознанная цена в источнике - ошибка цены, а не строки", func(t *testing.T) {
+		for _, price := range []string{"около 45k", "45,000", "45.000"} {
+			record, err := layout.ParseRow([]interface{}{"29.12.2025", "10:30:00", "ChannelA", "BTC", "long", price, 45100.0})
+			require.NoError(t, err, price)
+			assert.ErrorContains(t, record.SourcePriceErr, "invalid source price", price)
+
+			// Ячейка не перезаписывается, цена Bybit не выдается за цену входа
+			assert.Equal(t, price, record.ToRow()[5])
+			_, ok := record.EntryPrice(EntryAuto)
+			assert.False(t, ok, price)
+			entry, ok := record.EntryPrice(EntryBybit)
+			assert.True(t, ok, price)
+			assert.Equal(t, 45100.0, entry)
+		}
+	})
+}
@@ -2,70 +2,102 @@ package model
 
 import (
 	"fmt"
-	"strconv"
+	"strings"
 	"time"
 )
 
 // CoinPriceRecord представляет запись о цене монеты из Google Sheets
 type CoinPriceRecord struct {
-	Date         string  // Дата
-	Time         string  // Время
-	Source       string  // Источник
-	Coin         string  // Монета
-	Direction    string  // Направление
-	SourcePrice  float64 // Цена в источнике
-	BybitPrice   float64 // Цена на Bybit
-	Price10Min   float64 // Цена через 10 минут
-	Price30Min   float64 // Цена через 30 минут
-	Price1Hour   float64 // Цена через 1 час
-	Price2Hours  float64 // Цена через 2 часа
-	Price6Hours  float64 // Цена через 6 часов
-	Price12Hours float64 // Цена через 12 часов
-	Price24Hours float64 // Цена через 24 часов
-	Price3Days   float64 // Цена через 3 дня
-	Price5Days   float64 // Цена через 5 дней
-	Price7Days   float64 // Цена через 7 дней
-	Price1Month  float64 // Цена через 1 месяц
+	Date        string     // Дата
+	Time        string     // Время
+	Source      string     // Источник
+	Coin        string     // Монета
+	Direction   string     // Направление
+	SourcePrice float64    // Цена в источнике
+	BybitPrice  PriceValue // Цена на Bybit
+	Timezone    string     // Часовой пояс даты и времени (необязательная колонка, пусто - пояс источника или листа)
 
+	// SourcePriceErr почему не распознана цена в источнике (nil - цена распознана или пуста)
+	// Строка с такой ценой обрабатывается, но доходность от цены в источнике для нее не считается.
+	SourcePriceErr error
+
+	// Prices цены через временные интервалы: ключ горизонта ("10m", "1h", "1M", ...) → значение ячейки
+	Prices map[string]PriceValue
+
+	// RowNumber номер строки в листе, из которой прочитана запись (0 - запись не из таблицы)
+	RowNumber int
+
+	// SheetID таблица и лист, из которых прочитана запись ("<ID таблицы>!<лист>", пусто - запись не из таблицы)
+	// Входит в Key: одинаковые сигналы в разных листах - разные записи.
+	SheetID string
+
+	// Samples сэмплы, которыми поля были заполнены в текущем запуске (поле → цена, время сэмпла и провайдер)
+	Samples map[string]PricePoint
+
+	// Раскладка колонок листа, из которого прочитана запись
+	layout *SheetLayout
 	// Оригинальная строка из Google Sheets для сохранения исходных значений
 	originalRow []interface{}
 }
 
 // ParseFromRow парсит строку из Google Sheets в CoinPriceRecord
-// Ожидаемый порядок колонок:
+// Ожидаемый порядок колонок (DefaultSheetLayout с горизонтами DefaultHorizons):
 // Дата, Время, Источник, Монета, Направление, Цена в источнике, Цена на Bybit,
 // Цена через 10 минут, Цена через 30 минут, Цена через 1 час, Цена через 2 часа,
 // Цена через 6 часов, Цена через 12 часов, Цена через 24 часов,
 // Цена через 3 дня, Цена через 5 дней, Цена через 7 дней, Цена через 1 месяц
+// Для листов с другим порядком колонок используйте SheetLayout.ParseRow
 func ParseFromRow(row []interface{}) (*CoinPriceRecord, error) {
-	if len(row) < 5 {
-		return nil, fmt.Errorf("invalid row: expected at least 5 columns, got %d", len(row))
+	return DefaultSheetLayout().ParseRow(row)
+}
+
+// NewRecord создает пустую запись для новой строки листа с этой раскладкой колонок
+func (l *SheetLayout) NewRecord() *CoinPriceRecord {
+	return &CoinPriceRecord{
+		Prices: make(map[string]PriceValue),
+		layout: l,
+	}
+}
+
+// ParseRow парсит строку листа в CoinPriceRecord по раскладке колонок
+func (l *SheetLayout) ParseRow(row []interface{}) (*CoinPriceRecord, error) {
+	if minLength := l.minRowLength(); len(row) < minLength {
+		return nil, fmt.Errorf("invalid row: expected at least %d columns, got %d", minLength, len(row))
 	}
 
 	record := &CoinPriceRecord{
-		Date:      getStringValue(row, 0),
-		Time:      getStringValue(row, 1),
-		Source:    getStringValue(row, 2),
-		Coin:      getStringValue(row, 3),
-		Direction: getStringValue(row, 4),
+		Prices: make(map[string]PriceValue),
+		layout: l,
 		// Сохраняем оригинальную строку для возможности восстановления значений
 		originalRow: row,
 	}
 
+	for field, value := range record.textFields() {
+		if index, ok := l.Index(field); ok {
+			*value = textCellValue(field, cellAt(row, index))
+		}
+	}
+
 	// Парсим цены (могут быть пустыми)
-	record.SourcePrice = getFloatValue(row, 5)
-	record.BybitPrice = getFloatValue(row, 6)
-	record.Price10Min = getFloatValue(row, 7)
-	record.Price30Min = getFloatValue(row, 8)
-	record.Price1Hour = getFloatValue(row, 9)
-	record.Price2Hours = getFloatValue(row, 10)
-	record.Price6Hours = getFloatValue(row, 11)
-	record.Price12Hours = getFloatValue(row, 12)
-	record.Price24Hours = getFloatValue(row, 13)
-	record.Price3Days = getFloatValue(row, 14)
-	record.Price5Days = getFloatValue(row, 15)
-	record.Price7Days = getFloatValue(row, 16)
-	record.Price1Month = getFloatValue(row, 17)
+	// Нераспознанная цена в источнике не отменяет строку: цены Bybit и ближних горизонтов позже уже не получить.
+	// Она запоминается в SourcePriceErr, а ячейка остается как есть.
+	if index, ok := l.Index(FieldSourcePrice); ok {
+		price, err := numberCellValue(cellAt(row, index))
+		if err != nil {
+			record.SourcePriceErr = fmt.Errorf("invalid source price: %w", err)
+		}
+		record.SourcePrice = price
+	}
+	if index, ok := l.Index(BybitPriceField); ok {
+		record.BybitPrice = getPriceValue(row, index)
+	}
+	for _, horizon := range l.Horizons() {
+		if index, ok := l.Index(horizon.Key); ok {
+			if value := getPriceValue(row, index); value.State != PriceEmpty {
+				record.Prices[horizon.Key] = value
+			}
+		}
+	}
 
 	return record, nil
 }
@@ -75,9 +107,53 @@ func (r *CoinPriceRecord) GetDateTime() string {
 	return fmt.Sprintf("%s %s", r.Date, r.Time)
 }
 
+// Форматы даты и времени, в которых записываются новые сигналы
+const (
+	SheetDateFormat = "02.01.2006"
+	SheetTimeFormat = "15:04:05"
+)
+
+// ParseSheetDate разбирает дату вида 29.12.2025 или 2025-12-29 в часовом поясе location
+func ParseSheetDate(value string, location *time.Location) (time.Time, error) {
+	for _, format := range []string{SheetDateFormat, "2006-01-02"} {
+		if t, err := time.ParseInLocation(format, strings.TrimSpace(value), location); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q: expected DD.MM.YYYY or YYYY-MM-DD", value)
+}
+
+// SetDateTime записывает дату и время сигнала в часовом поясе записи (см. Location)
+func (r *CoinPriceRecord) SetDateTime(t time.Time) {
+	location, err := r.Location()
+	if err != nil {
+		location = r.timezones().Sheet()
+	}
+	t = t.In(location)
+	r.Date = t.Format(SheetDateFormat)
+	r.Time = t.Format(SheetTimeFormat)
+}
+
 // TryParseDateTime пытается распарсить дату и время в time.Time
-// Возвращает время в часовом поясе GMT+7
+// Дата и время без смещения читаются в часовом поясе записи (см. Location).
+// Колонка даты или времени может содержать момент ISO-8601 со смещением (2025-12-29T10:00:00+03:00)
+// или Unix время в секундах или миллисекундах - тогда пояс записи на момент не влияет.
+// Возвращает время в часовом поясе записи.
 func (r *CoinPriceRecord) TryParseDateTime() (time.Time, error) {
+	location, err := r.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	date := strings.TrimSpace(r.Date)
+	clock := strings.TrimSpace(r.Time)
+	for _, value := range []string{date, clock, date + "T" + clock} {
+		if t, ok := parseTimestamp(value); ok {
+			return t.In(location), nil
+		}
+	}
+
 	// Пробуем различные форматы
 	formats := []string{
 		"02.01.2006 15:04:05",
@@ -86,10 +162,8 @@ func (r *CoinPriceRecord) TryParseDateTime() (time.Time, error) {
 		"2006-01-02 15:04",
 	}
 
-	dateTimeStr := r.GetDateTime()
-
-	// GMT+7 часовой пояс
-	location := time.FixedZone("GMT+7", 7*60*60)
+	// Колонка даты может содержать и время (ячейка с датой и временем), тогда время пустое
+	dateTimeStr := strings.TrimSpace(r.GetDateTime())
 
 	for _, format := range formats {
 		if t, err := time.ParseInLocation(format, dateTimeStr, location); err == nil {
@@ -100,112 +174,224 @@ func (r *CoinPriceRecord) TryParseDateTime() (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse date/time: %s", dateTimeStr)
 }
 
-// PriceField представляет поле с ценой и соответствующий ему временной интервал
+// BybitPriceField имя поля "Цена на Bybit" для сэмплов и маршрутизации цен
+const BybitPriceField = "BybitPrice"
+
+// PriceField представляет поле с ценой и соответствующий ему временной горизонт
 type PriceField struct {
-	Name     string        // Название поля (например, "Price10Min")
-	Duration time.Duration // Интервал времени
-	Value    *float64      // Указатель на значение в структуре
+	Name    string  // Название поля - ключ горизонта (например, "10m")
+	Horizon Horizon // Горизонт: интервал и заголовок колонки
 }
 
-// GetPriceFields возвращает список всех полей с ценами и их временными интервалами
+// GetPriceFields возвращает список всех полей с ценами и их горизонтами
+// Горизонты берутся из листа, из которого прочитана запись (по умолчанию DefaultHorizons)
 func (r *CoinPriceRecord) GetPriceFields() []PriceField {
-	return []PriceField{
-		{Name: "Price10Min", Duration: 10 * time.Minute, Value: &r.Price10Min},
-		{Name: "Price30Min", Duration: 30 * time.Minute, Value: &r.Price30Min},
-		{Name: "Price1Hour", Duration: 1 * time.Hour, Value: &r.Price1Hour},
-		{Name: "Price2Hours", Duration: 2 * time.Hour, Value: &r.Price2Hours},
-		{Name: "Price6Hours", Duration: 6 * time.Hour, Value: &r.Price6Hours},
-		{Name: "Price12Hours", Duration: 12 * time.Hour, Value: &r.Price12Hours},
-		{Name: "Price24Hours", Duration: 24 * time.Hour, Value: &r.Price24Hours},
-		{Name: "Price3Days", Duration: 3 * 24 * time.Hour, Value: &r.Price3Days},
-		{Name: "Price5Days", Duration: 5 * 24 * time.Hour, Value: &r.Price5Days},
-		{Name: "Price7Days", Duration: 7 * 24 * time.Hour, Value: &r.Price7Days},
-		{Name: "Price1Month", Duration: 30 * 24 * time.Hour, Value: &r.Price1Month}, // Примерно 1 месяц
+	var fields []PriceField
+	for _, horizon := range r.horizons() {
+		fields = append(fields, PriceField{Name: horizon.Key, Horizon: horizon})
 	}
+
+	return fields
 }
 
-// ShouldFetchPrice проверяет, нужно ли получать цену для указанного временного интервала
-// Возвращает true, если время уже наступило и цена еще не заполнена
-func (r *CoinPriceRecord) ShouldFetchPrice(field PriceField, now time.Time) (bool, error) {
-	// Если цена уже заполнена, не нужно получать
-	if *field.Value != 0 {
-		return false, nil
+// Price возвращает значение ячейки горизонта (для незаполненной ячейки - PriceEmpty)
+func (r *CoinPriceRecord) Price(horizonKey string) PriceValue {
+	return r.Prices[horizonKey]
+}
+
+// SetPrice устанавливает значение ячейки горизонта
+func (r *CoinPriceRecord) SetPrice(horizonKey string, value PriceValue) {
+	if r.Prices == nil {
+		r.Prices = make(map[string]PriceValue)
 	}
+	r.Prices[horizonKey] = value
+}
 
-	// Парсим дату и время записи
+// TargetTime возвращает момент, на который должна быть зафиксирована цена поля
+// (время записи + горизонт поля)
+func (r *CoinPriceRecord) TargetTime(field PriceField) (time.Time, error) {
 	recordTime, err := r.TryParseDateTime()
 	if err != nil {
-		return false, err
+		return time.Time{}, err
 	}
 
+	return field.Horizon.Target(recordTime), nil
+}
+
+// IsDue проверяет, наступил ли момент, на который нужна цена поля
+func (r *CoinPriceRecord) IsDue(field PriceField, now time.Time) (bool, error) {
 	// Рассчитываем время, когда должна быть эта цена
-	targetTime := recordTime.Add(field.Duration)
+	targetTime, err := r.TargetTime(field)
+	if err != nil {
+		return false, err
+	}
 
-	// Если текущее время больше или равно целевому времени, нужно получить цену
 	return now.After(targetTime) || now.Equal(targetTime), nil
 }
 
-// Вспомогательные функции
-
-func getStringValue(row []interface{}, index int) string {
-	if index >= len(row) {
-		return ""
+// ShouldFetchPrice проверяет, нужно ли получать цену для указанного временного интервала
+// Возвращает true, если время уже наступило, а в ячейке нет цены (пусто, WAIT или ERR)
+func (r *CoinPriceRecord) ShouldFetchPrice(field PriceField, now time.Time) (bool, error) {
+	// Заполненные, ручные и недоступные значения не запрашиваются
+	if !r.Price(field.Name).NeedsFetch() {
+		return false, nil
 	}
-	if row[index] == nil {
-		return ""
+
+	return r.IsDue(field, now)
+}
+
+// NextDue возвращает ближайший еще не наступивший момент горизонта, цену на который нужно будет получить
+// false - таких горизонтов нет (все цены получены или дату/время записи не удалось распарсить)
+func (r *CoinPriceRecord) NextDue(now time.Time) (time.Time, bool) {
+	var next time.Time
+
+	for _, field := range r.GetPriceFields() {
+		if !r.HasColumn(field.Name) || !r.Price(field.Name).NeedsFetch() {
+			continue
+		}
+
+		targetTime, err := r.TargetTime(field)
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		if targetTime.After(now) && (next.IsZero() || targetTime.Before(next)) {
+			next = targetTime
+		}
 	}
-	return fmt.Sprintf("%v", row[index])
+
+	return next, !next.IsZero()
 }
 
-func getFloatValue(row []interface{}, index int) float64 {
-	if index >= len(row) {
-		return 0
+// Key идентификатор сигнала, не зависящий от номера строки: лист, дата, время, источник, монета и направление
+// Запись сохраняет свой ключ при сортировке листа и удалении соседних строк.
+func (r *CoinPriceRecord) Key() string {
+	key := strings.Join([]string{
+		strings.TrimSpace(r.Date),
+		strings.TrimSpace(r.Time),
+		strings.TrimSpace(r.Source),
+		strings.ToUpper(strings.TrimSpace(r.Coin)),
+		strings.ToLower(strings.TrimSpace(r.Direction)),
+	}, "|")
+	if r.SheetID == "" {
+		return key
 	}
-	if row[index] == nil {
-		return 0
+
+	return r.SheetID + "|" + key
+}
+
+// SetLayout привязывает запись к раскладке колонок листа, в который она будет записана
+func (r *CoinPriceRecord) SetLayout(layout *SheetLayout) {
+	r.layout = layout
+}
+
+// RecordSample запоминает, каким сэмплом и от какого провайдера было заполнено поле
+func (r *CoinPriceRecord) RecordSample(fieldName string, point PricePoint) {
+	if r.Samples == nil {
+		r.Samples = make(map[string]PricePoint)
 	}
+	r.Samples[fieldName] = point
+}
 
-	str := fmt.Sprintf("%v", row[index])
-	if str == "" {
-		return 0
+// Вспомогательные функции
+
+// cellAt возвращает ячейку строки (nil, если строка короче)
+func cellAt(row []interface{}, index int) interface{} {
+	if index >= len(row) {
+		return nil
 	}
 
-	val, err := strconv.ParseFloat(str, 64)
-	if err != nil {
-		return 0
+	return row[index]
+}
+
+func getPriceValue(row []interface{}, index int) PriceValue {
+	if index >= len(row) {
+		return PriceValue{}
 	}
-	return val
+
+	return ParsePriceValue(row[index])
 }
 
 // String возвращает строковое представление записи
 func (r *CoinPriceRecord) String() string {
-	return fmt.Sprintf("Date: %s, Time: %s, Source: %s, Coin: %s, Direction: %s, SourcePrice: %.2f, BybitPrice: %.2f",
+	return fmt.Sprintf("Date: %s, Time: %s, Source: %s, Coin: %s, Direction: %s, SourcePrice: %.2f, BybitPrice: %s",
 		r.Date, r.Time, r.Source, r.Coin, r.Direction, r.SourcePrice, r.BybitPrice)
 }
 
 // ToRow конвертирует запись обратно в формат строки для Google Sheets
-// Использует оригинальные значения для полей, которые не были обновлены (остались 0)
+// Цены записываются в представлении своего состояния (число, WAIT, ERR, N/A, ручное значение);
+// если значение не изменилось, в строке остается исходная ячейка.
+// Колонки, которые не сопоставлены полям записи, возвращаются из оригинальной строки без изменений.
 func (r *CoinPriceRecord) ToRow() []interface{} {
-	return []interface{}{
-		r.Date,
-		r.Time,
-		r.Source,
-		r.Coin,
-		r.Direction,
-		r.getValueOrOriginal(5, r.SourcePrice),
-		r.getValueOrOriginal(6, r.BybitPrice),
-		r.getValueOrOriginal(7, r.Price10Min),
-		r.getValueOrOriginal(8, r.Price30Min),
-		r.getValueOrOriginal(9, r.Price1Hour),
-		r.getValueOrOriginal(10, r.Price2Hours),
-		r.getValueOrOriginal(11, r.Price6Hours),
-		r.getValueOrOriginal(12, r.Price12Hours),
-		r.getValueOrOriginal(13, r.Price24Hours),
-		r.getValueOrOriginal(14, r.Price3Days),
-		r.getValueOrOriginal(15, r.Price5Days),
-		r.getValueOrOriginal(16, r.Price7Days),
-		r.getValueOrOriginal(17, r.Price1Month),
+	layout := r.layout
+	if layout == nil {
+		layout = DefaultSheetLayout()
+	}
+
+	row := make([]interface{}, max(layout.Width(), len(r.originalRow)))
+	for i := range row {
+		row[i] = ""
+		if i < len(r.originalRow) && r.originalRow[i] != nil {
+			row[i] = r.originalRow[i]
+		}
 	}
+
+	for field, value := range r.textFields() {
+		index, ok := layout.Index(field)
+		if !ok {
+			continue
+		}
+		// Неизменное значение остается исходной ячейкой: дата-серийный номер не превращается в текст
+		if original := cellAt(r.originalRow, index); original != nil && textCellValue(field, original) == *value {
+			continue
+		}
+		row[index] = *value
+	}
+
+	if index, ok := layout.Index(FieldSourcePrice); ok {
+		row[index] = r.getValueOrOriginal(index, r.SourcePrice)
+	}
+	if index, ok := layout.Index(BybitPriceField); ok {
+		row[index] = r.priceCell(index, r.BybitPrice)
+	}
+	for _, horizon := range layout.Horizons() {
+		if index, ok := layout.Index(horizon.Key); ok {
+			row[index] = r.priceCell(index, r.Price(horizon.Key))
+		}
+	}
+
+	return row
+}
+
+// HasColumn проверяет, есть ли в листе записи колонка для поля
+func (r *CoinPriceRecord) HasColumn(field string) bool {
+	if r.layout == nil {
+		return true
+	}
+
+	_, ok := r.layout.Index(field)
+	return ok
+}
+
+// textFields возвращает текстовые поля записи
+func (r *CoinPriceRecord) textFields() map[string]*string {
+	return map[string]*string{
+		FieldDate:      &r.Date,
+		FieldTime:      &r.Time,
+		FieldSource:    &r.Source,
+		FieldCoin:      &r.Coin,
+		FieldDirection: &r.Direction,
+		FieldTimezone:  &r.Timezone,
+	}
+}
+
+// horizons возвращает горизонты листа записи
+func (r *CoinPriceRecord) horizons() []Horizon {
+	if r.layout == nil {
+		return DefaultHorizons()
+	}
+
+	return r.layout.Horizons()
 }
 
 // getValueOrOriginal возвращает новое значение если оно != 0, иначе оригинальное из таблицы
@@ -223,3 +409,22 @@ func (r *CoinPriceRecord) getValueOrOriginal(index int, currentValue float64) in
 	// Если оригинальной строки нет, возвращаем пустую строку
 	return ""
 }
+
+// priceCell возвращает ячейку для значения цены
+// Для пустого или не изменившегося значения возвращается исходная ячейка из таблицы.
+// Цена, записанная в ячейку текстом ("45000.5", "$1,234.50"), возвращается числом:
+// иначе к ячейке не применится числовой формат колонки.
+func (r *CoinPriceRecord) priceCell(index int, value PriceValue) interface{} {
+	if r.originalRow != nil && index < len(r.originalRow) && r.originalRow[index] != nil &&
+		(value.State == PriceEmpty || ParsePriceValue(r.originalRow[index]) == value && !isTextNumber(r.originalRow[index], value)) {
+		return r.originalRow[index]
+	}
+
+	return value.Cell()
+}
+
+// isTextNumber проверяет, что заполненная цена записана в ячейке текстом, а не числом
+func isTextNumber(cell interface{}, value PriceValue) bool {
+	_, isText := cell.(string)
+	return isText && value.State == PriceFilled
+}
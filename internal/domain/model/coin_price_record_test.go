@@ -10,24 +10,24 @@ import (
 func TestParseFromRow(t *testing.T) {
 	t.Run("Полная строка с данными", func(t *testing.T) {
 		row := []interface{}{
-			"29.12.2025",        // Дата
-			"10:30:00",          // Время
-			"Binance",           // Источник
-			"BTC",               // Монета
-			"UP",                // Направление
-			"45000.50",          // Цена в источнике
-			"45010.00",          // Цена на Bybit
-			"45100.00",          // 10 мин
-			"45200.00",          // 30 мин
-			"45300.00",          // 1 час
-			"45400.00",          // 2 часа
-			"45500.00",          // 6 часов
-			"45600.00",          // 12 часов
-			"45700.00",          // 24 часа
-			"46000.00",          // 3 дня
-			"46500.00",          // 5 дней
-			"47000.00",          // 7 дней
-			"48000.00",          // 1 месяц
+			"29.12.2025", // Дата
+			"10:30:00",   // Время
+			"Binance",    // Источник
+			"BTC",        // Монета
+			"UP",         // Направление
+			"45000.50",   // Цена в источнике
+			"45010.00",   // Цена на Bybit
+			"45100.00",   // 10 мин
+			"45200.00",   // 30 мин
+			"45300.00",   // 1 час
+			"45400.00",   // 2 часа
+			"45500.00",   // 6 часов
+			"45600.00",   // 12 часов
+			"45700.00",   // 24 часа
+			"46000.00",   // 3 дня
+			"46500.00",   // 5 дней
+			"47000.00",   // 7 дней
+			"48000.00",   // 1 месяц
 		}
 
 		record, err := ParseFromRow(row)
@@ -40,9 +40,9 @@ func TestParseFromRow(t *testing.T) {
 		assert.Equal(t, "BTC", record.Coin)
 		assert.Equal(t, "UP", record.Direction)
 		assert.Equal(t, 45000.50, record.SourcePrice)
-		assert.Equal(t, 45010.00, record.BybitPrice)
-		assert.Equal(t, 45100.00, record.Price10Min)
-		assert.Equal(t, 48000.00, record.Price1Month)
+		assert.Equal(t, NewPriceValue(45010.00), record.BybitPrice)
+		assert.Equal(t, NewPriceValue(45100.00), record.Price("10m"))
+		assert.Equal(t, NewPriceValue(48000.00), record.Price("1M"))
 	})
 
 	t.Run("Строка с пустыми ценами", func(t *testing.T) {
@@ -64,8 +64,8 @@ func TestParseFromRow(t *testing.T) {
 
 		assert.Equal(t, "BTC", record.Coin)
 		assert.Equal(t, 45000.50, record.SourcePrice)
-		assert.Equal(t, 0.0, record.BybitPrice)
-		assert.Equal(t, 0.0, record.Price10Min)
+		assert.Equal(t, PriceEmpty, record.BybitPrice.State)
+		assert.Equal(t, PriceEmpty, record.Price("10m").State)
 	})
 
 	t.Run("Минимальная строка", func(t *testing.T) {
@@ -114,7 +114,7 @@ func TestCoinPriceRecord_String(t *testing.T) {
 		Coin:        "BTC",
 		Direction:   "UP",
 		SourcePrice: 45000.50,
-		BybitPrice:  45010.00,
+		BybitPrice:  NewPriceValue(45010.00),
 	}
 
 	str := record.String()
@@ -152,36 +152,65 @@ func TestCoinPriceRecord_TryParseDateTime(t *testing.T) {
 
 func TestCoinPriceRecord_GetPriceFields(t *testing.T) {
 	record := &CoinPriceRecord{
-		Price10Min:  100.0,
-		Price30Min:  200.0,
-		Price1Hour:  300.0,
-		Price1Month: 1000.0,
+		Prices: map[string]PriceValue{
+			"10m": NewPriceValue(100.0),
+			"30m": NewPriceValue(200.0),
+			"1h":  NewPriceValue(300.0),
+			"1M":  NewPriceValue(1000.0),
+		},
 	}
 
 	fields := record.GetPriceFields()
 	assert.Equal(t, 11, len(fields))
 
 	// Проверяем первое поле
-	assert.Equal(t, "Price10Min", fields[0].Name)
-	assert.Equal(t, 10*time.Minute, fields[0].Duration)
-	assert.Equal(t, 100.0, *fields[0].Value)
-
-	// Проверяем последнее поле
-	assert.Equal(t, "Price1Month", fields[10].Name)
-	assert.Equal(t, 30*24*time.Hour, fields[10].Duration)
-	assert.Equal(t, 1000.0, *fields[10].Value)
+	assert.Equal(t, "10m", fields[0].Name)
+	assert.Equal(t, 10, fields[0].Horizon.Minutes)
+	assert.Equal(t, "Цена через 10 минут", fields[0].Horizon.Header())
+	assert.Equal(t, NewPriceValue(100.0), record.Price(fields[0].Name))
+
+	// Проверяем последнее поле: календарный месяц, а не 30 дней
+	assert.Equal(t, "1M", fields[10].Name)
+	assert.Equal(t, 1, fields[10].Horizon.Months)
+	assert.Equal(t, NewPriceValue(1000.0), record.Price(fields[10].Name))
+}
+
+func TestCoinPriceRecord_CustomHorizons(t *testing.T) {
+	horizons, err := ParseHorizons("4h=Цена через 4 часа;48h=Цена через 48 часов;14d=Цена через 14 дней;3M=Цена через 3 месяца")
+	assert.NoError(t, err)
+
+	header := []interface{}{"Дата", "Время", "Источник", "Монета", "Направление", "Цена через 4 часа", "Цена через 3 месяца"}
+	layout, err := NewSheetLayoutFromHeader(header, nil, horizons)
+	assert.NoError(t, err)
+
+	record, err := layout.ParseRow([]interface{}{"30.11.2025", "10:00", "ChannelA", "BTC", "long", 45000.0, ""})
+	assert.NoError(t, err)
+
+	fields := record.GetPriceFields()
+	assert.Equal(t, 4, len(fields))
+	assert.Equal(t, NewPriceValue(45000.0), record.Price("4h"))
+
+	// 3 календарных месяца от 30 ноября - 28 февраля (в феврале нет 30 числа)
+	target, err := record.TargetTime(fields[3])
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 2, 28, 10, 0, 0, 0, target.Location()), target)
+
+	record.SetPrice("3M", NewPriceValue(52000))
+	row := record.ToRow()
+	assert.Equal(t, 52000.0, row[6])
+	assert.Equal(t, 45000.0, row[5]) // Значение не изменилось - исходная ячейка
 }
 
 func TestCoinPriceRecord_ShouldFetchPrice(t *testing.T) {
 	t.Run("Цена уже заполнена - не нужно получать", func(t *testing.T) {
 		record := &CoinPriceRecord{
-			Date:       "29.12.2025",
-			Time:       "10:00:00",
-			Price10Min: 45000.0,
+			Date:   "29.12.2025",
+			Time:   "10:00:00",
+			Prices: map[string]PriceValue{"10m": NewPriceValue(45000.0)},
 		}
 
 		fields := record.GetPriceFields()
-		field := fields[0] // Price10Min
+		field := fields[0] // 10m
 
 		// Время прошло, но цена уже есть
 		now := time.Date(2025, 12, 29, 11, 0, 0, 0, time.FixedZone("GMT+7", 7*60*60))
@@ -193,13 +222,13 @@ func TestCoinPriceRecord_ShouldFetchPrice(t *testing.T) {
 
 	t.Run("Время наступило, цена пустая - нужно получить", func(t *testing.T) {
 		record := &CoinPriceRecord{
-			Date:       "29.12.2025",
-			Time:       "10:00:00",
-			Price10Min: 0, // Пустая
+			Date: "29.12.2025",
+			Time: "10:00:00",
+			// Цены пустые
 		}
 
 		fields := record.GetPriceFields()
-		field := fields[0] // Price10Min
+		field := fields[0] // 10m
 
 		// Прошло 15 минут (больше чем 10)
 		now := time.Date(2025, 12, 29, 10, 15, 0, 0, time.FixedZone("GMT+7", 7*60*60))
@@ -211,13 +240,13 @@ func TestCoinPriceRecord_ShouldFetchPrice(t *testing.T) {
 
 	t.Run("Время еще не наступило - не нужно получать", func(t *testing.T) {
 		record := &CoinPriceRecord{
-			Date:       "29.12.2025",
-			Time:       "10:00:00",
-			Price10Min: 0, // Пустая
+			Date: "29.12.2025",
+			Time: "10:00:00",
+			// Цены пустые
 		}
 
 		fields := record.GetPriceFields()
-		field := fields[0] // Price10Min
+		field := fields[0] // 10m
 
 		// Прошло только 5 минут (меньше чем 10)
 		now := time.Date(2025, 12, 29, 10, 5, 0, 0, time.FixedZone("GMT+7", 7*60*60))
@@ -231,24 +260,26 @@ func TestCoinPriceRecord_ShouldFetchPrice(t *testing.T) {
 func TestCoinPriceRecord_ToRow(t *testing.T) {
 	t.Run("Конвертация полной записи", func(t *testing.T) {
 		record := &CoinPriceRecord{
-			Date:         "29.12.2025",
-			Time:         "10:30:00",
-			Source:       "Binance",
-			Coin:         "BTC",
-			Direction:    "UP",
-			SourcePrice:  45000.50,
-			BybitPrice:   45010.00,
-			Price10Min:   45100.00,
-			Price30Min:   45200.00,
-			Price1Hour:   45300.00,
-			Price2Hours:  45400.00,
-			Price6Hours:  45500.00,
-			Price12Hours: 45600.00,
-			Price24Hours: 45700.00,
-			Price3Days:   46000.00,
-			Price5Days:   46500.00,
-			Price7Days:   47000.00,
-			Price1Month:  48000.00,
+			Date:        "29.12.2025",
+			Time:        "10:30:00",
+			Source:      "Binance",
+			Coin:        "BTC",
+			Direction:   "UP",
+			SourcePrice: 45000.50,
+			BybitPrice:  NewPriceValue(45010.00),
+			Prices: map[string]PriceValue{
+				"10m": NewPriceValue(45100.00),
+				"30m": NewPriceValue(45200.00),
+				"1h":  NewPriceValue(45300.00),
+				"2h":  NewPriceValue(45400.00),
+				"6h":  NewPriceValue(45500.00),
+				"12h": NewPriceValue(45600.00),
+				"24h": NewPriceValue(45700.00),
+				"3d":  NewPriceValue(46000.00),
+				"5d":  NewPriceValue(46500.00),
+				"7d":  NewPriceValue(47000.00),
+				"1M":  NewPriceValue(48000.00),
+			},
 		}
 
 		row := record.ToRow()
@@ -275,22 +306,21 @@ func TestCoinPriceRecord_ToRow(t *testing.T) {
 			"UP",
 			45000.50,
 			45010.00,
-			45100.00, // Оригинальная цена Price10Min
-			45200.00, // Оригинальная цена Price30Min
+			45100.00, // Оригинальная цена 10m
+			45200.00, // Оригинальная цена 30m
 			"",       // Пусто в таблице
 		}
 
 		record := &CoinPriceRecord{
-			Date:         "29.12.2025",
-			Time:         "10:30:00",
-			Source:       "Binance",
-			Coin:         "BTC",
-			Direction:    "UP",
-			SourcePrice:  45000.50,
-			BybitPrice:   45010.00,
-			Price10Min:   0, // Не удалось получить новую цену
-			Price30Min:   0, // Не удалось получить новую цену
-			originalRow:  originalRow,
+			Date:        "29.12.2025",
+			Time:        "10:30:00",
+			Source:      "Binance",
+			Coin:        "BTC",
+			Direction:   "UP",
+			SourcePrice: 45000.50,
+			BybitPrice:  NewPriceValue(45010.00),
+			Prices:      map[string]PriceValue{}, // Не удалось получить новые цены
+			originalRow: originalRow,
 		}
 
 		row := record.ToRow()
@@ -299,9 +329,9 @@ func TestCoinPriceRecord_ToRow(t *testing.T) {
 		assert.Equal(t, "BTC", row[3])
 		assert.Equal(t, 45010.00, row[6])
 		// Должны вернуться оригинальные значения из таблицы
-		assert.Equal(t, 45100.00, row[7])  // Price10Min - оригинальное значение
-		assert.Equal(t, 45200.00, row[8])  // Price30Min - оригинальное значение
-		assert.Equal(t, "", row[9])        // Price1Hour - было пусто, осталось пусто
+		assert.Equal(t, 45100.00, row[7]) // 10m - оригинальное значение
+		assert.Equal(t, 45200.00, row[8]) // 30m - оригинальное значение
+		assert.Equal(t, "", row[9])       // 1h - было пусто, осталось пусто
 	})
 
 	t.Run("Конвертация записи без оригинальной строки", func(t *testing.T) {
@@ -312,7 +342,7 @@ func TestCoinPriceRecord_ToRow(t *testing.T) {
 			Coin:        "BTC",
 			Direction:   "UP",
 			SourcePrice: 45000.50,
-			BybitPrice:  45010.00,
+			BybitPrice:  NewPriceValue(45010.00),
 			// Остальные цены = 0, originalRow = nil
 		}
 
@@ -322,9 +352,127 @@ func TestCoinPriceRecord_ToRow(t *testing.T) {
 		assert.Equal(t, "BTC", row[3])
 		assert.Equal(t, 45010.00, row[6])
 		// Без оригинальной строки пустые значения станут пустыми строками
-		assert.Equal(t, "", row[7])  // Price10Min
-		assert.Equal(t, "", row[8])  // Price30Min
-		assert.Equal(t, "", row[17]) // Price1Month
+		assert.Equal(t, "", row[7])  // 10m
+		assert.Equal(t, "", row[8])  // 30m
+		assert.Equal(t, "", row[17]) // 1M
 	})
 }
 
+func TestCoinPriceRecord_PriceStatesRoundTrip(t *testing.T) {
+	row := []interface{}{
+		"29.12.2025", "10:30:00", "Binance", "BTC", "UP", 45000.5,
+		"N/A",       // Цена на Bybit
+		45100.0,     // 10m
+		"ERR",       // 30m
+		"*45300",    // 1h
+		"WAIT",      // 2h
+		"делистинг", // 6h
+		"",          // 12h
+	}
+
+	record, err := ParseFromRow(row)
+	assert.NoError(t, err)
+
+	assert.Equal(t, PriceNotAvailable, record.BybitPrice.State)
+	assert.Equal(t, PriceFilled, record.Price("10m").State)
+	assert.Equal(t, PriceFetchFailed, record.Price("30m").State)
+	assert.Equal(t, PriceValue{State: PriceManual, Price: 45300}, record.Price("1h"))
+	assert.Equal(t, PriceNotDue, record.Price("2h").State)
+	assert.Equal(t, PriceManual, record.Price("6h").State)
+	assert.Equal(t, PriceEmpty, record.Price("12h").State)
+
+	// Без изменений ячейки цен записываются как были прочитаны
+	assert.Equal(t, row[6:], record.ToRow()[6:len(row)])
+	assert.Empty(t, record.Changes())
+
+	record.SetPrice("30m", NewPriceValue(45200))
+	record.SetPrice("2h", PriceValue{State: PriceNotAvailable})
+	assert.Equal(t, []CellChange{
+		{Column: 9, Field: "30m", OldValue: "ERR", NewValue: 45200.0},
+		{Column: 11, Field: "2h", OldValue: "WAIT", NewValue: "N/A"},
+	}, record.Changes())
+}
+
+func TestCoinPriceRecord_ShouldFetchPriceStates(t *testing.T) {
+	now := time.Date(2025, 12, 29, 11, 0, 0, 0, time.FixedZone("GMT+7", 7*60*60))
+
+	for _, tt := range []struct {
+		value    PriceValue
+		expected bool
+	}{
+		{PriceValue{State: PriceNotDue}, true},
+		{PriceValue{State: PriceFetchFailed}, true},
+		{PriceValue{State: PriceNotAvailable}, false},
+		{PriceValue{State: PriceManual, Price: 45000}, false},
+		{NewPriceValue(0), false},
+	} {
+		t.Run(tt.value.State.String(), func(t *testing.T) {
+			record := &CoinPriceRecord{Date: "29.12.2025", Time: "10:00:00"}
+			record.SetPrice("10m", tt.value)
+
+			shouldFetch, err := record.ShouldFetchPrice(record.GetPriceFields()[0], now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, shouldFetch)
+		})
+	}
+}
+
+func TestCoinPriceRecord_NextDue(t *testing.T) {
+	location := time.FixedZone("GMT+7", 7*60*60)
+	record := &CoinPriceRecord{
+		Date:   "29.12.2025",
+		Time:   "10:00:00",
+		Prices: map[string]PriceValue{"30m": NewPriceValue(45000.0)},
+	}
+
+	t.Run("Ближайший не наступивший горизонт без цены", func(t *testing.T) {
+		// 10m уже наступил, 30m заполнен - следующий 1h
+		next, ok := record.NextDue(time.Date(2025, 12, 29, 10, 15, 0, 0, location))
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2025, 12, 29, 11, 0, 0, 0, location), next)
+	})
+
+	t.Run("Все горизонты наступили", func(t *testing.T) {
+		_, ok := record.NextDue(time.Date(2026, 3, 1, 0, 0, 0, 0, location))
+		assert.False(t, ok)
+	})
+
+	t.Run("Дату не удалось распарсить", func(t *testing.T) {
+		_, ok := (&CoinPriceRecord{Date: "вчера", Time: "10:00"}).NextDue(time.Now())
+		assert.False(t, ok)
+	})
+}
+
+func TestSheetLayout_NewRecord(t *testing.T) {
+	header := []interface{}{"Монета", "Дата", "Время", "Источник", "Направление", "Заметки", "Цена в источнике", "Цена через 1 час"}
+	layout, err := NewSheetLayoutFromHeader(header, nil, nil)
+	assert.NoError(t, err)
+
+	record := layout.NewRecord()
+	record.Coin = "BTC"
+	record.Source = "ChannelX"
+	record.Direction = "long"
+	record.SourcePrice = 45000
+	record.SetDateTime(time.Date(2025, 12, 29, 3, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, []interface{}{"BTC", "29.12.2025", "10:00:00", "ChannelX", "long", "", 45000.0, ""}, record.ToRow())
+
+	parsed, err := layout.ParseRow(record.ToRow())
+	assert.NoError(t, err)
+	parsedTime, err := parsed.TryParseDateTime()
+	assert.NoError(t, err)
+	assert.True(t, parsedTime.Equal(time.Date(2025, 12, 29, 3, 0, 0, 0, time.UTC)))
+}
+
+func TestParseSheetDate(t *testing.T) {
+	expected := time.Date(2025, 12, 29, 0, 0, 0, 0, DefaultSheetTimezone)
+
+	for _, value := range []string{"29.12.2025", "2025-12-29", " 29.12.2025 "} {
+		got, err := ParseSheetDate(value, DefaultSheetTimezone)
+		assert.NoError(t, err, value)
+		assert.True(t, expected.Equal(got), value)
+	}
+
+	_, err := ParseSheetDate("12/29/2025", DefaultSheetTimezone)
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Horizon временной горизонт, на который фиксируется цена после сигнала
+// Минуты и часы - фиксированный интервал, дни, недели и месяцы - календарные
+// (через месяц после 31 января - 28/29 февраля).
+type Horizon struct {
+	Key     string   // Ключ горизонта, например "10m", "4h", "14d", "3M"
+	Headers []string // Заголовки колонки в листе (первый - основной)
+	Minutes int      // Фиксированный интервал в минутах
+	Days    int      // Календарные дни
+	Months  int      // Календарные месяцы
+}
+
+// DefaultHorizons горизонты исходной таблицы
+func DefaultHorizons() []Horizon {
+	return []Horizon{
+		mustParseHorizon("10m=Цена через 10 минут|Price 10 min|Price10Min"),
+		mustParseHorizon("30m=Цена через 30 минут|Price 30 min|Price30Min"),
+		mustParseHorizon("1h=Цена через 1 час|Price 1 hour|Price1Hour"),
+		mustParseHorizon("2h=Цена через 2 часа|Price 2 hours|Price2Hours"),
+		mustParseHorizon("6h=Цена через 6 часов|Price 6 hours|Price6Hours"),
+		mustParseHorizon("12h=Цена через 12 часов|Price 12 hours|Price12Hours"),
+		mustParseHorizon("24h=Цена через 24 часов|Цена через 24 часа|Price 24 hours|Price24Hours"),
+		mustParseHorizon("3d=Цена через 3 дня|Price 3 days|Price3Days"),
+		mustParseHorizon("5d=Цена через 5 дней|Price 5 days|Price5Days"),
+		mustParseHorizon("7d=Цена через 7 дней|Price 7 days|Price7Days"),
+		mustParseHorizon("1M=Цена через 1 месяц|Price 1 month|Price1Month"),
+	}
+}
+
+// ParseHorizons разбирает список горизонтов вида "10m=Цена через 10 минут;4h=Цена через 4 часа;3M=Цена через 3 месяца"
+func ParseHorizons(spec string) ([]Horizon, error) {
+	var horizons []Horizon
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(spec, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		horizon, err := ParseHorizon(item)
+		if err != nil {
+			return nil, err
+		}
+		if seen[horizon.Key] {
+			return nil, fmt.Errorf("duplicate horizon %q", horizon.Key)
+		}
+		seen[horizon.Key] = true
+
+		horizons = append(horizons, horizon)
+	}
+
+	return horizons, nil
+}
+
+// ParseHorizon разбирает горизонт вида "<число><единица>=Заголовок[|Заголовок...]"
+// Единицы: m - минуты, h - часы, d - календарные дни, w - недели, M - календарные месяцы
+func ParseHorizon(spec string) (Horizon, error) {
+	key, headersStr, ok := strings.Cut(spec, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return Horizon{}, fmt.Errorf("invalid horizon %q: expected <amount><unit>=Header", spec)
+	}
+
+	horizon := Horizon{Key: key}
+	for _, header := range strings.Split(headersStr, "|") {
+		if header = strings.TrimSpace(header); header != "" {
+			horizon.Headers = append(horizon.Headers, header)
+		}
+	}
+	if len(horizon.Headers) == 0 {
+		return Horizon{}, fmt.Errorf("invalid horizon %q: no column header", spec)
+	}
+
+	amount, err := strconv.Atoi(key[:len(key)-1])
+	if err != nil || amount <= 0 {
+		return Horizon{}, fmt.Errorf("invalid horizon %q: amount must be a positive integer", spec)
+	}
+
+	switch key[len(key)-1] {
+	case 'm':
+		horizon.Minutes = amount
+	case 'h':
+		horizon.Minutes = amount * 60
+	case 'd':
+		horizon.Days = amount
+	case 'w':
+		horizon.Days = amount * 7
+	case 'M':
+		horizon.Months = amount
+	default:
+		return Horizon{}, fmt.Errorf("invalid horizon %q: unknown unit %q (use m, h, d, w, M)", spec, key[len(key)-1:])
+	}
+
+	return horizon, nil
+}
+
+// Header возвращает основной заголовок колонки горизонта
+func (h Horizon) Header() string {
+	return h.Headers[0]
+}
+
+// Target возвращает момент, на который нужна цена горизонта для сигнала во время from
+func (h Horizon) Target(from time.Time) time.Time {
+	return addMonthsClamped(from, h.Months).AddDate(0, 0, h.Days).Add(time.Duration(h.Minutes) * time.Minute)
+}
+
+// addMonthsClamped прибавляет календарные месяцы; если в целевом месяце нет такого дня, берется последний день месяца
+func addMonthsClamped(t time.Time, months int) time.Time {
+	if months == 0 {
+		return t
+	}
+
+	firstOfMonth := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	target := firstOfMonth.AddDate(0, months, 0)
+	lastDay := target.AddDate(0, 1, -1).Day()
+
+	return target.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
+
+func mustParseHorizon(spec string) Horizon {
+	horizon, err := ParseHorizon(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return horizon
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHorizon(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Horizon
+	}{
+		{"10m=Цена через 10 минут", Horizon{Key: "10m", Headers: []string{"Цена через 10 минут"}, Minutes: 10}},
+		{"4h = Цена через 4 часа | Price 4h", Horizon{Key: "4h", Headers: []string{"Цена через 4 часа", "Price 4h"}, Minutes: 240}},
+		{"14d=Цена через 14 дней", Horizon{Key: "14d", Headers: []string{"Цена через 14 дней"}, Days: 14}},
+		{"2w=Цена через 2 недели", Horizon{Key: "2w", Headers: []string{"Цена через 2 недели"}, Days: 14}},
+		{"3M=Цена через 3 месяца", Horizon{Key: "3M", Headers: []string{"Цена через 3 месяца"}, Months: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			horizon, err := ParseHorizon(tt.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, horizon)
+		})
+	}
+
+	for _, spec := range []string{"10m", "10m=", "0h=Ноль", "10x=Цена", "h=Цена", "=Цена"} {
+		t.Run("Ошибка: "+spec, func(t *testing.T) {
+			_, err := ParseHorizon(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseHorizons(t *testing.T) {
+	horizons, err := ParseHorizons("4h=Цена через 4 часа; 48h=Цена через 48 часов;")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(horizons))
+	assert.Equal(t, "48h", horizons[1].Key)
+
+	_, err = ParseHorizons("4h=Цена через 4 часа;4h=Еще раз")
+	assert.Error(t, err)
+}
+
+func TestHorizon_Target(t *testing.T) {
+	location := time.FixedZone("GMT+7", 7*60*60)
+	from := time.Date(2026, 1, 31, 10, 0, 0, 0, location)
+
+	assert.Equal(t, time.Date(2026, 1, 31, 14, 0, 0, 0, location), mustParseHorizon("4h=4h").Target(from))
+	assert.Equal(t, time.Date(2026, 2, 14, 10, 0, 0, 0, location), mustParseHorizon("14d=14d").Target(from))
+
+	// Календарный месяц: после 31 января - 28 февраля, а не 2 или 3 марта
+	assert.Equal(t, time.Date(2026, 2, 28, 10, 0, 0, 0, location), mustParseHorizon("1M=1M").Target(from))
+	assert.Equal(t, time.Date(2026, 4, 30, 10, 0, 0, 0, location), mustParseHorizon("3M=3M").Target(from))
+	assert.Equal(t, time.Date(2026, 3, 15, 10, 0, 0, 0, location),
+		mustParseHorizon("1M=1M").Target(time.Date(2026, 2, 15, 10, 0, 0, 0, location)))
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// PricePoint представляет цену монеты в конкретный момент времени
+type PricePoint struct {
+	Price     float64   // Цена в USD
+	Timestamp time.Time // Фактическое время сэмпла у провайдера
+	Provider  string    // Провайдер, который вернул цену
+}
+
+// PriceSample полученная цена поля записи с происхождением: кто ее вернул, на какой момент она
+// запрашивалась, какого времени фактический сэмпл и когда цена была получена
+type PriceSample struct {
+	RecordKey   string // Ключ записи (CoinPriceRecord.Key)
+	Field       string // Поле записи (BybitPriceField или ключ горизонта)
+	Coin        string
+	Price       float64
+	Provider    string
+	RequestedAt time.Time // Момент, на который запрашивалась цена
+	SampledAt   time.Time // Фактическое время сэмпла у провайдера
+	FetchedAt   time.Time // Когда цена была получена
+}
+
+// NearestPricePoint возвращает сэмпл, ближайший к целевому времени
+// Если ближайший сэмпл отстоит от цели больше чем на tolerance, возвращается ошибка
+func NearestPricePoint(points []PricePoint, target time.Time, tolerance time.Duration) (PricePoint, error) {
+	if len(points) == 0 {
+		return PricePoint{}, fmt.Errorf("no price samples around %s", target.Format(time.RFC3339))
+	}
+
+	best := points[0]
+	bestDiff := absDuration(best.Timestamp.Sub(target))
+	for _, point := range points[1:] {
+		if diff := absDuration(point.Timestamp.Sub(target)); diff < bestDiff {
+			best = point
+			bestDiff = diff
+		}
+	}
+
+	if bestDiff > tolerance {
+		return PricePoint{}, fmt.Errorf(
+			"nearest price sample at %s is %s away from %s (tolerance %s)",
+			best.Timestamp.Format(time.RFC3339), bestDiff, target.Format(time.RFC3339), tolerance,
+		)
+	}
+
+	return best, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
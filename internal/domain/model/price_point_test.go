@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNearestPricePoint(t *testing.T) {
+	target := time.Date(2025, 12, 29, 10, 10, 0, 0, time.UTC)
+	points := []PricePoint{
+		{Price: 100, Timestamp: target.Add(-7 * time.Minute)},
+		{Price: 101, Timestamp: target.Add(-2 * time.Minute)},
+		{Price: 102, Timestamp: target.Add(3 * time.Minute)},
+	}
+
+	t.Run("Выбирается ближайший сэмпл", func(t *testing.T) {
+		point, err := NearestPricePoint(points, target, 5*time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, 101.0, point.Price)
+		assert.Equal(t, target.Add(-2*time.Minute), point.Timestamp)
+	})
+
+	t.Run("Сэмпл вне окна допуска", func(t *testing.T) {
+		_, err := NearestPricePoint(points, target.Add(time.Hour), 5*time.Minute)
+		assert.Error(t, err)
+	})
+
+	t.Run("Нет сэмплов", func(t *testing.T) {
+		_, err := NearestPricePoint(nil, target, 5*time.Minute)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PriceState состояние ячейки с ценой
+type PriceState int
+
+const (
+	// PriceEmpty ячейка пустая, цену еще не запрашивали
+	PriceEmpty PriceState = iota
+	// PriceFilled цена заполнена программой (или числом вручную)
+	PriceFilled
+	// PriceNotDue момент горизонта еще не наступил
+	PriceNotDue
+	// PriceFetchFailed не удалось получить цену (сеть, rate limit), в следующий запуск запрос повторится
+	PriceFetchFailed
+	// PriceNotAvailable ни один провайдер не знает монету или не имеет данных на нужный момент
+	PriceNotAvailable
+	// PriceManual значение введено вручную ("*45000" или произвольный текст), программа его не перезаписывает
+	PriceManual
+)
+
+// Представление состояний в ячейках листа
+const (
+	NotDueCell       = "WAIT"
+	FetchFailedCell  = "ERR"
+	NotAvailableCell = "N/A"
+	ManualPrefix     = "*"
+)
+
+func (s PriceState) String() string {
+	switch s {
+	case PriceEmpty:
+		return "empty"
+	case PriceFilled:
+		return "filled"
+	case PriceNotDue:
+		return "not-due"
+	case PriceFetchFailed:
+		return "fetch-failed"
+	case PriceNotAvailable:
+		return "not-available"
+	case PriceManual:
+		return "manual"
+	default:
+		return fmt.Sprintf("PriceState(%d)", int(s))
+	}
+}
+
+// PriceValue значение ячейки с ценой: состояние и цена (для заполненных и ручных значений)
+type PriceValue struct {
+	State PriceState
+	Price float64
+	// Text исходный текст ручного значения, которое не удалось распознать как число
+	Text string
+}
+
+// NewPriceValue заполненная цена
+func NewPriceValue(price float64) PriceValue {
+	return PriceValue{State: PriceFilled, Price: price}
+}
+
+// ParsePriceValue распознает значение ячейки листа
+// "" - пусто, "WAIT", "ERR", "N/A" - служебные состояния, "*45000" - ручная цена,
+// число - заполненная цена, любой другой текст считается ручным значением.
+func ParsePriceValue(cell interface{}) PriceValue {
+	switch value := cell.(type) {
+	case nil:
+		return PriceValue{}
+	case float64:
+		return NewPriceValue(value)
+	case int:
+		return NewPriceValue(float64(value))
+	}
+
+	text := strings.TrimSpace(fmt.Sprintf("%v", cell))
+	switch strings.ToUpper(text) {
+	case "":
+		return PriceValue{}
+	case NotDueCell:
+		return PriceValue{State: PriceNotDue}
+	case FetchFailedCell:
+		return PriceValue{State: PriceFetchFailed}
+	case NotAvailableCell, "NA", "#N/A":
+		return PriceValue{State: PriceNotAvailable}
+	}
+
+	if manual, ok := strings.CutPrefix(text, ManualPrefix); ok {
+		if price, err := ParseNumber(manual); err == nil {
+			return PriceValue{State: PriceManual, Price: price}
+		}
+	}
+
+	if price, err := ParseNumber(text); err == nil {
+		return NewPriceValue(price)
+	}
+
+	return PriceValue{State: PriceManual, Text: text}
+}
+
+// Cell возвращает представление значения в ячейке листа
+func (v PriceValue) Cell() interface{} {
+	switch v.State {
+	case PriceFilled:
+		return v.Price
+	case PriceNotDue:
+		return NotDueCell
+	case PriceFetchFailed:
+		return FetchFailedCell
+	case PriceNotAvailable:
+		return NotAvailableCell
+	case PriceManual:
+		if v.Text != "" {
+			return v.Text
+		}
+		return ManualPrefix + strconv.FormatFloat(v.Price, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// HasPrice проверяет, есть ли в значении цена (заполненная или введенная вручную)
+func (v PriceValue) HasPrice() bool {
+	return v.State == PriceFilled || (v.State == PriceManual && v.Text == "")
+}
+
+// NeedsFetch проверяет, нужно ли запрашивать цену для ячейки, когда наступит момент горизонта
+// Заполненные, ручные и недоступные значения не запрашиваются повторно
+func (v PriceValue) NeedsFetch() bool {
+	return v.State == PriceEmpty || v.State == PriceNotDue || v.State == PriceFetchFailed
+}
+
+// String возвращает значение для логов
+func (v PriceValue) String() string {
+	if v.State == PriceFilled {
+		return fmt.Sprintf("%.2f", v.Price)
+	}
+
+	return fmt.Sprintf("%v", v.Cell())
+}
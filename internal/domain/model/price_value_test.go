@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePriceValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		cell     interface{}
+		expected PriceValue
+	}{
+		{"Пустая ячейка", "", PriceValue{}},
+		{"Ячейки нет", nil, PriceValue{}},
+		{"Число строкой", "45000.50", NewPriceValue(45000.50)},
+		{"Число", 45000.50, NewPriceValue(45000.50)},
+		{"Ноль - это цена, а не пустая ячейка", "0", NewPriceValue(0)},
+		{"Еще рано", "WAIT", PriceValue{State: PriceNotDue}},
+		{"Ошибка запроса", " err ", PriceValue{State: PriceFetchFailed}},
+		{"Нет данных", "N/A", PriceValue{State: PriceNotAvailable}},
+		{"Нет данных (формула)", "#N/A", PriceValue{State: PriceNotAvailable}},
+		{"Ручная цена", "*45000", PriceValue{State: PriceManual, Price: 45000}},
+		{"Произвольный текст", "делистинг", PriceValue{State: PriceManual, Text: "делистинг"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParsePriceValue(tt.cell))
+		})
+	}
+}
+
+func TestPriceValue_CellRoundTrip(t *testing.T) {
+	values := []PriceValue{
+		{},
+		NewPriceValue(0.0046),
+		{State: PriceNotDue},
+		{State: PriceFetchFailed},
+		{State: PriceNotAvailable},
+		{State: PriceManual, Price: 45000.5},
+		{State: PriceManual, Text: "делистинг"},
+	}
+
+	for _, value := range values {
+		t.Run(value.State.String(), func(t *testing.T) {
+			assert.Equal(t, value, ParsePriceValue(value.Cell()))
+		})
+	}
+}
+
+func TestPriceValue_NeedsFetch(t *testing.T) {
+	assert.True(t, PriceValue{}.NeedsFetch())
+	assert.True(t, PriceValue{State: PriceNotDue}.NeedsFetch())
+	assert.True(t, PriceValue{State: PriceFetchFailed}.NeedsFetch())
+	assert.False(t, NewPriceValue(1).NeedsFetch())
+	assert.False(t, PriceValue{State: PriceNotAvailable}.NeedsFetch())
+	assert.False(t, PriceValue{State: PriceManual, Price: 1}.NeedsFetch())
+}
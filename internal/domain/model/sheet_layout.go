@@ -0,0 +1,259 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Поля записи, которые можно сопоставить колонкам листа
+const (
+	FieldDate        = "Date"
+	FieldTime        = "Time"
+	FieldSource      = "Source"
+	FieldCoin        = "Coin"
+	FieldDirection   = "Direction"
+	FieldSourcePrice = "SourcePrice"
+	FieldTimezone    = "Timezone"
+)
+
+// baseFieldOrder порядок основных колонок листа по умолчанию (как в исходной таблице)
+// За ними по умолчанию идут колонки горизонтов
+var baseFieldOrder = []string{
+	FieldDate, FieldTime, FieldSource, FieldCoin, FieldDirection, FieldSourcePrice, BybitPriceField,
+}
+
+// optionalFields поля, колонки которых есть не в каждом листе: их нет в раскладке по умолчанию,
+// и их отсутствие не считается пропущенной колонкой
+var optionalFields = []string{FieldTimezone}
+
+// requiredFields поля, без которых лист нельзя обработать
+var requiredFields = []string{FieldDate, FieldTime, FieldSource, FieldCoin, FieldDirection}
+
+// DefaultColumnAliases названия заголовков (русские и английские), по которым находятся основные колонки
+// Заголовки колонок горизонтов задаются в самих горизонтах (Horizon.Headers)
+func DefaultColumnAliases() map[string][]string {
+	return map[string][]string{
+		FieldDate:        {"Дата", "Date"},
+		FieldTime:        {"Время", "Time"},
+		FieldSource:      {"Источник", "Source"},
+		FieldCoin:        {"Монета", "Coin"},
+		FieldDirection:   {"Направление", "Direction"},
+		FieldSourcePrice: {"Цена в источнике", "Source Price"},
+		BybitPriceField:  {"Цена на Bybit", "Bybit Price"},
+		FieldTimezone:    {"Часовой пояс", "Timezone", "TZ"},
+	}
+}
+
+// SheetLayout соответствие полей записи колонкам листа
+// Поля горизонтов называются ключами горизонтов ("10m", "1M", ...)
+type SheetLayout struct {
+	columns  map[string]int // Поле → индекс колонки в строке (с 0)
+	fields   map[int]string // Индекс колонки → поле
+	width    int            // Количество колонок, которые занимает запись
+	horizons []Horizon      // Горизонты листа
+
+	timezones Timezones // Часовые пояса даты и времени сигналов
+}
+
+// SetTimezones задает часовые пояса, в которых читаются и записываются дата и время сигналов листа
+func (l *SheetLayout) SetTimezones(timezones Timezones) {
+	l.timezones = timezones
+}
+
+// Timezones возвращает часовые пояса листа
+func (l *SheetLayout) Timezones() Timezones {
+	return l.timezones
+}
+
+// DefaultSheetLayout раскладка колонок по умолчанию: Дата, Время, Источник, ... Цена через 1 месяц
+func DefaultSheetLayout() *SheetLayout {
+	return NewSheetLayout(DefaultHorizons())
+}
+
+// NewSheetLayout раскладка колонок в порядке по умолчанию: основные колонки, затем колонки горизонтов
+func NewSheetLayout(horizons []Horizon) *SheetLayout {
+	layout := newSheetLayout(horizons)
+	for index, field := range layout.allFields() {
+		layout.set(field, index)
+	}
+
+	return layout
+}
+
+// NewSheetLayoutFromHeader находит колонки по строке заголовков
+// aliases дополняет DefaultColumnAliases и заголовки горизонтов: поле → дополнительные названия заголовков.
+// horizons - горизонты листа (если не заданы, используются DefaultHorizons).
+// Колонки, которые не удалось сопоставить полю, при записи сохраняются как есть.
+// Если не найдена обязательная колонка, возвращается ошибка.
+func NewSheetLayoutFromHeader(header []interface{}, aliases map[string][]string, horizons []Horizon) (*SheetLayout, error) {
+	if len(horizons) == 0 {
+		horizons = DefaultHorizons()
+	}
+	layout := newSheetLayout(horizons)
+
+	allAliases := DefaultColumnAliases()
+	for _, horizon := range horizons {
+		allAliases[horizon.Key] = append([]string{}, horizon.Headers...)
+	}
+	for field, extra := range aliases {
+		if _, ok := allAliases[field]; !ok {
+			return nil, fmt.Errorf("unknown column field %q in aliases (known: %s)", field, strings.Join(layout.allFields(), ", "))
+		}
+		allAliases[field] = append(allAliases[field], extra...)
+	}
+
+	// Нормализованное название заголовка → поле
+	lookup := make(map[string]string)
+	for _, field := range append(layout.allFields(), optionalFields...) {
+		names := allAliases[field]
+		// Основные колонки можно назвать и именем поля ("BybitPrice"), колонки горизонтов - только заголовками
+		if slices.Contains(baseFieldOrder, field) || slices.Contains(optionalFields, field) {
+			names = append([]string{field}, names...)
+		}
+
+		for _, alias := range names {
+			if key := normalizeHeader(alias); key != "" {
+				if _, exists := lookup[key]; !exists {
+					lookup[key] = field
+				}
+			}
+		}
+	}
+
+	layout.width = len(header)
+	for index, cell := range header {
+		field, ok := lookup[normalizeHeader(fmt.Sprintf("%v", cell))]
+		if !ok {
+			continue
+		}
+		// При повторе заголовка используется первая колонка
+		if _, exists := layout.columns[field]; !exists {
+			layout.set(field, index)
+		}
+	}
+
+	var missing []string
+	for _, field := range requiredFields {
+		if _, ok := layout.columns[field]; !ok {
+			missing = append(missing, fmt.Sprintf("%s (%s)", field, strings.Join(allAliases[field], " / ")))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("required columns not found in header: %s", strings.Join(missing, ", "))
+	}
+
+	return layout, nil
+}
+
+// Index возвращает индекс колонки поля в строке
+func (l *SheetLayout) Index(field string) (int, bool) {
+	index, ok := l.columns[field]
+	return index, ok
+}
+
+// Field возвращает поле, сопоставленное колонке (пустая строка для неизвестных колонок)
+func (l *SheetLayout) Field(index int) string {
+	return l.fields[index]
+}
+
+// Width возвращает количество колонок, которые занимает запись
+func (l *SheetLayout) Width() int {
+	return l.width
+}
+
+// Horizons возвращает горизонты листа
+func (l *SheetLayout) Horizons() []Horizon {
+	return l.horizons
+}
+
+// Header возвращает строку заголовков для раскладки: основное название каждой колонки
+// Колонки, не сопоставленные полям, остаются пустыми.
+func (l *SheetLayout) Header() []interface{} {
+	names := DefaultColumnAliases()
+	for _, horizon := range l.horizons {
+		names[horizon.Key] = []string{horizon.Header()}
+	}
+
+	header := make([]interface{}, l.width)
+	for index := range header {
+		header[index] = ""
+		if field, ok := l.fields[index]; ok && len(names[field]) > 0 {
+			header[index] = names[field][0]
+		}
+	}
+
+	return header
+}
+
+// MissingFields возвращает поля, для которых в листе нет колонки
+func (l *SheetLayout) MissingFields() []string {
+	var missing []string
+	for _, field := range l.allFields() {
+		if _, ok := l.columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+
+	return missing
+}
+
+// minRowLength минимальная длина строки, чтобы в ней были все обязательные колонки
+func (l *SheetLayout) minRowLength() int {
+	length := 0
+	for _, field := range requiredFields {
+		if index, ok := l.columns[field]; ok && index+1 > length {
+			length = index + 1
+		}
+	}
+
+	return length
+}
+
+// Fields возвращает поля, сопоставленные колонкам, в порядке колонок
+func (l *SheetLayout) Fields() []string {
+	indexes := make([]int, 0, len(l.fields))
+	for index := range l.fields {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	fields := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		fields = append(fields, l.fields[index])
+	}
+
+	return fields
+}
+
+func newSheetLayout(horizons []Horizon) *SheetLayout {
+	return &SheetLayout{
+		columns:  make(map[string]int),
+		fields:   make(map[int]string),
+		horizons: horizons,
+	}
+}
+
+// allFields возвращает все поля листа: основные, затем горизонты
+func (l *SheetLayout) allFields() []string {
+	fields := append([]string{}, baseFieldOrder...)
+	for _, horizon := range l.horizons {
+		fields = append(fields, horizon.Key)
+	}
+
+	return fields
+}
+
+func (l *SheetLayout) set(field string, index int) {
+	l.columns[field] = index
+	l.fields[index] = field
+	if index+1 > l.width {
+		l.width = index + 1
+	}
+}
+
+// normalizeHeader приводит заголовок к виду для сравнения: без регистра и лишних пробелов
+func normalizeHeader(header string) string {
+	return strings.ToLower(strings.Join(strings.Fields(header), " "))
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSheetLayoutFromHeader(t *testing.T) {
+	t.Run("Колонки в другом порядке и лишние колонки", func(t *testing.T) {
+		header := []interface{}{"Монета", "Заметки", " дата ", "Время", "Источник", "Направление", "Цена через 1 час"}
+
+		layout, err := NewSheetLayoutFromHeader(header, nil, nil)
+		require.NoError(t, err)
+
+		index, ok := layout.Index(FieldCoin)
+		assert.True(t, ok)
+		assert.Equal(t, 0, index)
+
+		index, ok = layout.Index(FieldDate)
+		assert.True(t, ok)
+		assert.Equal(t, 2, index)
+
+		assert.Equal(t, "", layout.Field(1))
+		assert.Equal(t, "1h", layout.Field(6))
+		assert.Contains(t, layout.MissingFields(), BybitPriceField)
+		assert.Equal(t, 7, layout.Width())
+	})
+
+	t.Run("Дополнительные названия заголовков из конфига", func(t *testing.T) {
+		header := []interface{}{"Дата", "Время", "Канал", "Тикер", "Направление"}
+
+		layout, err := NewSheetLayoutFromHeader(header, map[string][]string{
+			FieldSource: {"Канал"},
+			FieldCoin:   {"Тикер"},
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{FieldDate, FieldTime, FieldSource, FieldCoin, FieldDirection}, layout.Fields())
+	})
+
+	t.Run("Нет обязательной колонки", func(t *testing.T) {
+		_, err := NewSheetLayoutFromHeader([]interface{}{"Дата", "Время", "Источник", "Направление"}, nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Coin")
+	})
+
+	t.Run("Неизвестное поле в конфиге", func(t *testing.T) {
+		_, err := NewSheetLayoutFromHeader([]interface{}{"Дата"}, map[string][]string{"Ticker": {"Тикер"}}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestSheetLayout_RoundTrip(t *testing.T) {
+	header := []interface{}{"Монета", "Заметки", "Дата", "Время", "Источник", "Направление", "Цена на Bybit"}
+	layout, err := NewSheetLayoutFromHeader(header, nil, nil)
+	require.NoError(t, err)
+
+	row := []interface{}{"XVG", "держим до пятницы", "29.12.2025", "10:30", "ChannelX", "long", ""}
+	record, err := layout.ParseRow(row)
+	require.NoError(t, err)
+
+	assert.Equal(t, "XVG", record.Coin)
+	assert.Equal(t, "29.12.2025", record.Date)
+	assert.Equal(t, "ChannelX", record.Source)
+
+	record.BybitPrice = NewPriceValue(0.0046)
+
+	// Неизвестная колонка "Заметки" сохраняется как есть
+	assert.Equal(t, []interface{}{"XVG", "держим до пятницы", "29.12.2025", "10:30", "ChannelX", "long", 0.0046}, record.ToRow())
+
+	_, err = layout.ParseRow([]interface{}{"XVG", "", "29.12.2025"})
+	assert.Error(t, err)
+}
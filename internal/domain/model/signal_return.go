@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Направления сигнала: знак доходности
+const (
+	DirectionLong  = 1
+	DirectionShort = -1
+)
+
+// directionNames значения колонки "Направление" (без учета регистра)
+var directionNames = map[string]int{
+	"long":    DirectionLong,
+	"buy":     DirectionLong,
+	"лонг":    DirectionLong,
+	"покупка": DirectionLong,
+	"short":   DirectionShort,
+	"sell":    DirectionShort,
+	"шорт":    DirectionShort,
+	"продажа": DirectionShort,
+}
+
+// ParseDirection возвращает знак направления сигнала: DirectionLong или DirectionShort
+// false - направление не распознано
+func ParseDirection(direction string) (int, bool) {
+	sign, ok := directionNames[strings.ToLower(strings.TrimSpace(direction))]
+	return sign, ok
+}
+
+// EntryBasis какая цена считается ценой входа сигнала
+type EntryBasis string
+
+const (
+	EntryAuto   EntryBasis = "auto"   // Цена в источнике, а если ее нет - цена на Bybit
+	EntrySource EntryBasis = "source" // Только цена в источнике
+	EntryBybit  EntryBasis = "bybit"  // Только цена на Bybit
+)
+
+// ParseEntryBasis разбирает способ выбора цены входа: auto, source или bybit
+func ParseEntryBasis(value string) (EntryBasis, error) {
+	switch basis := EntryBasis(strings.ToLower(strings.TrimSpace(value))); basis {
+	case EntryAuto, EntrySource, EntryBybit:
+		return basis, nil
+	default:
+		return "", fmt.Errorf("unknown entry price %q (use %s, %s or %s)", value, EntryAuto, EntrySource, EntryBybit)
+	}
+}
+
+// EntryPrice возвращает цену входа сигнала
+// Если цена в источнике не распознана, цены входа нет и для EntryAuto: доходность от цены Bybit
+// выдавалась бы за доходность от цены в источнике.
+func (r *CoinPriceRecord) EntryPrice(basis EntryBasis) (float64, bool) {
+	if basis != EntryBybit && r.SourcePriceErr != nil {
+		return 0, false
+	}
+	if basis != EntryBybit && r.SourcePrice > 0 {
+		return r.SourcePrice, true
+	}
+	if basis != EntrySource && r.BybitPrice.HasPrice() && r.BybitPrice.Price > 0 {
+		return r.BybitPrice.Price, true
+	}
+
+	return 0, false
+}
+
+// Return возвращает доходность сигнала на горизонте в процентах с учетом направления:
+// для short падение цены - положительная доходность
+// false - нет цены входа, цены на горизонте или направление не распознано
+func (r *CoinPriceRecord) Return(horizonKey string, basis EntryBasis) (float64, bool) {
+	sign, ok := ParseDirection(r.Direction)
+	if !ok {
+		return 0, false
+	}
+
+	entry, ok := r.EntryPrice(basis)
+	if !ok {
+		return 0, false
+	}
+
+	value := r.Price(horizonKey)
+	if !value.HasPrice() {
+		return 0, false
+	}
+
+	return float64(sign) * (value.Price - entry) / entry * 100, true
+}
+
+// IsComplete проверяет, что цены всех горизонтов, колонки которых есть в листе, больше не нужно запрашивать
+func (r *CoinPriceRecord) IsComplete() bool {
+	for _, field := range r.GetPriceFields() {
+		if r.HasColumn(field.Name) && r.Price(field.Name).NeedsFetch() {
+			return false
+		}
+	}
+
+	return true
+}
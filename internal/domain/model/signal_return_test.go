@@ -0,0 +1,93 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDirection(t *testing.T) {
+	for direction, expected := range map[string]int{
+		"long":  DirectionLong,
+		" BUY ": DirectionLong,
+		"Лонг":  DirectionLong,
+		"short": DirectionShort,
+		"Шорт":  DirectionShort,
+		"SELL":  DirectionShort,
+	} {
+		sign, ok := ParseDirection(direction)
+		assert.True(t, ok, direction)
+		assert.Equal(t, expected, sign, direction)
+	}
+
+	_, ok := ParseDirection("hold")
+	assert.False(t, ok)
+}
+
+func TestCoinPriceRecord_Return(t *testing.T) {
+	t.Run("Long - рост цены положительный", func(t *testing.T) {
+		record := &CoinPriceRecord{Direction: "long", SourcePrice: 100}
+		record.SetPrice("1h", NewPriceValue(110))
+
+		ret, ok := record.Return("1h", EntryAuto)
+		assert.True(t, ok)
+		assert.InDelta(t, 10.0, ret, 1e-9)
+	})
+
+	t.Run("Short - падение цены положительное", func(t *testing.T) {
+		record := &CoinPriceRecord{Direction: "short", SourcePrice: 100}
+		record.SetPrice("1h", NewPriceValue(95))
+
+		ret, ok := record.Return("1h", EntryAuto)
+		assert.True(t, ok)
+		assert.InDelta(t, 5.0, ret, 1e-9)
+	})
+
+	t.Run("Без цены в источнике - от цены на Bybit", func(t *testing.T) {
+		record := &CoinPriceRecord{Direction: "long", BybitPrice: NewPriceValue(200)}
+		record.SetPrice("1h", PriceValue{State: PriceManual, Price: 190})
+
+		ret, ok := record.Return("1h", EntryAuto)
+		assert.True(t, ok)
+		assert.InDelta(t, -5.0, ret, 1e-9)
+	})
+
+	t.Run("Нет цены на горизонте", func(t *testing.T) {
+		record := &CoinPriceRecord{Direction: "long", SourcePrice: 100}
+		record.SetPrice("1h", PriceValue{State: PriceNotAvailable})
+
+		_, ok := record.Return("1h", EntryAuto)
+		assert.False(t, ok)
+	})
+}
+
+func TestCoinPriceRecord_EntryPrice(t *testing.T) {
+	record := &CoinPriceRecord{SourcePrice: 100, BybitPrice: NewPriceValue(101)}
+
+	for basis, expected := range map[EntryBasis]float64{EntryAuto: 100, EntrySource: 100, EntryBybit: 101} {
+		entry, ok := record.EntryPrice(basis)
+		assert.True(t, ok, basis)
+		assert.Equal(t, expected, entry, basis)
+	}
+
+	record.SourcePrice = 0
+	_, ok := record.EntryPrice(EntrySource)
+	assert.False(t, ok)
+
+	_, err := ParseEntryBasis("mid")
+	assert.Error(t, err)
+}
+
+func TestCoinPriceRecord_IsComplete(t *testing.T) {
+	record := &CoinPriceRecord{}
+	for _, field := range record.GetPriceFields() {
+		record.SetPrice(field.Name, NewPriceValue(1))
+	}
+	assert.True(t, record.IsComplete())
+
+	record.SetPrice("1M", PriceValue{State: PriceFetchFailed})
+	assert.False(t, record.IsComplete())
+
+	record.SetPrice("1M", PriceValue{State: PriceNotAvailable})
+	assert.True(t, record.IsComplete())
+}
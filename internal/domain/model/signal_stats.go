@@ -0,0 +1,64 @@
+package model
+
+import "sort"
+
+// HorizonStats результаты сигналов на одном горизонте
+type HorizonStats struct {
+	Horizon Horizon
+	Count   int     // Сколько сигналов имеют доходность на горизонте
+	Wins    int     // Сколько из них с положительной доходностью
+	WinRate float64 // Доля выигрышных сигналов, %
+	Mean    float64 // Средняя доходность, %
+	Median  float64 // Медианная доходность, %
+}
+
+// ComputeHorizonStats считает доходность сигналов с учетом направления на каждом горизонте
+// Сигналы без цены входа, без цены на горизонте или с нераспознанным направлением не учитываются.
+func ComputeHorizonStats(records []*CoinPriceRecord, horizons []Horizon, basis EntryBasis) []HorizonStats {
+	stats := make([]HorizonStats, 0, len(horizons))
+
+	for _, horizon := range horizons {
+		var returns []float64
+		for _, record := range records {
+			if ret, ok := record.Return(horizon.Key, basis); ok {
+				returns = append(returns, ret)
+			}
+		}
+
+		stats = append(stats, newHorizonStats(horizon, returns))
+	}
+
+	return stats
+}
+
+func newHorizonStats(horizon Horizon, returns []float64) HorizonStats {
+	stats := HorizonStats{Horizon: horizon, Count: len(returns)}
+	if len(returns) == 0 {
+		return stats
+	}
+
+	var sum float64
+	for _, ret := range returns {
+		sum += ret
+		if ret > 0 {
+			stats.Wins++
+		}
+	}
+	stats.Mean = sum / float64(len(returns))
+	stats.WinRate = float64(stats.Wins) / float64(len(returns)) * 100
+	stats.Median = median(returns)
+
+	return stats
+}
+
+// median медиана значений (срез сортируется на месте)
+func median(values []float64) float64 {
+	sort.Float64s(values)
+
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+
+	return values[middle]
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeHorizonStats(t *testing.T) {
+	newRecord := func(direction string, entry float64, price1h float64) *CoinPriceRecord {
+		record := &CoinPriceRecord{Direction: direction, SourcePrice: entry}
+		if price1h > 0 {
+			record.SetPrice("1h", NewPriceValue(price1h))
+		}
+		return record
+	}
+
+	records := []*CoinPriceRecord{
+		newRecord("long", 100, 110),  // +10%
+		newRecord("short", 100, 95),  // +5%
+		newRecord("long", 100, 98),   // -2%
+		newRecord("short", 100, 101), // -1%
+		newRecord("long", 100, 0),    // Нет цены на горизонте
+		newRecord("hold", 100, 120),  // Направление не распознано
+	}
+	horizons, err := ParseHorizons("1h=Цена через 1 час;24h=Цена через 24 часа")
+	assert.NoError(t, err)
+
+	stats := ComputeHorizonStats(records, horizons, EntrySource)
+	assert.Len(t, stats, 2)
+
+	assert.Equal(t, "1h", stats[0].Horizon.Key)
+	assert.Equal(t, 4, stats[0].Count)
+	assert.Equal(t, 2, stats[0].Wins)
+	assert.InDelta(t, 50.0, stats[0].WinRate, 1e-9)
+	assert.InDelta(t, 3.0, stats[0].Mean, 1e-9)
+	assert.InDelta(t, 2.0, stats[0].Median, 1e-9)
+
+	// Горизонт без цен
+	assert.Equal(t, HorizonStats{Horizon: horizons[1]}, stats[1])
+}
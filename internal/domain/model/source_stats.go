@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SourceStats результаты сигналов одного источника по всем горизонтам
+type SourceStats struct {
+	Source   string
+	Signals  int            // Сколько сигналов у источника
+	Returns  int            // Сколько доходностей (сигнал × горизонт) учтено в метриках
+	Wins     int            // Сколько из них положительные
+	HitRate  float64        // Доля положительных доходностей, %
+	Mean     float64        // Средняя доходность по всем горизонтам, %
+	Median   float64        // Медианная доходность по всем горизонтам, %
+	Horizons []HorizonStats // Результаты источника на каждом горизонте
+	Best     *HorizonStats  // Горизонт с лучшей средней доходностью (nil - нет доходностей)
+	Worst    *HorizonStats  // Горизонт с худшей средней доходностью
+}
+
+// Метрики, по которым можно сортировать источники
+const (
+	SortBySource  = "source"
+	SortBySignals = "signals"
+	SortByHitRate = "hit-rate"
+	SortByMean    = "mean"
+	SortByMedian  = "median"
+)
+
+// ComputeSourceStats группирует записи по источнику и считает доходность с учетом направления
+// Источники с числом сигналов меньше minSignals не возвращаются.
+func ComputeSourceStats(records []*CoinPriceRecord, horizons []Horizon, basis EntryBasis, minSignals int) []SourceStats {
+	var sources []string
+	bySource := make(map[string][]*CoinPriceRecord)
+	for _, record := range records {
+		source := strings.TrimSpace(record.Source)
+		if _, ok := bySource[source]; !ok {
+			sources = append(sources, source)
+		}
+		bySource[source] = append(bySource[source], record)
+	}
+
+	var result []SourceStats
+	for _, source := range sources {
+		sourceRecords := bySource[source]
+		if len(sourceRecords) < minSignals {
+			continue
+		}
+
+		stats := SourceStats{
+			Source:   source,
+			Signals:  len(sourceRecords),
+			Horizons: ComputeHorizonStats(sourceRecords, horizons, basis),
+		}
+
+		var returns []float64
+		for _, horizon := range horizons {
+			for _, record := range sourceRecords {
+				if ret, ok := record.Return(horizon.Key, basis); ok {
+					returns = append(returns, ret)
+				}
+			}
+		}
+		pooled := newHorizonStats(Horizon{}, returns)
+		stats.Returns, stats.Wins = pooled.Count, pooled.Wins
+		stats.HitRate, stats.Mean, stats.Median = pooled.WinRate, pooled.Mean, pooled.Median
+
+		for i := range stats.Horizons {
+			horizonStats := &stats.Horizons[i]
+			if horizonStats.Count == 0 {
+				continue
+			}
+			if stats.Best == nil || horizonStats.Mean > stats.Best.Mean {
+				stats.Best = horizonStats
+			}
+			if stats.Worst == nil || horizonStats.Mean < stats.Worst.Mean {
+				stats.Worst = horizonStats
+			}
+		}
+
+		result = append(result, stats)
+	}
+
+	return result
+}
+
+// SortSourceStats сортирует источники по метрике: по имени - по алфавиту, по остальным - от большего к меньшему
+func SortSourceStats(stats []SourceStats, metric string) error {
+	var value func(s SourceStats) float64
+	switch metric {
+	case SortBySource:
+		sort.SliceStable(stats, func(i, j int) bool {
+			return strings.ToLower(stats[i].Source) < strings.ToLower(stats[j].Source)
+		})
+		return nil
+	case SortBySignals:
+		sort.SliceStable(stats, func(i, j int) bool {
+			return stats[i].Signals > stats[j].Signals
+		})
+		return nil
+	case SortByHitRate:
+		value = func(s SourceStats) float64 { return s.HitRate }
+	case SortByMean:
+		value = func(s SourceStats) float64 { return s.Mean }
+	case SortByMedian:
+		value = func(s SourceStats) float64 { return s.Median }
+	default:
+		return fmt.Errorf("unknown sort metric %q (use %s, %s, %s, %s or %s)",
+			metric, SortBySource, SortBySignals, SortByHitRate, SortByMean, SortByMedian)
+	}
+
+	// Источники без доходностей - в конце, метрики доходности у них не посчитаны
+	sort.SliceStable(stats, func(i, j int) bool {
+		if (stats[i].Returns == 0) != (stats[j].Returns == 0) {
+			return stats[j].Returns == 0
+		}
+		return value(stats[i]) > value(stats[j])
+	})
+
+	return nil
+}
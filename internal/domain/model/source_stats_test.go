@@ -0,0 +1,84 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeSourceStats(t *testing.T) {
+	horizons, err := ParseHorizons("1h=Цена через 1 час;24h=Цена через 24 часа")
+	require.NoError(t, err)
+
+	newRecord := func(source string, direction string, price1h float64, price24h float64) *CoinPriceRecord {
+		record := &CoinPriceRecord{Source: source, Direction: direction, SourcePrice: 100}
+		record.SetPrice("1h", NewPriceValue(price1h))
+		if price24h > 0 {
+			record.SetPrice("24h", NewPriceValue(price24h))
+		}
+		return record
+	}
+
+	records := []*CoinPriceRecord{
+		newRecord("ChannelA", "long", 102, 110), // +2%, +10%
+		newRecord("ChannelA", "short", 99, 104), // +1%, -4%
+		newRecord("ChannelB", "long", 97, 0),    // -3%
+		newRecord(" ChannelA ", "long", 101, 0), // +1%
+	}
+
+	stats := ComputeSourceStats(records, horizons, EntrySource, 1)
+	require.Len(t, stats, 2)
+
+	a := stats[0]
+	assert.Equal(t, "ChannelA", a.Source)
+	assert.Equal(t, 3, a.Signals)
+	assert.Equal(t, 5, a.Returns)
+	assert.Equal(t, 4, a.Wins)
+	assert.InDelta(t, 80.0, a.HitRate, 1e-9)
+	assert.InDelta(t, 2.0, a.Mean, 1e-9)
+	assert.InDelta(t, 1.0, a.Median, 1e-9)
+	require.NotNil(t, a.Best)
+	assert.Equal(t, "24h", a.Best.Horizon.Key)
+	assert.Equal(t, "1h", a.Worst.Horizon.Key)
+
+	b := stats[1]
+	assert.Equal(t, "ChannelB", b.Source)
+	assert.Equal(t, "1h", b.Best.Horizon.Key)
+	assert.Equal(t, "1h", b.Worst.Horizon.Key)
+
+	// Порог по числу сигналов
+	stats = ComputeSourceStats(records, horizons, EntrySource, 2)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "ChannelA", stats[0].Source)
+}
+
+func TestSortSourceStats(t *testing.T) {
+	stats := []SourceStats{
+		{Source: "b", Signals: 5, Returns: 5, Mean: 1, HitRate: 80},
+		{Source: "a", Signals: 9},
+		{Source: "c", Signals: 2, Returns: 2, Mean: 3, HitRate: 50},
+	}
+
+	sources := func() []string {
+		var names []string
+		for _, s := range stats {
+			names = append(names, s.Source)
+		}
+		return names
+	}
+
+	require.NoError(t, SortSourceStats(stats, SortByMean))
+	assert.Equal(t, []string{"c", "b", "a"}, sources())
+
+	require.NoError(t, SortSourceStats(stats, SortByHitRate))
+	assert.Equal(t, []string{"b", "c", "a"}, sources())
+
+	require.NoError(t, SortSourceStats(stats, SortBySignals))
+	assert.Equal(t, []string{"a", "b", "c"}, sources())
+
+	require.NoError(t, SortSourceStats(stats, SortBySource))
+	assert.Equal(t, []string{"a", "b", "c"}, sources())
+
+	assert.Error(t, SortSourceStats(stats, "profit"))
+}
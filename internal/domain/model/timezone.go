@@ -0,0 +1,159 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSheetTimezone часовой пояс листа, если SHEET_TIMEZONE не задан: GMT+7
+var DefaultSheetTimezone = time.FixedZone("GMT+7", 7*60*60)
+
+// Timezones часовые пояса, в которых записаны дата и время сигналов листа
+// Нулевое значение - пояс листа по умолчанию (GMT+7) без поясов источников.
+type Timezones struct {
+	sheet   *time.Location
+	sources map[string]*time.Location // Название источника в нижнем регистре → пояс
+}
+
+// NewTimezones создает часовые пояса листа: sheet - пояс листа (nil - GMT+7),
+// sources - пояса источников, которые публикуют время не в поясе листа
+func NewTimezones(sheet *time.Location, sources map[string]*time.Location) Timezones {
+	timezones := Timezones{sheet: sheet, sources: make(map[string]*time.Location, len(sources))}
+	for source, location := range sources {
+		timezones.sources[strings.ToLower(strings.TrimSpace(source))] = location
+	}
+
+	return timezones
+}
+
+// Sheet возвращает часовой пояс листа
+func (z Timezones) Sheet() *time.Location {
+	if z.sheet == nil {
+		return DefaultSheetTimezone
+	}
+
+	return z.sheet
+}
+
+// Source возвращает часовой пояс источника по умолчанию (false - пояс не задан)
+func (z Timezones) Source(source string) (*time.Location, bool) {
+	location, ok := z.sources[strings.ToLower(strings.TrimSpace(source))]
+	return location, ok
+}
+
+// utcOffsetPattern смещение от UTC: +3, -03:30, UTC+7, GMT-0530
+var utcOffsetPattern = regexp.MustCompile(`(?i)^(?:utc|gmt)?\s*([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// ParseTimezone разбирает часовой пояс: имя IANA (Europe/Berlin, с переходом на летнее время),
+// UTC или фиксированное смещение (+03:00, UTC+3, GMT+7)
+// GMT+7 означает UTC+7, а не обратный знак, как в IANA зоне Etc/GMT+7.
+func ParseTimezone(value string) (*time.Location, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("empty timezone")
+	}
+	if strings.EqualFold(value, "UTC") || strings.EqualFold(value, "GMT") || value == "Z" {
+		return time.UTC, nil
+	}
+
+	if match := utcOffsetPattern.FindStringSubmatch(value); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes := 0
+		if match[3] != "" {
+			minutes, _ = strconv.Atoi(match[3])
+		}
+		if hours > 14 || minutes > 59 {
+			return nil, fmt.Errorf("invalid timezone offset %q", value)
+		}
+
+		offset := hours*60*60 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(value, offset), nil
+	}
+
+	location, err := time.LoadLocation(value)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: use an IANA name like Europe/Berlin or an offset like +03:00", value)
+	}
+
+	return location, nil
+}
+
+// Location возвращает часовой пояс, в котором записаны дата и время сигнала:
+// колонка часового пояса строки, иначе пояс источника, иначе пояс листа (пояса берутся из раскладки листа)
+func (r *CoinPriceRecord) Location() (*time.Location, error) {
+	if r.Timezone != "" {
+		return ParseTimezone(r.Timezone)
+	}
+
+	timezones := r.timezones()
+	if location, ok := timezones.Source(r.Source); ok {
+		return location, nil
+	}
+
+	return timezones.Sheet(), nil
+}
+
+// timezones часовые пояса листа записи; у записи без раскладки - пояса по умолчанию
+func (r *CoinPriceRecord) timezones() Timezones {
+	if r.layout == nil {
+		return Timezones{}
+	}
+
+	return r.layout.Timezones()
+}
+
+// timestampFormats моменты ISO-8601 с явным смещением: часовой пояс записи для них не нужен
+var timestampFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04Z07:00",
+}
+
+// parseTimestamp разбирает момент с явным смещением (ISO-8601) или Unix время в секундах или миллисекундах
+func parseTimestamp(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	if isDigits(value) {
+		epoch, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		// 10 цифр - секунды (до 2286 года), 13 цифр - миллисекунды
+		switch len(value) {
+		case 9, 10:
+			return time.Unix(epoch, 0), true
+		case 12, 13:
+			return time.UnixMilli(epoch), true
+		default:
+			return time.Time{}, false
+		}
+	}
+
+	for _, format := range timestampFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func isDigits(value string) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return value != ""
+}
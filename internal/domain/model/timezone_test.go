@@ -0,0 +1,125 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimezone(t *testing.T) {
+	offsets := map[string]int{
+		"UTC":      0,
+		"+03:00":   3 * 3600,
+		"UTC+3":    3 * 3600,
+		"GMT+7":    7 * 3600,
+		"gmt-0530": -(5*3600 + 30*60),
+	}
+	at := time.Date(2025, 12, 29, 10, 0, 0, 0, time.UTC)
+	for value, offset := range offsets {
+		location, err := ParseTimezone(value)
+		require.NoError(t, err, value)
+		_, got := at.In(location).Zone()
+		assert.Equal(t, offset, got, value)
+	}
+
+	location, err := ParseTimezone("Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", location.String())
+
+	for _, value := range []string{"", "Mars/Olympus", "+25:00"} {
+		_, err := ParseTimezone(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestCoinPriceRecord_Timezones(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	layout := DefaultSheetLayout()
+	layout.SetTimezones(NewTimezones(nil, map[string]*time.Location{"ChannelUTC": time.UTC, "ChannelEU": berlin}))
+
+	expected := time.Date(2025, 12, 29, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Пояс листа по умолчанию", func(t *testing.T) {
+		record := &CoinPriceRecord{layout: layout, Date: "29.12.2025", Time: "17:00:00", Source: "ChannelA"}
+		parsed, err := record.TryParseDateTime()
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(parsed))
+		assert.Equal(t, DefaultSheetTimezone, parsed.Location())
+	})
+
+	t.Run("Пояс источника", func(t *testing.T) {
+		record := &CoinPriceRecord{layout: layout, Date: "29.12.2025", Time: "10:00", Source: "channelutc"}
+		parsed, err := record.TryParseDateTime()
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(parsed))
+	})
+
+	t.Run("Колонка часового пояса важнее пояса источника", func(t *testing.T) {
+		record := &CoinPriceRecord{layout: layout, Date: "29.12.2025", Time: "13:00:00", Source: "ChannelUTC", Timezone: "+03:00"}
+		parsed, err := record.TryParseDateTime()
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(parsed))
+
+		record.Timezone = "Nowhere/City"
+		_, err = record.TryParseDateTime()
+		assert.Error(t, err)
+	})
+
+	t.Run("Летнее время IANA пояса", func(t *testing.T) {
+		winter := &CoinPriceRecord{layout: layout, Date: "29.12.2025", Time: "11:00:00", Source: "ChannelEU"}
+		summer := &CoinPriceRecord{layout: layout, Date: "29.06.2025", Time: "11:00:00", Source: "ChannelEU"}
+
+		parsed, err := winter.TryParseDateTime()
+		require.NoError(t, err)
+		assert.Equal(t, 10, parsed.UTC().Hour())
+
+		parsed, err = summer.TryParseDateTime()
+		require.NoError(t, err)
+		assert.Equal(t, 9, parsed.UTC().Hour())
+	})
+
+	t.Run("Момент со смещением и Unix время", func(t *testing.T) {
+		records := []*CoinPriceRecord{
+			{layout: layout, Date: "2025-12-29T12:00:00+02:00", Source: "ChannelEU"},
+			{layout: layout, Date: "2025-12-29", Time: "10:00:00Z"},
+			{layout: layout, Date: "29.12.2025", Time: "2025-12-29T10:00:00Z"},
+			{layout: layout, Date: "1767002400"},
+			{layout: layout, Date: "1767002400000", Time: ""},
+		}
+		for _, record := range records {
+			parsed, err := record.TryParseDateTime()
+			require.NoError(t, err, record.GetDateTime())
+			assert.True(t, expected.Equal(parsed), record.GetDateTime())
+		}
+	})
+
+	t.Run("SetDateTime пишет время в поясе записи", func(t *testing.T) {
+		record := &CoinPriceRecord{layout: layout, Source: "ChannelUTC"}
+		record.SetDateTime(expected)
+		assert.Equal(t, "29.12.2025", record.Date)
+		assert.Equal(t, "10:00:00", record.Time)
+	})
+}
+
+func TestSheetLayout_TimezoneColumn(t *testing.T) {
+	header := []interface{}{"Дата", "Время", "TZ", "Источник", "Монета", "Направление"}
+	layout, err := NewSheetLayoutFromHeader(header, nil, nil)
+	require.NoError(t, err)
+
+	index, ok := layout.Index(FieldTimezone)
+	require.True(t, ok)
+	assert.Equal(t, 2, index)
+	assert.NotContains(t, layout.MissingFields(), FieldTimezone)
+
+	record, err := layout.ParseRow([]interface{}{"29.12.2025", "10:00:00", "UTC", "ChannelA", "BTC", "long"})
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", record.Timezone)
+	assert.Equal(t, "UTC", record.ToRow()[2])
+
+	_, ok = DefaultSheetLayout().Index(FieldTimezone)
+	assert.False(t, ok)
+}
@@ -0,0 +1,24 @@
+package command
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/urfave/cli/v2"
+)
+
+func NewBotCommand(service usecase.IBot) *cli.Command {
+	return &cli.Command{
+		Name:  "bot",
+		Usage: "run the Telegram bot that adds signals with /signal",
+		Flags: []cli.Flag{},
+		Action: func(c *cli.Context) error {
+			ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return service.Run(ctx)
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/urfave/cli/v2"
+)
+
+func NewCoinsCommand(service usecase.ICoins) *cli.Command {
+	return &cli.Command{
+		Name:  "coins",
+		Usage: "symbol to CoinGecko ID mapping",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "resolve",
+				Usage:     "show which CoinGecko coin a symbol resolves to",
+				ArgsUsage: "<SYMBOL>",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: coins resolve <SYMBOL>")
+					}
+					return service.Resolve(c.Context, c.Args().First())
+				},
+			},
+			{
+				Name:  "map",
+				Usage: "manage manual symbol mappings",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "list manual mappings",
+						Action: func(c *cli.Context) error {
+							return service.ListMappings(c.Context)
+						},
+					},
+					{
+						Name:      "add",
+						Usage:     "pin a symbol to a CoinGecko ID",
+						ArgsUsage: "<SYMBOL> <coingecko-id>",
+						Action: func(c *cli.Context) error {
+							if c.NArg() != 2 {
+								return fmt.Errorf("usage: coins map add <SYMBOL> <coingecko-id>")
+							}
+							return service.AddMapping(c.Context, c.Args().Get(0), c.Args().Get(1))
+						},
+					},
+					{
+						Name:      "remove",
+						Usage:     "remove a manual mapping",
+						ArgsUsage: "<SYMBOL>",
+						Action: func(c *cli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("usage: coins map remove <SYMBOL>")
+							}
+							return service.RemoveMapping(c.Context, c.Args().First())
+						},
+					},
+				},
+			},
+		},
+	}
+}
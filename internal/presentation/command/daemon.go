@@ -0,0 +1,25 @@
+package command
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/urfave/cli/v2"
+)
+
+func NewDaemonCommand(service usecase.IDaemon) *cli.Command {
+	return &cli.Command{
+		Name:  "daemon",
+		Usage: "keep running and fill prices exactly when each horizon is due",
+		Flags: []cli.Flag{},
+		Action: func(c *cli.Context) error {
+			// SIGTERM/Ctrl-C завершают daemon; прерванный проход ничего не записывает в таблицу
+			ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return service.Run(ctx)
+		},
+	}
+}
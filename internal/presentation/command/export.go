@@ -0,0 +1,45 @@
+package command
+
+import (
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/urfave/cli/v2"
+)
+
+func NewExportCommand(service usecase.IExport) *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "write the parsed records to stdout as csv, json or ndjson",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "output format: csv, json or ndjson",
+				Value: usecase.OutputCSV,
+			},
+			&cli.BoolFlag{
+				Name:  "returns",
+				Usage: "add the direction-aware return at every horizon, %",
+			},
+			&cli.StringFlag{
+				Name:  "entry",
+				Usage: "entry price for --returns: auto (source price, else Bybit price), source or bybit",
+				Value: string(model.EntryAuto),
+			},
+			newStoreFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			entry, err := model.ParseEntryBasis(c.String("entry"))
+			if err != nil {
+				return err
+			}
+
+			return service.Export(c.Context, usecase.ExportOptions{
+				Format:  c.String("format"),
+				Returns: c.Bool("returns"),
+				Entry:   entry,
+				Out:     c.App.Writer,
+				Store:   c.String("store"),
+			})
+		},
+	}
+}
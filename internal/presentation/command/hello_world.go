@@ -1,19 +1,19 @@
 package command
 
 import (
-    "context"
-    
-    "github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
-    "github.com/urfave/cli/v2"
+	"context"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/urfave/cli/v2"
 )
 
 func NewHelloWorldCommand(service usecase.IHelloWorld) *cli.Command {
-    return &cli.Command{
-        Name:  "hello-world",
-        Usage: "hello-world command",
-        Flags: []cli.Flag{},
-        Action: func(c *cli.Context) error {
-            return service.Process(context.Background())
-        },
-    }
+	return &cli.Command{
+		Name:  "hello-world",
+		Usage: "hello-world command",
+		Flags: []cli.Flag{},
+		Action: func(c *cli.Context) error {
+			return service.Process(context.Background())
+		},
+	}
 }
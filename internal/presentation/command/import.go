@@ -0,0 +1,82 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/urfave/cli/v2"
+)
+
+func NewImportCommand(service usecase.IImport) *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "validate records (csv, json or ndjson, same as export) and append them to the sheet",
+		ArgsUsage: "[FILE|-]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "input format: csv, json or ndjson (by default from the file extension)",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "only validate the records, do not append them",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			path := c.Args().First()
+			format := c.String("format")
+
+			var in io.Reader = c.App.Reader
+			if path != "" && path != "-" {
+				file, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				in = file
+
+				if format == "" {
+					format = formatFromExtension(path)
+				}
+			}
+			if in == nil {
+				in = os.Stdin
+			}
+			if format == "" {
+				return fmt.Errorf("set --format for %s", describeInput(path))
+			}
+
+			return service.Import(c.Context, usecase.ImportOptions{
+				Format: format,
+				In:     in,
+				DryRun: c.Bool("dry-run"),
+			})
+		},
+	}
+}
+
+// formatFromExtension формат записей по расширению файла (пусто, если не распознан)
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return usecase.OutputCSV
+	case ".json":
+		return usecase.OutputJSON
+	case ".ndjson", ".jsonl":
+		return usecase.OutputNDJSON
+	default:
+		return ""
+	}
+}
+
+func describeInput(path string) string {
+	if path == "" || path == "-" {
+		return "stdin"
+	}
+
+	return path
+}
@@ -1,7 +1,9 @@
 package command
 
 import (
-	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
 	"github.com/urfave/cli/v2"
@@ -11,10 +13,37 @@ func NewProcessCommand(service usecase.IProcess) *cli.Command {
 	return &cli.Command{
 		Name:  "process",
 		Usage: "process command",
-		Flags: []cli.Flag{},
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "fill prices but print the planned cell changes instead of writing them to the sheet",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "dry-run output format: table or json",
+				Value: usecase.OutputTable,
+			},
+			newStoreFlag(),
+		},
 		Action: func(c *cli.Context) error {
-			return service.Process(context.Background())
+			// Ctrl-C прерывает ожидание лимитеров и запросы к провайдерам
+			ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return service.Process(ctx, usecase.ProcessOptions{
+				DryRun: c.Bool("dry-run"),
+				Output: c.String("output"),
+				Out:    c.App.Writer,
+				Store:  c.String("store"),
+			})
 		},
 	}
 }
 
+// newStoreFlag флаг --store: таблица с сигналами вместо Google Sheets из конфига
+func newStoreFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "store",
+		Usage: "use a local file as the signal sheet instead of Google Sheets: file:signals.csv or file:signals.xlsx",
+	}
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/urfave/cli/v2"
+)
+
+func NewReportCommand(service usecase.IReport) *cli.Command {
+	return &cli.Command{
+		Name:  "report",
+		Usage: "win rate, mean and median return of signals at every horizon",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "entry",
+				Usage: "entry price: auto (source price, else Bybit price), source or bybit",
+				Value: string(model.EntryAuto),
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "output format: table, csv or markdown",
+				Value: usecase.OutputTable,
+			},
+			newStoreFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			entry, err := model.ParseEntryBasis(c.String("entry"))
+			if err != nil {
+				return err
+			}
+
+			return service.Report(c.Context, usecase.ReportOptions{
+				Entry:  entry,
+				Output: c.String("output"),
+				Out:    c.App.Writer,
+				Store:  c.String("store"),
+			})
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/urfave/cli/v2"
+)
+
+func NewRestoreCommand(service usecase.IRestore) *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "rebuild the signals sheet from the local store",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "sheet",
+				Usage:    "sheet to write the stored records to (created if missing, its content is replaced)",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "allow overwriting the data sheet itself",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return service.Restore(c.Context, usecase.RestoreOptions{
+				Sheet: c.String("sheet"),
+				Force: c.Bool("force"),
+			})
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"time"
+
+	"github.com/drybin/TrackMyCoin/internal/app/cli/usecase"
+	"github.com/drybin/TrackMyCoin/internal/domain/model"
+	"github.com/urfave/cli/v2"
+)
+
+func NewSourcesCommand(service usecase.ISources) *cli.Command {
+	return &cli.Command{
+		Name:  "sources",
+		Usage: "leaderboard of signal sources: hit rate, mean and median return, best and worst horizon",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "entry",
+				Usage: "entry price: auto (source price, else Bybit price), source or bybit",
+				Value: string(model.EntryAuto),
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "output format: table, csv or markdown",
+				Value: usecase.OutputTable,
+			},
+			&cli.IntFlag{
+				Name:  "min-signals",
+				Usage: "hide sources with fewer signals",
+				Value: 1,
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "only signals on or after this date (DD.MM.YYYY or YYYY-MM-DD)",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "only signals on or before this date (DD.MM.YYYY or YYYY-MM-DD)",
+			},
+			&cli.StringFlag{
+				Name:  "sort",
+				Usage: "sort by: source, signals, hit-rate, mean or median",
+				Value: model.SortByMean,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			entry, err := model.ParseEntryBasis(c.String("entry"))
+			if err != nil {
+				return err
+			}
+			from, err := parseDateFlag(c.String("from"))
+			if err != nil {
+				return err
+			}
+			to, err := parseDateFlag(c.String("to"))
+			if err != nil {
+				return err
+			}
+
+			return service.Sources(c.Context, usecase.SourcesOptions{
+				Entry:      entry,
+				Output:     c.String("output"),
+				Out:        c.App.Writer,
+				MinSignals: c.Int("min-signals"),
+				From:       from,
+				To:         to,
+				Sort:       c.String("sort"),
+			})
+		},
+	}
+}
+
+// parseDateFlag пустое значение - без границы; часовой пояс таблицы подставляет usecase
+func parseDateFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return model.ParseSheetDate(value, time.UTC)
+}
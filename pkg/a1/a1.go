@@ -0,0 +1,186 @@
+package a1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Range диапазон в A1 нотации Google Sheets
+// Колонки и строки нумеруются как в таблице: колонка A = 1, первая строка = 1.
+// Нулевые значения означают открытую границу (например, "Лист1!A2:R" - EndRow = 0).
+type Range struct {
+	Sheet    string
+	StartCol int
+	StartRow int
+	EndCol   int
+	EndRow   int
+}
+
+// ColumnLetter возвращает буквенное обозначение колонки: 1 → A, 27 → AA
+func ColumnLetter(col int) string {
+	var letters []byte
+	for col > 0 {
+		col--
+		letters = append([]byte{byte('A' + col%26)}, letters...)
+		col /= 26
+	}
+
+	return string(letters)
+}
+
+// ColumnNumber возвращает номер колонки по буквенному обозначению: A → 1, AA → 27
+func ColumnNumber(letters string) (int, error) {
+	if letters == "" {
+		return 0, fmt.Errorf("empty column letters")
+	}
+
+	col := 0
+	for _, ch := range strings.ToUpper(letters) {
+		if ch < 'A' || ch > 'Z' {
+			return 0, fmt.Errorf("invalid column letters %q", letters)
+		}
+		col = col*26 + int(ch-'A'+1)
+	}
+
+	return col, nil
+}
+
+// QuoteSheet берет имя листа в кавычки, если без них диапазон будет прочитан неправильно
+func QuoteSheet(sheet string) string {
+	for _, ch := range sheet {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '_' {
+			return "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+		}
+	}
+
+	return sheet
+}
+
+// Cell возвращает адрес одной ячейки, например Лист1!C5
+func Cell(sheet string, col int, row int) string {
+	return withSheet(sheet, ColumnLetter(col)+strconv.Itoa(row))
+}
+
+// Rows возвращает диапазон строк startRow..endRow по колонкам startCol..endCol, например Лист1!A2:R11
+func Rows(sheet string, startCol int, endCol int, startRow int, endRow int) string {
+	return withSheet(sheet, fmt.Sprintf("%s%d:%s%d", ColumnLetter(startCol), startRow, ColumnLetter(endCol), endRow))
+}
+
+// String возвращает диапазон в A1 нотации
+func (r Range) String() string {
+	start := ColumnLetter(r.StartCol) + rowString(r.StartRow)
+	end := ColumnLetter(r.EndCol) + rowString(r.EndRow)
+	if start == "" && end == "" {
+		return QuoteSheet(r.Sheet)
+	}
+	if end == "" || end == start {
+		return withSheet(r.Sheet, start)
+	}
+
+	return withSheet(r.Sheet, start+":"+end)
+}
+
+// Parse разбирает диапазон A1 нотации: "Лист1", "Лист1!A2:R", "'Sheet 1'!B3", "A1:R100"
+func Parse(rangeA1 string) (Range, error) {
+	var result Range
+
+	cells := rangeA1
+	if idx := strings.LastIndex(rangeA1, "!"); idx >= 0 {
+		result.Sheet = unquoteSheet(rangeA1[:idx])
+		cells = rangeA1[idx+1:]
+	} else if !looksLikeCells(rangeA1) {
+		// Диапазон без "!" и без адресов ячеек - это имя листа целиком
+		result.Sheet = unquoteSheet(rangeA1)
+		return result, nil
+	}
+
+	start, end, hasEnd := strings.Cut(cells, ":")
+
+	var err error
+	result.StartCol, result.StartRow, err = parseCell(start)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid range %q: %w", rangeA1, err)
+	}
+
+	if hasEnd {
+		result.EndCol, result.EndRow, err = parseCell(end)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid range %q: %w", rangeA1, err)
+		}
+	} else {
+		result.EndCol, result.EndRow = result.StartCol, result.StartRow
+	}
+
+	return result, nil
+}
+
+func parseCell(cell string) (int, int, error) {
+	cell = strings.ReplaceAll(strings.TrimSpace(cell), "$", "")
+
+	i := 0
+	for i < len(cell) && (cell[i] >= 'A' && cell[i] <= 'Z' || cell[i] >= 'a' && cell[i] <= 'z') {
+		i++
+	}
+
+	col := 0
+	if i > 0 {
+		var err error
+		if col, err = ColumnNumber(cell[:i]); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	row := 0
+	if i < len(cell) {
+		var err error
+		if row, err = strconv.Atoi(cell[i:]); err != nil || row <= 0 {
+			return 0, 0, fmt.Errorf("invalid cell %q", cell)
+		}
+	}
+
+	if col == 0 && row == 0 {
+		return 0, 0, fmt.Errorf("invalid cell %q", cell)
+	}
+
+	return col, row, nil
+}
+
+func looksLikeCells(value string) bool {
+	start, end, hasEnd := strings.Cut(value, ":")
+	if _, _, err := parseCell(start); err != nil {
+		return false
+	}
+	if hasEnd {
+		if _, _, err := parseCell(end); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func unquoteSheet(sheet string) string {
+	if len(sheet) >= 2 && strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") {
+		return strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+	}
+
+	return sheet
+}
+
+func withSheet(sheet string, cells string) string {
+	if sheet == "" {
+		return cells
+	}
+
+	return QuoteSheet(sheet) + "!" + cells
+}
+
+func rowString(row int) string {
+	if row == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(row)
+}
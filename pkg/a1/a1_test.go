@@ -0,0 +1,59 @@
+package a1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnLetter(t *testing.T) {
+	tests := map[int]string{1: "A", 18: "R", 26: "Z", 27: "AA", 52: "AZ", 703: "AAA"}
+
+	for col, letters := range tests {
+		assert.Equal(t, letters, ColumnLetter(col))
+
+		number, err := ColumnNumber(letters)
+		assert.NoError(t, err)
+		assert.Equal(t, col, number)
+	}
+
+	_, err := ColumnNumber("A1")
+	assert.Error(t, err)
+}
+
+func TestCellAndRows(t *testing.T) {
+	assert.Equal(t, "Лист1!C5", Cell("Лист1", 3, 5))
+	assert.Equal(t, "'Sheet 1'!A2:R11", Rows("Sheet 1", 1, 18, 2, 11))
+	assert.Equal(t, "'It''s'!A1", Cell("It's", 1, 1))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Range
+	}{
+		{"Лист1", Range{Sheet: "Лист1"}},
+		{"'Sheet 1'", Range{Sheet: "Sheet 1"}},
+		{"Лист1!A2:R", Range{Sheet: "Лист1", StartCol: 1, StartRow: 2, EndCol: 18}},
+		{"'Sheet 1'!B3", Range{Sheet: "Sheet 1", StartCol: 2, StartRow: 3, EndCol: 2, EndRow: 3}},
+		{"A1:R100", Range{StartCol: 1, StartRow: 1, EndCol: 18, EndRow: 100}},
+		{"Лист1!A1:Z1000", Range{Sheet: "Лист1", StartCol: 1, StartRow: 1, EndCol: 26, EndRow: 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	_, err := Parse("Лист1!A0")
+	assert.Error(t, err)
+}
+
+func TestRange_String(t *testing.T) {
+	assert.Equal(t, "Лист1!A2:R", Range{Sheet: "Лист1", StartCol: 1, StartRow: 2, EndCol: 18}.String())
+	assert.Equal(t, "Лист1!B3", Range{Sheet: "Лист1", StartCol: 2, StartRow: 3, EndCol: 2, EndRow: 3}.String())
+	assert.Equal(t, "'Sheet 1'", Range{Sheet: "Sheet 1"}.String())
+}
@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clock источник времени лимитера (в тестах подменяется фейковыми часами)
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock системные часы
+var SystemClock Clock = systemClock{}
+
+// Rate ограничение частоты: не больше Limit запросов за Per
+type Rate struct {
+	Limit int
+	Per   time.Duration
+}
+
+// ParseRate разбирает ограничение вида "30/1m" (30 запросов в минуту) или "10/s"
+func ParseRate(value string) (Rate, error) {
+	limitStr, perStr, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q: expected <limit>/<duration>, e.g. 30/1m", value)
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+	if err != nil || limit <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q: limit must be a positive integer", value)
+	}
+
+	perStr = strings.TrimSpace(perStr)
+	if perStr != "" && (perStr[0] < '0' || perStr[0] > '9') {
+		// "10/s" - то же, что "10/1s"
+		perStr = "1" + perStr
+	}
+	per, err := time.ParseDuration(perStr)
+	if err != nil || per <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q: duration must be positive, e.g. 1m", value)
+	}
+
+	return Rate{Limit: limit, Per: per}, nil
+}
+
+func (r Rate) String() string {
+	return fmt.Sprintf("%d/%s", r.Limit, r.Per)
+}
+
+// Limiter token bucket: емкость Limit токенов, один токен восстанавливается за Per / Limit
+// Лимитер общий для всех запросов к провайдеру и безопасен для использования из нескольких горутин.
+// Методы nil лимитера не ограничивают частоту.
+type Limiter struct {
+	mu       sync.Mutex
+	clock    Clock
+	interval time.Duration // Время восстановления одного токена
+	burst    float64       // Емкость корзины
+	tokens   float64       // Текущее количество токенов (отрицательное - токены зарезервированы ожидающими)
+	last     time.Time     // Момент последнего пересчета токенов
+}
+
+// NewLimiter создает лимитер с полной корзиной (clock nil - системные часы)
+func NewLimiter(rate Rate, clock Clock) *Limiter {
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	return &Limiter{
+		clock:    clock,
+		interval: rate.Per / time.Duration(rate.Limit),
+		burst:    float64(rate.Limit),
+		tokens:   float64(rate.Limit),
+		last:     clock.Now(),
+	}
+}
+
+// Wait ждет свободный токен
+// Токен резервируется сразу, поэтому ожидающие получают токены в порядке вызова.
+// При отмене ctx ожидание прерывается, а зарезервированный токен возвращается.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.refill()
+	l.tokens--
+	wait := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-l.clock.After(wait):
+		return nil
+	}
+}
+
+// Sleep ждет d по часам лимитера (например, задержка перед повтором после 429)
+// Ожидание прерывается при отмене ctx.
+func (l *Limiter) Sleep(ctx context.Context, d time.Duration) error {
+	clock := SystemClock
+	if l != nil {
+		clock = l.clock
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(d):
+		return nil
+	}
+}
+
+// refill восстанавливает токены за время, прошедшее с последнего пересчета
+func (l *Limiter) refill() {
+	now := l.clock.Now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.burst, l.tokens+float64(elapsed)/float64(l.interval))
+		l.last = now
+	}
+}
@@ -0,0 +1,175 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock часы, которые идут только при вызове Advance
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	waiting chan struct{} // Сигнал о каждом новом ожидании в After
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Date(2025, 12, 29, 10, 0, 0, 0, time.UTC),
+		waiting: make(chan struct{}, 100),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	c.waiting <- struct{}{}
+
+	return ch
+}
+
+// Advance переводит часы вперед и срабатывает таймеры, время которых наступило
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	var pending []fakeTimer
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- c.now
+	}
+	c.timers = pending
+}
+
+// waitAsync запускает Wait в горутине и возвращает канал с его результатом
+func waitAsync(ctx context.Context, limiter *Limiter) chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- limiter.Wait(ctx)
+	}()
+
+	return done
+}
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("30/1m")
+	require.NoError(t, err)
+	assert.Equal(t, Rate{Limit: 30, Per: time.Minute}, rate)
+
+	rate, err = ParseRate(" 10 / s ")
+	require.NoError(t, err)
+	assert.Equal(t, Rate{Limit: 10, Per: time.Second}, rate)
+
+	for _, value := range []string{"30", "0/1m", "x/1m", "30/0s", "30/minute"} {
+		_, err := ParseRate(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewLimiter(Rate{Limit: 2, Per: time.Second}, clock)
+	ctx := context.Background()
+
+	// Полная корзина: два запроса проходят сразу
+	require.NoError(t, limiter.Wait(ctx))
+	require.NoError(t, limiter.Wait(ctx))
+
+	// Третий ждет восстановления токена (полсекунды)
+	done := waitAsync(ctx, limiter)
+	<-clock.waiting
+
+	clock.Advance(499 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Wait returned before a token was refilled")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	require.NoError(t, <-done)
+
+	// Простой дольше Per не накапливает токенов больше емкости
+	clock.Advance(time.Hour)
+	require.NoError(t, limiter.Wait(ctx))
+	require.NoError(t, limiter.Wait(ctx))
+
+	done = waitAsync(ctx, limiter)
+	<-clock.waiting
+	clock.Advance(500 * time.Millisecond)
+	require.NoError(t, <-done)
+}
+
+func TestLimiter_WaitCanceled(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewLimiter(Rate{Limit: 1, Per: time.Minute}, clock)
+	require.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitAsync(ctx, limiter)
+	<-clock.waiting
+
+	// Отмена прерывает ожидание сразу, не дожидаясь токена
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	// Отмененное ожидание вернуло токен: следующий запрос ждет одну минуту, а не две
+	done = waitAsync(context.Background(), limiter)
+	<-clock.waiting
+	clock.Advance(time.Minute)
+	require.NoError(t, <-done)
+
+	// Уже отмененный контекст не занимает токен
+	assert.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+}
+
+func TestLimiter_Sleep(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewLimiter(Rate{Limit: 1, Per: time.Second}, clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- limiter.Sleep(ctx, 8*time.Second)
+	}()
+	<-clock.waiting
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	go func() {
+		done <- limiter.Sleep(context.Background(), 2*time.Second)
+	}()
+	<-clock.waiting
+	clock.Advance(2 * time.Second)
+	require.NoError(t, <-done)
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var limiter *Limiter
+	assert.NoError(t, limiter.Wait(context.Background()))
+}